@@ -0,0 +1,159 @@
+package controller
+
+import (
+	"fmt"
+	"food-track-be/model/dto"
+	"food-track-be/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"strconv"
+)
+
+type CatalogFoodController struct {
+	catalogFoodService *service.CatalogFoodService
+}
+
+func NewCatalogFoodController(catalogFoodService *service.CatalogFoodService) *CatalogFoodController {
+	return &CatalogFoodController{catalogFoodService: catalogFoodService}
+}
+
+// SearchCatalogFoods godoc
+//	@Summary		Search foods
+//	@Description	search the food catalog by name, alias or barcode; foods named exactly as the query come first
+//	@Tags			food
+//	@Produce		json
+//	@Param			q		query		string	true	"Name, alias or barcode to search"
+//	@Param			limit	query		int		false	"Number of foods to return, between 1 and 100 (default 20)"
+//	@Success		200		{object}	dto.BaseResponse[[]dto.CatalogFoodDto]
+//	@Router			/food/ [get]
+func (s *CatalogFoodController) SearchCatalogFoods(c *gin.Context) {
+	limit := service.DefaultCatalogSearchLimit
+	if limitParam := c.Query("limit"); limitParam != "" {
+		var err error
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil {
+			abortWithValidationError(c, fmt.Errorf("invalid limit: %w", err))
+			return
+		}
+	}
+	catalogFoodsDto, err := s.catalogFoodService.Search(c.Query("q"), limit)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	response := dto.BaseResponse[[]dto.CatalogFoodDto]{
+		Body: catalogFoodsDto,
+	}
+	c.JSON(200, response)
+}
+
+// FindCatalogFoodById godoc
+//	@Summary		Get food
+//	@Description	get the food of the catalog with the provided id
+//	@Tags			food
+//	@Produce		json
+//	@Param			foodId	path		string	true	"Food ID"
+//	@Success		200		{object}	dto.BaseResponse[dto.CatalogFoodDto]
+//	@Router			/food/{foodId}/ [get]
+func (s *CatalogFoodController) FindCatalogFoodById(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("foodId"))
+	if err != nil {
+		abortWithValidationError(c, err)
+		return
+	}
+	catalogFoodDto, err := s.catalogFoodService.FindById(id)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	response := dto.BaseResponse[dto.CatalogFoodDto]{
+		Body: catalogFoodDto,
+	}
+	c.JSON(200, response)
+}
+
+// CreateCatalogFood godoc
+//	@Summary		Create food
+//	@Description	add a food to the catalog, with its nutrients per 100 g or ml; administrators only
+//	@Tags			food
+//	@Accept			json
+//	@Produce		json
+//	@Param			catalogFoodDto	body		dto.CatalogFoodDto	true	"Food to create"
+//	@Success		200				{object}	dto.BaseResponse[dto.CatalogFoodDto]
+//	@Router			/food/ [post]
+func (s *CatalogFoodController) CreateCatalogFood(c *gin.Context) {
+	var catalogFoodDto dto.CatalogFoodDto
+	err := c.ShouldBindJSON(&catalogFoodDto)
+	if err != nil {
+		abortWithValidationError(c, err)
+		return
+	}
+	catalogFoodDto, err = s.catalogFoodService.Create(catalogFoodDto)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	response := dto.BaseResponse[dto.CatalogFoodDto]{
+		Body: catalogFoodDto,
+	}
+	c.JSON(200, response)
+}
+
+// UpdateCatalogFood godoc
+//	@Summary		Update food
+//	@Description	update the food of the catalog with the provided id; administrators only
+//	@Tags			food
+//	@Accept			json
+//	@Produce		json
+//	@Param			foodId			path		string				true	"Food ID"
+//	@Param			catalogFoodDto	body		dto.CatalogFoodDto	true	"Food to update"
+//	@Success		200				{object}	dto.BaseResponse[dto.CatalogFoodDto]
+//	@Router			/food/{foodId}/ [patch]
+func (s *CatalogFoodController) UpdateCatalogFood(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("foodId"))
+	if err != nil {
+		abortWithValidationError(c, err)
+		return
+	}
+	var catalogFoodDto dto.CatalogFoodDto
+	err = c.ShouldBindJSON(&catalogFoodDto)
+	if err != nil {
+		abortWithValidationError(c, err)
+		return
+	}
+	catalogFoodDto.ID = id
+	catalogFoodDto, err = s.catalogFoodService.Update(catalogFoodDto)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	response := dto.BaseResponse[dto.CatalogFoodDto]{
+		Body: catalogFoodDto,
+	}
+	c.JSON(200, response)
+}
+
+// DeleteCatalogFood godoc
+//	@Summary		Delete food
+//	@Description	delete the food of the catalog with the provided id; administrators only
+//	@Tags			food
+//	@Produce		json
+//	@Param			foodId	path		string	true	"Food ID"
+//	@Success		200		{object}	dto.BaseResponse[bool]
+//	@Router			/food/{foodId}/ [delete]
+func (s *CatalogFoodController) DeleteCatalogFood(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("foodId"))
+	if err != nil {
+		abortWithValidationError(c, err)
+		return
+	}
+	err = s.catalogFoodService.Delete(id)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	response := dto.BaseResponse[bool]{
+		Body: err == nil,
+	}
+	c.JSON(200, response)
+}
@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"food-track-be/service"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"net/http"
+	"testing"
+)
+
+const catalogFoodBody = `{"name": "Egg", "defaultUnit": "pcs", "kcal": 143}`
+
+func TestCatalogFoodChangesAreForbiddenToUsers(t *testing.T) {
+	foodId := uuid.New()
+	requests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{"create", http.MethodPost, "/api/food/", catalogFoodBody},
+		{"update", http.MethodPatch, "/api/food/" + foodId.String() + "/", catalogFoodBody},
+		{"delete", http.MethodDelete, "/api/food/" + foodId.String() + "/", ""},
+	}
+	for _, request := range requests {
+		t.Run(request.name, func(t *testing.T) {
+			r, mock := newTestRouter(t)
+
+			recorder := serve(r, request.method, request.path, request.body)
+
+			assertError(t, recorder, mock, http.StatusForbidden, service.Forbidden)
+		})
+	}
+}
+
+func TestCatalogFoodChangesAreAllowedToAdministrators(t *testing.T) {
+	r, mock := newTestRouter(t)
+	foodId := uuid.New()
+	mock.ExpectQuery(`FROM "catalog_food" AS "cf" WHERE .*id = '` + foodId.String() + `'`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(foodId.String(), "Egg"))
+	mock.ExpectExec(`DELETE FROM "catalog_food" AS "cf" WHERE .*'` + foodId.String() + `'`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	recorder := serveWithToken(r, adminToken, http.MethodDelete, "/api/food/"+foodId.String()+"/", "")
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
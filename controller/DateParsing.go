@@ -0,0 +1,30 @@
+package controller
+
+import (
+	"food-track-be/service"
+	"time"
+)
+
+// legacyDateLayout is the dd-MM-yyyy layout of the dates in the query parameters, accepted alongside ISO-8601.
+const legacyDateLayout = "02-01-2006"
+
+// parseDate parses a date query parameter, either in the legacy dd-MM-yyyy layout or as an ISO-8601 date (yyyy-MM-dd)
+// or date-time with offset. Dates without a time are the start of the day in the location, date-times are converted
+// to it.
+func parseDate(value string, location *time.Location) (time.Time, error) {
+	for _, layout := range []string{legacyDateLayout, time.DateOnly} {
+		if date, err := time.ParseInLocation(layout, value, location); err == nil {
+			return date, nil
+		}
+	}
+	if date, err := time.Parse(time.RFC3339, value); err == nil {
+		return date.In(location), nil
+	}
+	return time.Time{}, service.NewValidationError("invalid date " + value + ", expected dd-MM-yyyy or ISO-8601")
+}
+
+// startOfDay returns the midnight which starts the day of t, in its location.
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,14 @@
+package controller
+
+import "github.com/gin-gonic/gin"
+
+// abortWithError aborts the request with the error, which is turned into the response by middleware.ErrorHandler.
+func abortWithError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
+
+// abortWithValidationError aborts the request because of an invalid parameter or body.
+func abortWithValidationError(c *gin.Context, err error) {
+	abortWithError(c, newValidationError(err))
+}
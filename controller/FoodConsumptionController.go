@@ -1,23 +1,20 @@
 package controller
 
 import (
-	"context"
-	firebase "firebase.google.com/go/v4"
+	"errors"
+	"food-track-be/middleware"
 	"food-track-be/model/dto"
 	"food-track-be/service"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"log"
-	"strings"
 )
 
 type FoodConsumptionController struct {
 	foodConsumptionService *service.FoodConsumptionService
-	firebaseApp            *firebase.App
 }
 
-func NewFoodConsumptionController(foodConsumptionService *service.FoodConsumptionService, fa *firebase.App) *FoodConsumptionController {
-	return &FoodConsumptionController{foodConsumptionService: foodConsumptionService, firebaseApp: fa}
+func NewFoodConsumptionController(foodConsumptionService *service.FoodConsumptionService) *FoodConsumptionController {
+	return &FoodConsumptionController{foodConsumptionService: foodConsumptionService}
 }
 
 // FindAllConsumptionForMeal godoc
@@ -27,21 +24,16 @@ func NewFoodConsumptionController(foodConsumptionService *service.FoodConsumptio
 //	@Produce		json
 //	@Param			mealId	path		string	true	"Meal ID"
 //	@Success		200		{object}	dto.BaseResponse[[]dto.FoodConsumptionDto]
-//	@Router			/{mealId}/consumption/ [get]
+//	@Router			/meal/{mealId}/consumption/ [get]
 func (s *FoodConsumptionController) FindAllConsumptionForMeal(c *gin.Context) {
-	_, err := s.validateTokenAndGetUserId(c.GetHeader("Authorization"))
-	if err != nil {
-		s.abortWithMessage(c, err.Error())
-		return
-	}
 	mealId, err := uuid.Parse(c.Param("mealId"))
 	if err != nil {
-		s.abortWithMessage(c, err.Error())
+		abortWithValidationError(c, err)
 		return
 	}
-	foodConsumptionDtos, err := s.foodConsumptionService.FindAllFoodConsumptionForMeal(mealId)
+	foodConsumptionDtos, err := s.foodConsumptionService.FindAllFoodConsumptionForMeal(mealId, middleware.GetUserId(c))
 	if err != nil {
-		s.abortWithMessage(c, err.Error())
+		abortWithError(c, err)
 		return
 	}
 	c.JSON(200, dto.BaseResponse[[]*dto.FoodConsumptionDto]{
@@ -58,28 +50,24 @@ func (s *FoodConsumptionController) FindAllConsumptionForMeal(c *gin.Context) {
 //	@Param			mealId				path		string					true	"Meal ID"
 //	@Param			foodConsumptionDto	body		dto.FoodConsumptionDto	true	"Food Consumption"
 //	@Success		200					{object}	dto.BaseResponse[dto.FoodConsumptionDto]
-//	@Router			/{mealId}/consumption/ [post]
+//	@Router			/meal/{mealId}/consumption/ [post]
 func (s *FoodConsumptionController) AddFoodConsumption(c *gin.Context) {
 	mealId, err := uuid.Parse(c.Param("mealId"))
 	if err != nil {
-		s.abortWithMessage(c, err.Error())
-		return
-	}
-	token := c.GetHeader("Authorization")
-	_, err = s.validateTokenAndGetUserId(token)
-	if err != nil {
-		s.abortWithMessage(c, err.Error())
+		abortWithValidationError(c, err)
 		return
 	}
+	token := middleware.GetToken(c)
+	userId := middleware.GetUserId(c)
 	var foodConsumptionDto dto.FoodConsumptionDto
-	err = c.BindJSON(&foodConsumptionDto)
+	err = c.ShouldBindJSON(&foodConsumptionDto)
 	if err != nil {
-		s.abortWithMessage(c, err.Error())
+		abortWithValidationError(c, err)
 		return
 	}
-	foodConsumptionDto, err = s.foodConsumptionService.CreateFoodConsumptionForMeal(mealId, foodConsumptionDto, token)
+	foodConsumptionDto, err = s.foodConsumptionService.CreateFoodConsumptionForMeal(mealId, userId, foodConsumptionDto, token)
 	if err != nil {
-		s.abortWithMessage(c, err.Error())
+		abortWithError(c, err)
 		return
 	}
 	c.JSON(200, dto.BaseResponse[dto.FoodConsumptionDto]{
@@ -96,24 +84,24 @@ func (s *FoodConsumptionController) AddFoodConsumption(c *gin.Context) {
 //	@Param			mealId				path		string					true	"Meal ID"
 //	@Param			foodConsumptionDto	body		dto.FoodConsumptionDto	true	"Food Consumption"
 //	@Success		200					{object}	dto.BaseResponse[dto.FoodConsumptionDto]
-//	@Router			/{mealId}/consumption/ [patch]
+//	@Router			/meal/{mealId}/consumption/ [patch]
 func (s *FoodConsumptionController) UpdateFoodConsumption(c *gin.Context) {
 	mealId, err := uuid.Parse(c.Param("mealId"))
 	if err != nil {
-		s.abortWithMessage(c, err.Error())
+		abortWithValidationError(c, err)
 		return
 	}
-	token := c.GetHeader("Authorization")
-	_, err = s.validateTokenAndGetUserId(token)
+	token := middleware.GetToken(c)
+	userId := middleware.GetUserId(c)
+	var foodConsumptionDto dto.FoodConsumptionDto
+	err = c.ShouldBindJSON(&foodConsumptionDto)
 	if err != nil {
-		s.abortWithMessage(c, err.Error())
+		abortWithValidationError(c, err)
 		return
 	}
-	var foodConsumptionDto dto.FoodConsumptionDto
-	c.BindJSON(&foodConsumptionDto)
-	foodConsumptionDto, err = s.foodConsumptionService.UpdateFoodConsumptionForMeal(mealId, foodConsumptionDto, token)
+	foodConsumptionDto, err = s.foodConsumptionService.UpdateFoodConsumptionForMeal(mealId, userId, foodConsumptionDto, token)
 	if err != nil {
-		s.abortWithMessage(c, err.Error())
+		abortWithError(c, err)
 		return
 	}
 	c.JSON(200, dto.BaseResponse[dto.FoodConsumptionDto]{
@@ -123,53 +111,73 @@ func (s *FoodConsumptionController) UpdateFoodConsumption(c *gin.Context) {
 
 // DeleteFoodConsumption godoc
 //	@Summary		Delete consumption for the meal
-//	@Description	delete consumption for the meal by mealId
+//	@Description	move the consumption of the meal to the trash
 //	@Tags			food-consumption
 //	@Accept			json
 //	@Produce		json
 //	@Param			mealId				path		string	true	"Meal ID"
 //	@Param			foodConsumptionId	path		string	true	"Food consumption ID"
 //	@Success		200					{object}	dto.BaseResponse[bool]
-//	@Router			/{mealId}/consumption/ [delete]
+//	@Router			/meal/{mealId}/consumption/{foodConsumptionId}/ [delete]
 func (s *FoodConsumptionController) DeleteFoodConsumption(c *gin.Context) {
 	mealId, err := uuid.Parse(c.Param("mealId"))
-	foodConsumptionId, err := uuid.Parse(c.Param("foodConsumptionId"))
 	if err != nil {
-		s.abortWithMessage(c, err.Error())
+		abortWithValidationError(c, err)
 		return
 	}
-	token := c.GetHeader("Authorization")
-	_, err = s.validateTokenAndGetUserId(token)
+	foodConsumptionId, err := uuid.Parse(c.Param("foodConsumptionId"))
 	if err != nil {
-		s.abortWithMessage(c, err.Error())
+		abortWithValidationError(c, err)
 		return
 	}
-	err = s.foodConsumptionService.DeleteFoodConsumptionForMeal(mealId, foodConsumptionId, token)
+	token := middleware.GetToken(c)
+	userId := middleware.GetUserId(c)
+	err = s.foodConsumptionService.DeleteFoodConsumptionForMeal(mealId, userId, foodConsumptionId, token)
 	if err != nil {
-		s.abortWithMessage(c, err.Error())
+		abortWithError(c, err)
 		return
 	}
 	c.JSON(200, dto.BaseResponse[bool]{
-		Body: err != nil,
+		Body: true,
 	})
 }
 
-func (s *FoodConsumptionController) abortWithMessage(c *gin.Context, message string) {
-	log.Println(message)
-	c.AbortWithStatusJSON(200, dto.BaseResponse[any]{
-		ErrorMessage: message,
-	})
-}
-
-func (s *FoodConsumptionController) validateTokenAndGetUserId(authHeader string) (string, error) {
-	auth, err := s.firebaseApp.Auth(context.Background())
-	filteredToken := strings.Replace(authHeader, "Bearer ", "", 1)
+// LookupBarcode godoc
+//	@Summary		Lookup barcode
+//	@Description	find the food with the barcode in the pantry and in the food catalog, with its available transactions and a consumption draft ready to be added to a meal
+//	@Tags			food-consumption
+//	@Produce		json
+//	@Param			code		path		string	true	"Barcode"
+//	@Param			pantryId	query		string	true	"Pantry of grocery-be to search"
+//	@Param			quantity	query		number	false	"Quantity of the draft, default 100 g or ml or 1 of other units"
+//	@Success		200			{object}	dto.BaseResponse[dto.BarcodeLookupDto]
+//	@Router			/food/barcode/{code}/ [get]
+func (s *FoodConsumptionController) LookupBarcode(c *gin.Context) {
+	pantryId := c.Query("pantryId")
+	if pantryId == "" {
+		abortWithValidationError(c, errors.New("pantryId is required"))
+		return
+	}
+	quantity, err := parseOptionalFloat(c, "quantity")
 	if err != nil {
-		return "", err
+		abortWithValidationError(c, err)
+		return
 	}
-	token, err := auth.VerifyIDToken(context.Background(), filteredToken)
+	if quantity == nil {
+		quantity = new(float32)
+	}
+	if *quantity < 0 {
+		abortWithValidationError(c, errors.New("quantity must be positive"))
+		return
+	}
+	token := middleware.GetToken(c)
+	userId := middleware.GetUserId(c)
+	lookupDto, err := s.foodConsumptionService.LookupBarcode(c.Param("code"), pantryId, *quantity, userId, token)
 	if err != nil {
-		return "", err
+		abortWithError(c, err)
+		return
 	}
-	return token.UID, nil
+	c.JSON(200, dto.BaseResponse[dto.BarcodeLookupDto]{
+		Body: lookupDto,
+	})
 }
@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"encoding/json"
+	"food-track-be/model/dto"
+	"food-track-be/service"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"net/http"
+	"testing"
+)
+
+func foodConsumptionBody(foodConsumptionId uuid.UUID) string {
+	return `{"id": "` + foodConsumptionId.String() + `", "foodName": "pasta", "quantityUsed": 80, "unit": "g"}`
+}
+
+func TestFoodConsumptionsOfAnotherUserMealAreNotFound(t *testing.T) {
+	foodConsumptionId := uuid.New()
+	requests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{"read", http.MethodGet, "consumption/", ""},
+		{"create", http.MethodPost, "consumption/", foodConsumptionBody(uuid.Nil)},
+		{"update", http.MethodPatch, "consumption/" + foodConsumptionId.String() + "/", foodConsumptionBody(foodConsumptionId)},
+		{"delete", http.MethodDelete, "consumption/" + foodConsumptionId.String() + "/", ""},
+	}
+	for _, request := range requests {
+		t.Run(request.name, func(t *testing.T) {
+			r, mock := newTestRouter(t)
+			mealId := uuid.New()
+			expectMealOfAnotherUser(mock, mealId)
+
+			recorder := serve(r, request.method, "/api/meal/"+mealId.String()+"/"+request.path, request.body)
+
+			assertNotFound(t, recorder, mock)
+		})
+	}
+}
+
+// The food consumption of another user's meal can't be reached through a meal of the user either.
+func TestFoodConsumptionOfAnotherMealIsNotFound(t *testing.T) {
+	requests := []struct {
+		name   string
+		method string
+		body   func(foodConsumptionId uuid.UUID) string
+	}{
+		{"update", http.MethodPatch, foodConsumptionBody},
+		{"delete", http.MethodDelete, func(uuid.UUID) string { return "" }},
+	}
+	for _, request := range requests {
+		t.Run(request.name, func(t *testing.T) {
+			r, mock := newTestRouter(t)
+			mealId := uuid.New()
+			foodConsumptionId := uuid.New()
+			mock.ExpectQuery(`FROM "meal" AS "m" .*WHERE .*m\.id = '` + mealId.String() + `'.* AND \(m\.user_id = '` + testUserId + `'\)`).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "status"}).AddRow(mealId.String(), testUserId, "eaten"))
+			mock.ExpectQuery(`FROM "food_consumption" AS "fc" WHERE .*id = '` + foodConsumptionId.String() + `'`).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "meal_id"}).AddRow(foodConsumptionId.String(), uuid.New().String()))
+
+			path := "/api/meal/" + mealId.String() + "/consumption/" + foodConsumptionId.String() + "/"
+			recorder := serve(r, request.method, path, request.body(foodConsumptionId))
+
+			assertNotFound(t, recorder, mock)
+		})
+	}
+}
+
+func TestDeleteFoodConsumptionWithInvalidMealIdIsInvalid(t *testing.T) {
+	r, mock := newTestRouter(t)
+
+	recorder := serve(r, http.MethodDelete, "/api/meal/not-a-meal/consumption/"+uuid.New().String()+"/", "")
+
+	assertError(t, recorder, mock, http.StatusBadRequest, service.Validation)
+}
+
+func TestDeleteFoodConsumptionAnswersTrue(t *testing.T) {
+	r, mock := newTestRouter(t)
+	mealId := uuid.New()
+	foodConsumptionId := uuid.New()
+	mock.ExpectQuery(`FROM "meal" AS "m" .*WHERE .*m\.id = '` + mealId.String() + `'.* AND \(m\.user_id = '` + testUserId + `'\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "status"}).AddRow(mealId.String(), testUserId, "eaten"))
+	mock.ExpectQuery(`FROM "food_consumption" AS "fc" WHERE .*id = '` + foodConsumptionId.String() + `'`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "meal_id", "food_name"}).AddRow(foodConsumptionId.String(), mealId.String(), "pasta"))
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "food_consumption" AS "fc" SET "deleted_at" = .*'` + foodConsumptionId.String() + `'`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	// The food consumption doesn't use the pantry: the saga has no entry to deliver.
+	mock.ExpectQuery(`UPDATE "grocery_outbox" .* RETURNING \*`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	recorder := serve(r, http.MethodDelete, "/api/meal/"+mealId.String()+"/consumption/"+foodConsumptionId.String()+"/", "")
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	var response dto.BaseResponse[bool]
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	if !response.Body {
+		t.Fatalf("expected true, got %s", recorder.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,145 @@
+package controller
+
+import (
+	"food-track-be/middleware"
+	"food-track-be/model/dto"
+	"food-track-be/service"
+	"github.com/gin-gonic/gin"
+	"time"
+)
+
+type GoalController struct {
+	goalService *service.GoalService
+}
+
+func NewGoalController(goalService *service.GoalService) *GoalController {
+	return &GoalController{goalService: goalService}
+}
+
+// FindGoal godoc
+//	@Summary		Get goal
+//	@Description	get the daily nutrition goal of the user
+//	@Tags			goal
+//	@Produce		json
+//	@Success		200	{object}	dto.BaseResponse[dto.GoalDto]
+//	@Router			/goal/ [get]
+func (s *GoalController) FindGoal(c *gin.Context) {
+	userId := middleware.GetUserId(c)
+	goalDto, err := s.goalService.FindByUserId(userId)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	response := dto.BaseResponse[dto.GoalDto]{
+		Body: goalDto,
+	}
+	c.JSON(200, response)
+}
+
+// CreateGoal godoc
+//	@Summary		Create goal
+//	@Description	create the daily nutrition goal of the user
+//	@Tags			goal
+//	@Accept			json
+//	@Produce		json
+//	@Param			goalDto	body		dto.GoalDto	true	"Goal to create"
+//	@Success		200		{object}	dto.BaseResponse[dto.GoalDto]
+//	@Router			/goal/ [post]
+func (s *GoalController) CreateGoal(c *gin.Context) {
+	var goalDto dto.GoalDto
+	err := c.ShouldBindJSON(&goalDto)
+	if err != nil {
+		abortWithValidationError(c, err)
+		return
+	}
+	userId := middleware.GetUserId(c)
+	goalDto.UserId = userId
+	goalDto, err = s.goalService.Create(goalDto)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	response := dto.BaseResponse[dto.GoalDto]{
+		Body: goalDto,
+	}
+	c.JSON(200, response)
+}
+
+// UpdateGoal godoc
+//	@Summary		Update goal
+//	@Description	update the daily nutrition goal of the user
+//	@Tags			goal
+//	@Accept			json
+//	@Produce		json
+//	@Param			goalDto	body		dto.GoalDto	true	"Goal to update"
+//	@Success		200		{object}	dto.BaseResponse[dto.GoalDto]
+//	@Router			/goal/ [patch]
+func (s *GoalController) UpdateGoal(c *gin.Context) {
+	var goalDto dto.GoalDto
+	err := c.ShouldBindJSON(&goalDto)
+	if err != nil {
+		abortWithValidationError(c, err)
+		return
+	}
+	userId := middleware.GetUserId(c)
+	goalDto, err = s.goalService.Update(goalDto, userId)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	response := dto.BaseResponse[dto.GoalDto]{
+		Body: goalDto,
+	}
+	c.JSON(200, response)
+}
+
+// DeleteGoal godoc
+//	@Summary		Delete goal
+//	@Description	delete the daily nutrition goal of the user
+//	@Tags			goal
+//	@Produce		json
+//	@Success		200	{object}	dto.BaseResponse[bool]
+//	@Router			/goal/ [delete]
+func (s *GoalController) DeleteGoal(c *gin.Context) {
+	userId := middleware.GetUserId(c)
+	err := s.goalService.Delete(userId)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	response := dto.BaseResponse[bool]{
+		Body: err == nil,
+	}
+	c.JSON(200, response)
+}
+
+// GetDailyProgress godoc
+//	@Summary		Get daily progress
+//	@Description	get the progress of the provided day (default is today) against the user's daily goal
+//	@Tags			goal
+//	@Produce		json
+//	@Param			date	query		string	false	"Day to check"
+//	@Success		200		{object}	dto.BaseResponse[dto.GoalProgressDto]
+//	@Router			/meal/progress/ [get]
+func (s *GoalController) GetDailyProgress(c *gin.Context) {
+	userId := middleware.GetUserId(c)
+	var err error
+	location := middleware.GetLocation(c)
+	date := startOfDay(time.Now().In(location))
+	if dateParam := c.Query("date"); dateParam != "" {
+		date, err = parseDate(dateParam, location)
+		if err != nil {
+			abortWithValidationError(c, err)
+			return
+		}
+	}
+	goalProgressDto, err := s.goalService.GetDailyProgress(date, userId)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	response := dto.BaseResponse[dto.GoalProgressDto]{
+		Body: goalProgressDto,
+	}
+	c.JSON(200, response)
+}
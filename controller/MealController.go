@@ -1,70 +1,77 @@
 package controller
 
 import (
-	"context"
-	firebase "firebase.google.com/go/v4"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"food-track-be/middleware"
+	"food-track-be/model"
 	"food-track-be/model/dto"
 	"food-track-be/service"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"log"
-	"strings"
+	"net/http"
+	"strconv"
 	"time"
 )
 
 type MealController struct {
-	mealService *service.MealService
-	firebaseApp *firebase.App
+	mealService       *service.MealService
+	mealImportService *service.MealImportService
 }
 
-func NewMealController(mealService *service.MealService, fa *firebase.App) *MealController {
-	return &MealController{mealService: mealService, firebaseApp: fa}
+func NewMealController(mealService *service.MealService, mealImportService *service.MealImportService) *MealController {
+	return &MealController{mealService: mealService, mealImportService: mealImportService}
 }
 
 // FindAllMeals godoc
 //	@Summary		Get all meals
-//	@Description	get all the meals which satisfies the query parameters (startRange, endRange) or all meals if no query parameters are provided
+//	@Description	get all the meals which satisfy the query parameters, sorted by date and id. When limit or cursor is given, the body is a page of them: {"items": [...], "nextCursor": "..."}
 //	@Tags			meal
 //	@Produce		json
 //	@Param			startRange	query		string	false	"Start date of the range"
 //	@Param			endRange	query		string	false	"End date of the range"
+//	@Param			status		query		string	false	"Meal status (planned or eaten)"
+//	@Param			mealType	query		string	false	"Meal type"
+//	@Param			name		query		string	false	"Substring of the meal name"
+//	@Param			minKcal		query		number	false	"Minimum kcal of the meal"
+//	@Param			maxKcal		query		number	false	"Maximum kcal of the meal"
+//	@Param			minCost		query		number	false	"Minimum cost of the meal"
+//	@Param			maxCost		query		number	false	"Maximum cost of the meal"
+//	@Param			sort		query		string	false	"Sort order by date (asc or desc, default desc)"
+//	@Param			limit		query		int		false	"Page size (default 50, max 200)"
+//	@Param			cursor		query		string	false	"Cursor of the page, as returned by the previous page"
 //	@Success		200			{object}	dto.BaseResponse[[]dto.MealDto]
-//	@Router			/ [get]
+//	@Router			/meal/ [get]
 func (s *MealController) FindAllMeals(c *gin.Context) {
-	var mealDtos = make([]dto.MealDto, 0)
-	startRangeParam := c.Query("startRange")
-	endRangeParam := c.Query("endRange")
-	userId, err := s.validateTokenAndGetUserId(c.GetHeader("Authorization"))
+	userId := middleware.GetUserId(c)
+	query, err := s.parseMealQuery(c)
 	if err != nil {
-		s.abortWithMessage(c, err.Error())
+		abortWithValidationError(c, err)
 		return
 	}
-	if startRangeParam != "" && endRangeParam != "" {
-		startRange, err := time.Parse("02-01-2006", startRangeParam)
+	// Clients which don't page get the array of all the meals, as before pagination.
+	if query.Cursor == "" && c.Query("limit") == "" {
+		mealDtos, err := s.mealService.FindAll(query, userId)
 		if err != nil {
-			s.abortWithMessage(c, err.Error())
-			return
-		}
-		endRange, err := time.Parse("02-01-2006", endRangeParam)
-		if err != nil {
-			endRange = startRange
-		}
-		mealDtos, err = s.mealService.FindAllInDateRange(startRange, endRange, userId)
-		if err != nil {
-			s.abortWithMessage(c, err.Error())
-			return
-		}
-	} else {
-		var err error
-		mealDtos, err = s.mealService.FindAll(userId)
-		if err != nil {
-			s.abortWithMessage(c, err.Error())
+			abortWithError(c, err)
 			return
 		}
+		c.JSON(200, dto.BaseResponse[[]dto.MealDto]{
+			Body: mealDtos,
+		})
+		return
+	}
+	page, err := s.mealService.FindPage(query, userId)
+	if err != nil {
+		abortWithError(c, err)
+		return
 	}
 
-	response := dto.BaseResponse[[]dto.MealDto]{
-		Body: mealDtos,
+	response := dto.BaseResponse[dto.PageDto[dto.MealDto]]{
+		Body: page,
 	}
 	c.JSON(200, response)
 }
@@ -76,17 +83,13 @@ func (s *MealController) FindAllMeals(c *gin.Context) {
 //	@Produce		json
 //	@Param			mealId	path		string	true	"Meal ID"
 //	@Success		200		{object}	dto.BaseResponse[dto.MealDto]
-//	@Router			/{mealId}/ [get]
+//	@Router			/meal/{mealId}/ [get]
 func (s *MealController) FindMealById(c *gin.Context) {
 	id, _ := uuid.Parse(c.Param("mealId"))
-	userId, err := s.validateTokenAndGetUserId(c.GetHeader("Authorization"))
-	if err != nil {
-		s.abortWithMessage(c, err.Error())
-		return
-	}
+	userId := middleware.GetUserId(c)
 	mealDto, err := s.mealService.FindById(id, userId)
 	if err != nil {
-		s.abortWithMessage(c, err.Error())
+		abortWithError(c, err)
 		return
 	}
 	response := dto.BaseResponse[dto.MealDto]{
@@ -103,23 +106,19 @@ func (s *MealController) FindMealById(c *gin.Context) {
 //	@Produce		json
 //	@Param			mealDto	body		dto.MealDto	true	"Meal to create"
 //	@Success		200		{object}	dto.BaseResponse[dto.MealDto]
-//	@Router			/ [post]
+//	@Router			/meal/ [post]
 func (s *MealController) CreateMeal(c *gin.Context) {
 	var mealDto dto.MealDto
-	err := c.BindJSON(&mealDto)
-	if err != nil {
-		s.abortWithMessage(c, err.Error())
-		return
-	}
-	userId, err := s.validateTokenAndGetUserId(c.GetHeader("Authorization"))
+	err := c.ShouldBindJSON(&mealDto)
 	if err != nil {
-		s.abortWithMessage(c, err.Error())
+		abortWithValidationError(c, err)
 		return
 	}
+	userId := middleware.GetUserId(c)
 	mealDto.UserId = userId
 	mealDto, err = s.mealService.Create(mealDto)
 	if err != nil {
-		s.abortWithMessage(c, err.Error())
+		abortWithError(c, err)
 		return
 	}
 	response := dto.BaseResponse[dto.MealDto]{
@@ -137,20 +136,20 @@ func (s *MealController) CreateMeal(c *gin.Context) {
 //	@Param			mealId	path		string		true	"Meal ID"
 //	@Param			mealDto	body		dto.MealDto	true	"Meal to create"
 //	@Success		200		{object}	dto.BaseResponse[dto.MealDto]
-//	@Router			/{mealId}/ [patch]
+//	@Router			/meal/{mealId}/ [patch]
 func (s *MealController) UpdateMeal(c *gin.Context) {
 	var mealDto dto.MealDto
 	id, _ := uuid.Parse(c.Param("mealId"))
-	err := c.BindJSON(&mealDto)
-	userId, err := s.validateTokenAndGetUserId(c.GetHeader("Authorization"))
+	err := c.ShouldBindJSON(&mealDto)
 	if err != nil {
-		s.abortWithMessage(c, err.Error())
+		abortWithValidationError(c, err)
 		return
 	}
+	userId := middleware.GetUserId(c)
 	mealDto.ID = id
 	mealDto, err = s.mealService.Update(mealDto, userId)
 	if err != nil {
-		s.abortWithMessage(c, err.Error())
+		abortWithError(c, err)
 		return
 	}
 	response := dto.BaseResponse[dto.MealDto]{
@@ -161,27 +160,37 @@ func (s *MealController) UpdateMeal(c *gin.Context) {
 
 // DeleteMeal godoc
 //	@Summary		Delete meal
-//	@Description	delete the meal with the provided id
+//	@Description	move the meal with the provided id to the trash together with its consumptions, giving the quantities used back to the pantry
 //	@Tags			meal
 //	@Accept			json
 //	@Produce		json
-//	@Param			mealId	path		string	true	"Meal ID"
-//	@Success		200		{object}	dto.BaseResponse[bool]
-//	@Router			/{mealId}/ [delete]
+//	@Param			mealId			path		string	true	"Meal ID"
+//	@Param			restoreStock	query		bool	false	"Give the quantities taken from the pantry back to it (default true)"
+//	@Success		200				{object}	dto.BaseResponse[bool]
+//	@Router			/meal/{mealId}/ [delete]
 func (s *MealController) DeleteMeal(c *gin.Context) {
-	id, _ := uuid.Parse(c.Param("mealId"))
-	userId, err := s.validateTokenAndGetUserId(c.GetHeader("Authorization"))
+	id, err := uuid.Parse(c.Param("mealId"))
 	if err != nil {
-		s.abortWithMessage(c, err.Error())
+		abortWithValidationError(c, err)
 		return
 	}
-	err = s.mealService.Delete(id, userId)
+	restoreStock := true
+	if value := c.Query("restoreStock"); value != "" {
+		restoreStock, err = strconv.ParseBool(value)
+		if err != nil {
+			abortWithValidationError(c, err)
+			return
+		}
+	}
+	token := middleware.GetToken(c)
+	userId := middleware.GetUserId(c)
+	err = s.mealService.Delete(id, userId, restoreStock, token)
 	if err != nil {
-		s.abortWithMessage(c, err.Error())
+		abortWithError(c, err)
 		return
 	}
 	response := dto.BaseResponse[bool]{
-		Body: err == nil,
+		Body: true,
 	}
 	c.JSON(200, response)
 }
@@ -194,38 +203,35 @@ func (s *MealController) DeleteMeal(c *gin.Context) {
 //	@Param			startRange	query		string	false	"Start date of the range"
 //	@Param			endRange	query		string	false	"End date of the range"
 //	@Success		200			{object}	dto.BaseResponse[dto.MealStatisticsDto]
-//	@Router			/statistics/ [get]
+//	@Router			/meal/statistics/ [get]
 func (s *MealController) GetMealStatistics(c *gin.Context) {
 	var mealStatisticsDto dto.MealStatisticsDto
 	startRangeParam := c.Query("startRange")
 	endRangeParam := c.Query("endRange")
-	userId, err := s.validateTokenAndGetUserId(c.GetHeader("Authorization"))
-	if err != nil {
-		s.abortWithMessage(c, err.Error())
-		return
-	}
+	userId := middleware.GetUserId(c)
+	location := middleware.GetLocation(c)
 	if startRangeParam != "" && endRangeParam != "" {
-		startRange, err := time.Parse("02-01-2006", startRangeParam)
+		startRange, err := parseDate(startRangeParam, location)
 		if err != nil {
-			s.abortWithMessage(c, err.Error())
+			abortWithValidationError(c, err)
 			return
 		}
-		endRange, err := time.Parse("02-01-2006", endRangeParam)
+		endRange, err := parseDate(endRangeParam, location)
 		if err != nil {
 			endRange = startRange
 		}
 		mealStatisticsDto, err = s.mealService.GetMealsStatistics(startRange, endRange, userId)
 		if err != nil {
-			s.abortWithMessage(c, err.Error())
+			abortWithError(c, err)
 			return
 		}
 	} else {
-		startRange := time.Now().AddDate(0, 0, -7)
-		endRange := time.Now()
+		startRange := time.Now().In(location).AddDate(0, 0, -7)
+		endRange := time.Now().In(location)
 		var err error
 		mealStatisticsDto, err = s.mealService.GetMealsStatistics(startRange, endRange, userId)
 		if err != nil {
-			s.abortWithMessage(c, err.Error())
+			abortWithError(c, err)
 			return
 		}
 	}
@@ -236,22 +242,410 @@ func (s *MealController) GetMealStatistics(c *gin.Context) {
 	c.JSON(200, response)
 }
 
-func (s *MealController) abortWithMessage(c *gin.Context, message string) {
-	log.Println(message)
-	c.AbortWithStatusJSON(200, dto.BaseResponse[any]{
-		ErrorMessage: message,
+// GetMealTimeseries godoc
+//	@Summary		Get meal statistics time series
+//	@Description	get the kcal, cost and number of the meals eaten in the date range (default is the past 30 days), in buckets of a day, an ISO week or a month, in total and per meal type
+//	@Tags			meal
+//	@Produce		json
+//	@Param			startRange	query		string	false	"Start date of the range"
+//	@Param			endRange	query		string	false	"End date of the range"
+//	@Param			granularity	query		string	false	"Size of the buckets (day, week or month, default day)"
+//	@Success		200			{object}	dto.BaseResponse[dto.MealTimeseriesDto]
+//	@Router			/meal/statistics/timeseries/ [get]
+func (s *MealController) GetMealTimeseries(c *gin.Context) {
+	userId := middleware.GetUserId(c)
+	var err error
+	location := middleware.GetLocation(c)
+	startRange := time.Now().In(location).AddDate(0, 0, -30)
+	endRange := time.Now().In(location)
+	if startRangeParam := c.Query("startRange"); startRangeParam != "" {
+		startRange, err = parseDate(startRangeParam, location)
+		if err != nil {
+			abortWithValidationError(c, err)
+			return
+		}
+	}
+	if endRangeParam := c.Query("endRange"); endRangeParam != "" {
+		endRange, err = parseDate(endRangeParam, location)
+		if err != nil {
+			abortWithValidationError(c, err)
+			return
+		}
+	}
+	granularity := dto.TimeseriesGranularity(c.DefaultQuery("granularity", string(dto.Day)))
+	timeseriesDto, err := s.mealService.GetTimeseries(startRange, endRange, granularity, userId)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	response := dto.BaseResponse[dto.MealTimeseriesDto]{
+		Body: timeseriesDto,
+	}
+	c.JSON(200, response)
+}
+
+// GeneratePlan godoc
+//	@Summary		Generate meal plan
+//	@Description	plan the week which starts at the provided date (default is next monday) copying the meals eaten in the previous week
+//	@Tags			meal
+//	@Produce		json
+//	@Param			startDate	query		string	false	"First day of the week to plan"
+//	@Success		200			{object}	dto.BaseResponse[[]dto.MealDto]
+//	@Router			/meal/plan/ [post]
+func (s *MealController) GeneratePlan(c *gin.Context) {
+	userId := middleware.GetUserId(c)
+	var err error
+	location := middleware.GetLocation(c)
+	today := startOfDay(time.Now().In(location))
+	startDate := today.AddDate(0, 0, (8-int(today.Weekday()))%7)
+	if startDate.Equal(today) {
+		startDate = startDate.AddDate(0, 0, 7)
+	}
+	if startDateParam := c.Query("startDate"); startDateParam != "" {
+		startDate, err = parseDate(startDateParam, location)
+		if err != nil {
+			abortWithValidationError(c, err)
+			return
+		}
+	}
+	mealsDto, err := s.mealService.GeneratePlan(startDate, userId)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	response := dto.BaseResponse[[]dto.MealDto]{
+		Body: mealsDto,
+	}
+	c.JSON(200, response)
+}
+
+// MarkMealAsEaten godoc
+//	@Summary		Mark meal as eaten
+//	@Description	turn the planned meal into an eaten one, removing its food consumptions from the pantry
+//	@Tags			meal
+//	@Produce		json
+//	@Param			mealId	path		string	true	"Meal ID"
+//	@Success		200		{object}	dto.BaseResponse[dto.MealDto]
+//	@Router			/meal/{mealId}/eaten/ [post]
+func (s *MealController) MarkMealAsEaten(c *gin.Context) {
+	mealId, err := uuid.Parse(c.Param("mealId"))
+	if err != nil {
+		abortWithValidationError(c, err)
+		return
+	}
+	token := middleware.GetToken(c)
+	userId := middleware.GetUserId(c)
+	mealDto, err := s.mealService.MarkAsEaten(mealId, userId, token)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	response := dto.BaseResponse[dto.MealDto]{
+		Body: mealDto,
+	}
+	c.JSON(200, response)
+}
+
+// DuplicateMeal godoc
+//	@Summary		Duplicate meal
+//	@Description	copy the meal, with its consumptions, to the provided dates keeping its time of the day; the quantities used by the copies are removed from the pantry at the current price
+//	@Tags			meal
+//	@Accept			json
+//	@Produce		json
+//	@Param			mealId				path		string					true	"Meal ID"
+//	@Param			duplicateMealDto	body		dto.DuplicateMealDto	true	"Target dates"
+//	@Success		200					{object}	dto.BaseResponse[[]dto.MealDto]
+//	@Router			/meal/{mealId}/duplicate/ [post]
+func (s *MealController) DuplicateMeal(c *gin.Context) {
+	mealId, err := uuid.Parse(c.Param("mealId"))
+	if err != nil {
+		abortWithValidationError(c, err)
+		return
+	}
+	var duplicateMealDto dto.DuplicateMealDto
+	err = c.ShouldBindJSON(&duplicateMealDto)
+	if err != nil {
+		abortWithValidationError(c, err)
+		return
+	}
+	dates := duplicateMealDto.Dates
+	if duplicateMealDto.Date != nil {
+		dates = append([]time.Time{*duplicateMealDto.Date}, dates...)
+	}
+	token := middleware.GetToken(c)
+	userId := middleware.GetUserId(c)
+	mealsDto, err := s.mealService.Duplicate(mealId, userId, dates, middleware.GetLocation(c), token)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	response := dto.BaseResponse[[]dto.MealDto]{
+		Body: mealsDto,
+	}
+	c.JSON(200, response)
+}
+
+// GetPlanReport godoc
+//	@Summary		Get meal plan report
+//	@Description	compare the kcal and cost planned in the date range (default is the last 7 days) with the ones actually eaten
+//	@Tags			meal
+//	@Produce		json
+//	@Param			startRange	query		string	false	"Start date of the range"
+//	@Param			endRange	query		string	false	"End date of the range"
+//	@Success		200			{object}	dto.BaseResponse[dto.PlanReportDto]
+//	@Router			/meal/plan/report/ [get]
+func (s *MealController) GetPlanReport(c *gin.Context) {
+	userId := middleware.GetUserId(c)
+	var err error
+	location := middleware.GetLocation(c)
+	startRange := time.Now().In(location).AddDate(0, 0, -7)
+	endRange := time.Now().In(location)
+	if startRangeParam := c.Query("startRange"); startRangeParam != "" {
+		startRange, err = parseDate(startRangeParam, location)
+		if err != nil {
+			abortWithValidationError(c, err)
+			return
+		}
+	}
+	if endRangeParam := c.Query("endRange"); endRangeParam != "" {
+		endRange, err = parseDate(endRangeParam, location)
+		if err != nil {
+			abortWithValidationError(c, err)
+			return
+		}
+	}
+	planReportDto, err := s.mealService.GetPlanReport(startRange, endRange, userId)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	response := dto.BaseResponse[dto.PlanReportDto]{
+		Body: planReportDto,
+	}
+	c.JSON(200, response)
+}
+
+// ExportMeals godoc
+//	@Summary		Export meals
+//	@Description	stream the meals in the date range (default is the whole history) with their food consumptions, as JSON array or as CSV with a row for every food consumption
+//	@Tags			meal
+//	@Produce		json
+//	@Produce		text/csv
+//	@Param			format		query		string	false	"Export format (json or csv, default json)"
+//	@Param			startRange	query		string	false	"Start date of the range"
+//	@Param			endRange	query		string	false	"End date of the range"
+//	@Success		200			{array}		dto.MealExportDto
+//	@Router			/meal/export/ [get]
+func (s *MealController) ExportMeals(c *gin.Context) {
+	userId := middleware.GetUserId(c)
+	var err error
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		abortWithError(c, service.NewValidationError("format must be json or csv"))
+		return
+	}
+	location := middleware.GetLocation(c)
+	startRange := time.Time{}
+	endRange := time.Date(9999, 12, 31, 0, 0, 0, 0, location)
+	if startRangeParam := c.Query("startRange"); startRangeParam != "" {
+		startRange, err = parseDate(startRangeParam, location)
+		if err != nil {
+			abortWithValidationError(c, err)
+			return
+		}
+	}
+	if endRangeParam := c.Query("endRange"); endRangeParam != "" {
+		endRange, err = parseDate(endRangeParam, location)
+		if err != nil {
+			abortWithValidationError(c, err)
+			return
+		}
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=meals.%s", format))
+	if format == "csv" {
+		err = s.exportMealsAsCsv(c, startRange, endRange, userId)
+	} else {
+		err = s.exportMealsAsJson(c, startRange, endRange, userId)
+	}
+	if err != nil && !c.Writer.Written() {
+		abortWithError(c, err)
+	} else if err != nil {
+		// The export is written while the meals are loaded, so once started an error can only interrupt the response.
+		log.Println(err)
+		c.Abort()
+	}
+}
+
+func (s *MealController) exportMealsAsJson(c *gin.Context, startRange time.Time, endRange time.Time, userId string) error {
+	c.Header("Content-Type", "application/json")
+	c.Status(200)
+	encoder := json.NewEncoder(c.Writer)
+	separator := "["
+	err := s.mealService.Export(startRange, endRange, userId, func(mealExportDto dto.MealExportDto) error {
+		_, err := c.Writer.WriteString(separator)
+		if err != nil {
+			return err
+		}
+		separator = ","
+		return encoder.Encode(mealExportDto)
+	})
+	if err != nil {
+		return err
+	}
+	if separator == "[" {
+		_, err = c.Writer.WriteString("[]")
+	} else {
+		_, err = c.Writer.WriteString("]")
+	}
+	return err
+}
+
+func (s *MealController) exportMealsAsCsv(c *gin.Context, startRange time.Time, endRange time.Time, userId string) error {
+	c.Header("Content-Type", "text/csv")
+	c.Status(200)
+	writer := csv.NewWriter(c.Writer)
+	err := writer.Write(dto.MealExportCsvHeader)
+	if err != nil {
+		return err
+	}
+	err = s.mealService.Export(startRange, endRange, userId, func(mealExportDto dto.MealExportDto) error {
+		mealColumns := []string{
+			mealExportDto.ID.String(), mealExportDto.Name, mealExportDto.Description, string(mealExportDto.MealType),
+			mealExportDto.Date.Format(time.RFC3339), string(mealExportDto.Status),
+		}
+		if len(mealExportDto.FoodConsumptions) == 0 {
+			return writer.Write(append(mealColumns, make([]string, len(dto.MealExportCsvHeader)-len(mealColumns))...))
+		}
+		for _, foodConsumption := range mealExportDto.FoodConsumptions {
+			row := append(mealColumns[:len(mealColumns):len(mealColumns)],
+				foodConsumption.ID.String(), foodConsumption.FoodId.String(), foodConsumption.TransactionId.String(),
+				foodConsumption.FoodName, formatFloat(foodConsumption.QuantityUsed), formatFloat(foodConsumption.QuantityUsedStd),
+				foodConsumption.Unit, foodConsumption.UnitStd, formatFloat(foodConsumption.Kcal), formatFloat(foodConsumption.Protein),
+				formatFloat(foodConsumption.Carbohydrate), formatFloat(foodConsumption.Fat), formatFloat(foodConsumption.Fiber),
+				formatFloat(foodConsumption.Sugar), formatFloat(foodConsumption.Sodium), formatFloat(foodConsumption.Cost),
+			)
+			err := writer.Write(row)
+			if err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
 	})
+	if err != nil {
+		return err
+	}
+	writer.Flush()
+	return writer.Error()
 }
 
-func (s *MealController) validateTokenAndGetUserId(authHeader string) (string, error) {
-	auth, err := s.firebaseApp.Auth(context.Background())
-	filteredToken := strings.Replace(authHeader, "Bearer ", "", 1)
+// ImportMeals godoc
+//	@Summary		Import meals
+//	@Description	import meals and food consumptions from a file with the same shape of the export, nothing is imported if any row is invalid
+//	@Tags			meal
+//	@Accept			json
+//	@Accept			text/csv
+//	@Produce		json
+//	@Param			format	query		string				false	"Import format (json or csv, default json)"
+//	@Param			dryRun	query		bool				false	"Only validate the rows, without importing them"
+//	@Param			meals	body		[]dto.MealExportDto	true	"Meals to import"
+//	@Success		200		{object}	dto.BaseResponse[dto.MealImportResultDto]
+//	@Router			/meal/import/ [post]
+func (s *MealController) ImportMeals(c *gin.Context) {
+	userId := middleware.GetUserId(c)
+	var err error
+	dryRun := false
+	if dryRunParam := c.Query("dryRun"); dryRunParam != "" {
+		dryRun, err = strconv.ParseBool(dryRunParam)
+		if err != nil {
+			abortWithValidationError(c, fmt.Errorf("invalid dryRun: %w", err))
+			return
+		}
+	}
+	result, err := s.mealImportService.Import(c.Request.Body, c.DefaultQuery("format", "json"), userId, dryRun)
 	if err != nil {
-		return "", err
+		abortWithError(c, err)
+		return
+	}
+	response := dto.BaseResponse[dto.MealImportResultDto]{
+		Body: result,
+	}
+	if len(result.Errors) > 0 {
+		response.ErrorMessage = "the import contains invalid rows"
+		response.Error = &dto.ErrorDto{Code: string(service.Validation), Message: response.ErrorMessage}
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	c.JSON(200, response)
+}
+
+func formatFloat(value float32) string {
+	return strconv.FormatFloat(float64(value), 'f', -1, 32)
+}
+
+func (s *MealController) parseMealQuery(c *gin.Context) (dto.MealQueryDto, error) {
+	var query dto.MealQueryDto
+	location := middleware.GetLocation(c)
+	if startRangeParam := c.Query("startRange"); startRangeParam != "" {
+		startRange, err := parseDate(startRangeParam, location)
+		if err != nil {
+			return query, err
+		}
+		query.StartRange = &startRange
+	}
+	if endRangeParam := c.Query("endRange"); endRangeParam != "" {
+		endRange, err := parseDate(endRangeParam, location)
+		if err != nil {
+			return query, err
+		}
+		query.EndRange = &endRange
+	}
+	query.Status = model.MealStatus(c.Query("status"))
+	query.MealType = model.MealType(c.Query("mealType"))
+	query.Name = c.Query("name")
+
+	var err error
+	if query.MinKcal, err = parseOptionalFloat(c, "minKcal"); err != nil {
+		return query, err
+	}
+	if query.MaxKcal, err = parseOptionalFloat(c, "maxKcal"); err != nil {
+		return query, err
+	}
+	if query.MinCost, err = parseOptionalFloat(c, "minCost"); err != nil {
+		return query, err
+	}
+	if query.MaxCost, err = parseOptionalFloat(c, "maxCost"); err != nil {
+		return query, err
+	}
+
+	switch c.DefaultQuery("sort", "desc") {
+	case "asc":
+		query.Ascending = true
+	case "desc":
+		query.Ascending = false
+	default:
+		return query, errors.New("sort must be asc or desc")
+	}
+	if limitParam := c.Query("limit"); limitParam != "" {
+		query.Limit, err = strconv.Atoi(limitParam)
+		if err != nil {
+			return query, fmt.Errorf("invalid limit: %w", err)
+		}
+	}
+	query.Cursor = c.Query("cursor")
+	return query, nil
+}
+
+func parseOptionalFloat(c *gin.Context, name string) (*float32, error) {
+	param := c.Query(name)
+	if param == "" {
+		return nil, nil
 	}
-	token, err := auth.VerifyIDToken(context.Background(), filteredToken)
+	value, err := strconv.ParseFloat(param, 32)
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("invalid %s: %w", name, err)
 	}
-	return token.UID, nil
+	result := float32(value)
+	return &result, nil
 }
@@ -0,0 +1,196 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"food-track-be/middleware"
+	"food-track-be/model/dto"
+	"food-track-be/repository"
+	"food-track-be/service"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const (
+	// testUserId is the user authenticated by the requests of the tests, who never owns the meals of another user.
+	testUserId = "user-2"
+	// adminToken authenticates testUserId as an administrator.
+	adminToken = "admin-token"
+)
+
+var registerValidators sync.Once
+
+// fakeAuthenticator authenticates any token as testUserId, an administrator with adminToken.
+type fakeAuthenticator struct{}
+
+func (fakeAuthenticator) Verify(ctx context.Context, token string) (*middleware.AuthenticatedUser, error) {
+	return &middleware.AuthenticatedUser{UserId: testUserId, Claims: map[string]interface{}{"admin": token == adminToken}}, nil
+}
+
+// newTestRouter routes the meal, food consumption and food catalog API like main.go, against a mocked database. The mock
+// fails any query which isn't expected, so a test expecting only the ownership check proves that nothing else is read
+// or written.
+func newTestRouter(t *testing.T) (*gin.Engine, sqlmock.Sqlmock) {
+	registerValidators.Do(func() {
+		if err := RegisterValidators(); err != nil {
+			t.Fatal(err)
+		}
+	})
+	sqlDb, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := bun.NewDB(sqlDb, pgdialect.New())
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	mr := repository.NewMealRepository(*db)
+	fcr := repository.NewFoodConsumptionRepository(*db)
+	gs := service.NewGroceryService()
+	gos := service.NewGroceryOutboxService(repository.NewGroceryOutboxRepository(*db), mr, fcr, gs)
+	us := service.NewUnitService(repository.NewFoodPieceWeightRepository(*db))
+	cfs := service.NewCatalogFoodService(repository.NewCatalogFoodRepository(*db))
+	fcs := service.NewFoodConsumptionService(fcr, mr, gs, gos, us, cfs)
+	ms := service.NewMealService(mr, fcs)
+	mc := NewMealController(ms, service.NewMealImportService(mr, fcr, us))
+	fcc := NewFoodConsumptionController(fcs)
+	cfc := NewCatalogFoodController(cfs)
+	am := middleware.NewAuthMiddleware(fakeAuthenticator{})
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.ErrorHandler)
+	mealApi := r.Group("/api/meal", am.Handle)
+	{
+		mealApi.GET("/", mc.FindAllMeals)
+		mealApi.GET(":mealId/", mc.FindMealById)
+		mealApi.PATCH(":mealId/", mc.UpdateMeal)
+		mealApi.DELETE(":mealId/", mc.DeleteMeal)
+
+		mealApi.GET(":mealId/consumption/", fcc.FindAllConsumptionForMeal)
+		mealApi.POST(":mealId/consumption/", fcc.AddFoodConsumption)
+		mealApi.PATCH(":mealId/consumption/:consumptionId/", fcc.UpdateFoodConsumption)
+		mealApi.DELETE(":mealId/consumption/:foodConsumptionId/", fcc.DeleteFoodConsumption)
+	}
+	foodApi := r.Group("/api/food", am.Handle)
+	{
+		foodApi.POST("/", am.RequireAdmin, cfc.CreateCatalogFood)
+		foodApi.PATCH(":foodId/", am.RequireAdmin, cfc.UpdateCatalogFood)
+		foodApi.DELETE(":foodId/", am.RequireAdmin, cfc.DeleteCatalogFood)
+	}
+	return r, mock
+}
+
+func serve(r *gin.Engine, method string, path string, body string) *httptest.ResponseRecorder {
+	return serveWithToken(r, "token", method, path, body)
+}
+
+func serveWithToken(r *gin.Engine, token string, method string, path string, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	request.Header.Set("Authorization", "Bearer "+token)
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, request)
+	return recorder
+}
+
+// expectMealOfAnotherUser expects the meal to be looked up for testUserId, who doesn't own it.
+func expectMealOfAnotherUser(mock sqlmock.Sqlmock, mealId uuid.UUID) {
+	mock.ExpectQuery(`FROM "meal" AS "m" .*WHERE .*m\.id = '` + mealId.String() + `'.* AND \(m\.user_id = '` + testUserId + `'\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+}
+
+func assertNotFound(t *testing.T, recorder *httptest.ResponseRecorder, mock sqlmock.Sqlmock) {
+	t.Helper()
+	assertError(t, recorder, mock, http.StatusNotFound, service.NotFound)
+}
+
+// assertError asserts that the request failed with the status and the kind of error, having run only the expected
+// queries.
+func assertError(t *testing.T, recorder *httptest.ResponseRecorder, mock sqlmock.Sqlmock, status int, kind service.ErrorKind) {
+	t.Helper()
+	if recorder.Code != status {
+		t.Fatalf("expected status %d, got %d: %s", status, recorder.Code, recorder.Body.String())
+	}
+	var response dto.BaseResponse[any]
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Error == nil || response.Error.Code != string(kind) {
+		t.Fatalf("expected a %s error, got %s", kind, recorder.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMealOfAnotherUserIsNotFound(t *testing.T) {
+	mealBody := `{"name": "lunch", "mealType": "lunch", "date": "2026-10-18T12:30:00Z"}`
+	requests := []struct {
+		name   string
+		method string
+		body   string
+	}{
+		{"read", http.MethodGet, ""},
+		{"update", http.MethodPatch, mealBody},
+		{"delete", http.MethodDelete, ""},
+	}
+	for _, request := range requests {
+		t.Run(request.name, func(t *testing.T) {
+			r, mock := newTestRouter(t)
+			mealId := uuid.New()
+			expectMealOfAnotherUser(mock, mealId)
+
+			recorder := serve(r, request.method, "/api/meal/"+mealId.String()+"/", request.body)
+
+			assertNotFound(t, recorder, mock)
+		})
+	}
+}
+
+func TestFindAllMealsReturnsAnArrayUnlessPaged(t *testing.T) {
+	requests := []struct {
+		name  string
+		query string
+		// limitClause ends the query: the page size plus the meal telling whether there is a next page.
+		limitClause string
+		paged       bool
+	}{
+		{"all", "", "", false},
+		{"page", "?limit=1", ` LIMIT 2`, true},
+	}
+	for _, request := range requests {
+		t.Run(request.name, func(t *testing.T) {
+			r, mock := newTestRouter(t)
+			mock.ExpectQuery(`FROM "meal" AS "m" .*WHERE \(m\.user_id = '` + testUserId + `'\).* ORDER BY "m"."date" DESC, "m"."id" DESC` + request.limitClause).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "name"}).AddRow(uuid.New().String(), testUserId, "lunch"))
+
+			recorder := serve(r, http.MethodGet, "/api/meal/"+request.query, "")
+
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+			}
+			var response dto.BaseResponse[json.RawMessage]
+			if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			var meals []dto.MealDto
+			err := json.Unmarshal(response.Body, &meals)
+			if request.paged == (err == nil) {
+				t.Fatalf("expected a page %v, got %s", request.paged, response.Body)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"errors"
+	"food-track-be/middleware"
+	"food-track-be/model/dto"
+	"food-track-be/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"strconv"
+)
+
+type PantryController struct {
+	pantryService *service.PantryService
+}
+
+func NewPantryController(pantryService *service.PantryService) *PantryController {
+	return &PantryController{pantryService: pantryService}
+}
+
+// FindAllPantryFoods godoc
+//	@Summary		Get pantry foods
+//	@Description	get the foods of the grocery-be pantry with the percentage still available, ordered by name
+//	@Tags			pantry
+//	@Produce		json
+//	@Param			pantryId	query		string	true	"Pantry of grocery-be"
+//	@Param			all			query		bool	false	"Include the foods already used up (default false)"
+//	@Success		200			{object}	dto.BaseResponse[[]dto.PantryFoodDto]
+//	@Router			/pantry/ [get]
+func (s *PantryController) FindAllPantryFoods(c *gin.Context) {
+	pantryId := c.Query("pantryId")
+	if pantryId == "" {
+		abortWithValidationError(c, errors.New("pantryId is required"))
+		return
+	}
+	all, err := strconv.ParseBool(c.DefaultQuery("all", "false"))
+	if err != nil {
+		abortWithValidationError(c, err)
+		return
+	}
+	token := middleware.GetToken(c)
+	pantryFoodsDto, err := s.pantryService.FindAllFoods(pantryId, all, token)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(200, dto.BaseResponse[[]dto.PantryFoodDto]{
+		Body: pantryFoodsDto,
+	})
+}
+
+// FindAllPantryTransactions godoc
+//	@Summary		Get pantry food transactions
+//	@Description	get the available transactions of the food with price per unit and days until expiry, the ones expiring first at the top
+//	@Tags			pantry
+//	@Produce		json
+//	@Param			foodId	path		string	true	"Food ID of grocery-be"
+//	@Success		200		{object}	dto.BaseResponse[[]dto.PantryTransactionDto]
+//	@Router			/pantry/{foodId}/transaction/ [get]
+func (s *PantryController) FindAllPantryTransactions(c *gin.Context) {
+	foodId, err := uuid.Parse(c.Param("foodId"))
+	if err != nil {
+		abortWithValidationError(c, err)
+		return
+	}
+	token := middleware.GetToken(c)
+	location := middleware.GetLocation(c)
+	pantryTransactionsDto, err := s.pantryService.FindAllTransactions(foodId, location, token)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(200, dto.BaseResponse[[]dto.PantryTransactionDto]{
+		Body: pantryTransactionsDto,
+	})
+}
@@ -0,0 +1,187 @@
+package controller
+
+import (
+	"food-track-be/middleware"
+	"food-track-be/model/dto"
+	"food-track-be/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type RecipeController struct {
+	recipeService *service.RecipeService
+}
+
+func NewRecipeController(recipeService *service.RecipeService) *RecipeController {
+	return &RecipeController{recipeService: recipeService}
+}
+
+// FindAllRecipes godoc
+//	@Summary		Get all recipes
+//	@Description	get all the recipes of the user
+//	@Tags			recipe
+//	@Produce		json
+//	@Success		200	{object}	dto.BaseResponse[[]dto.RecipeDto]
+//	@Router			/recipe/ [get]
+func (s *RecipeController) FindAllRecipes(c *gin.Context) {
+	userId := middleware.GetUserId(c)
+	recipeDtos, err := s.recipeService.FindAll(userId)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	response := dto.BaseResponse[[]dto.RecipeDto]{
+		Body: recipeDtos,
+	}
+	c.JSON(200, response)
+}
+
+// FindRecipeById godoc
+//	@Summary		Get recipe
+//	@Description	get the recipe with the provided id
+//	@Tags			recipe
+//	@Produce		json
+//	@Param			recipeId	path		string	true	"Recipe ID"
+//	@Success		200			{object}	dto.BaseResponse[dto.RecipeDto]
+//	@Router			/recipe/{recipeId}/ [get]
+func (s *RecipeController) FindRecipeById(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("recipeId"))
+	if err != nil {
+		abortWithValidationError(c, err)
+		return
+	}
+	userId := middleware.GetUserId(c)
+	recipeDto, err := s.recipeService.FindById(id, userId)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	response := dto.BaseResponse[dto.RecipeDto]{
+		Body: recipeDto,
+	}
+	c.JSON(200, response)
+}
+
+// CreateRecipe godoc
+//	@Summary		Create recipe
+//	@Description	create a new recipe with its ingredients
+//	@Tags			recipe
+//	@Accept			json
+//	@Produce		json
+//	@Param			recipeDto	body		dto.RecipeDto	true	"Recipe to create"
+//	@Success		200			{object}	dto.BaseResponse[dto.RecipeDto]
+//	@Router			/recipe/ [post]
+func (s *RecipeController) CreateRecipe(c *gin.Context) {
+	var recipeDto dto.RecipeDto
+	err := c.ShouldBindJSON(&recipeDto)
+	if err != nil {
+		abortWithValidationError(c, err)
+		return
+	}
+	userId := middleware.GetUserId(c)
+	recipeDto.UserId = userId
+	recipeDto, err = s.recipeService.Create(recipeDto)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	response := dto.BaseResponse[dto.RecipeDto]{
+		Body: recipeDto,
+	}
+	c.JSON(200, response)
+}
+
+// UpdateRecipe godoc
+//	@Summary		Update recipe
+//	@Description	update the recipe with the provided id, replacing all its ingredients
+//	@Tags			recipe
+//	@Accept			json
+//	@Produce		json
+//	@Param			recipeId	path		string			true	"Recipe ID"
+//	@Param			recipeDto	body		dto.RecipeDto	true	"Recipe to update"
+//	@Success		200			{object}	dto.BaseResponse[dto.RecipeDto]
+//	@Router			/recipe/{recipeId}/ [patch]
+func (s *RecipeController) UpdateRecipe(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("recipeId"))
+	if err != nil {
+		abortWithValidationError(c, err)
+		return
+	}
+	var recipeDto dto.RecipeDto
+	err = c.ShouldBindJSON(&recipeDto)
+	if err != nil {
+		abortWithValidationError(c, err)
+		return
+	}
+	userId := middleware.GetUserId(c)
+	recipeDto.ID = id
+	recipeDto, err = s.recipeService.Update(recipeDto, userId)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	response := dto.BaseResponse[dto.RecipeDto]{
+		Body: recipeDto,
+	}
+	c.JSON(200, response)
+}
+
+// DeleteRecipe godoc
+//	@Summary		Delete recipe
+//	@Description	delete the recipe with the provided id
+//	@Tags			recipe
+//	@Produce		json
+//	@Param			recipeId	path		string	true	"Recipe ID"
+//	@Success		200			{object}	dto.BaseResponse[bool]
+//	@Router			/recipe/{recipeId}/ [delete]
+func (s *RecipeController) DeleteRecipe(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("recipeId"))
+	if err != nil {
+		abortWithValidationError(c, err)
+		return
+	}
+	userId := middleware.GetUserId(c)
+	err = s.recipeService.Delete(id, userId)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	response := dto.BaseResponse[bool]{
+		Body: err == nil,
+	}
+	c.JSON(200, response)
+}
+
+// ApplyRecipe godoc
+//	@Summary		Apply recipe to a meal
+//	@Description	add the ingredients of the recipe to the meal as food consumptions, scaled to the servings eaten
+//	@Tags			recipe
+//	@Accept			json
+//	@Produce		json
+//	@Param			recipeId		path		string				true	"Recipe ID"
+//	@Param			applyRecipeDto	body		dto.ApplyRecipeDto	true	"Meal and servings eaten"
+//	@Success		200				{object}	dto.BaseResponse[[]dto.FoodConsumptionDto]
+//	@Router			/recipe/{recipeId}/apply/ [post]
+func (s *RecipeController) ApplyRecipe(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("recipeId"))
+	if err != nil {
+		abortWithValidationError(c, err)
+		return
+	}
+	var applyRecipeDto dto.ApplyRecipeDto
+	err = c.ShouldBindJSON(&applyRecipeDto)
+	if err != nil {
+		abortWithValidationError(c, err)
+		return
+	}
+	token := middleware.GetToken(c)
+	userId := middleware.GetUserId(c)
+	foodConsumptionDtos, err := s.recipeService.ApplyToMeal(id, applyRecipeDto, userId, token)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(200, dto.BaseResponse[[]*dto.FoodConsumptionDto]{
+		Body: foodConsumptionDtos,
+	})
+}
@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"food-track-be/middleware"
+	"food-track-be/model/dto"
+	"food-track-be/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TrashController struct {
+	trashService *service.TrashService
+}
+
+func NewTrashController(trashService *service.TrashService) *TrashController {
+	return &TrashController{trashService: trashService}
+}
+
+// FindTrash godoc
+//	@Summary		Get the trash
+//	@Description	get the meals and the food consumptions of the user in the trash, which are purged at the end of the retention period
+//	@Tags			trash
+//	@Produce		json
+//	@Success		200	{object}	dto.BaseResponse[dto.TrashDto]
+//	@Router			/meal/trash/ [get]
+func (s *TrashController) FindTrash(c *gin.Context) {
+	userId := middleware.GetUserId(c)
+	trashDto, err := s.trashService.FindAll(userId)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(200, dto.BaseResponse[dto.TrashDto]{
+		Body: trashDto,
+	})
+}
+
+// RestoreMeal godoc
+//	@Summary		Restore meal
+//	@Description	move the meal with the provided id out of the trash, together with the consumptions deleted with it
+//	@Tags			trash
+//	@Produce		json
+//	@Param			mealId	path		string	true	"Meal ID"
+//	@Success		200		{object}	dto.BaseResponse[dto.MealDto]
+//	@Router			/meal/trash/{mealId}/restore/ [post]
+func (s *TrashController) RestoreMeal(c *gin.Context) {
+	mealId, err := uuid.Parse(c.Param("mealId"))
+	if err != nil {
+		abortWithValidationError(c, err)
+		return
+	}
+	token := middleware.GetToken(c)
+	userId := middleware.GetUserId(c)
+	mealDto, err := s.trashService.RestoreMeal(mealId, userId, token)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(200, dto.BaseResponse[dto.MealDto]{
+		Body: mealDto,
+	})
+}
+
+// RestoreFoodConsumption godoc
+//	@Summary		Restore consumption
+//	@Description	move the consumption of the meal out of the trash, removing again the quantity used from the pantry
+//	@Tags			trash
+//	@Produce		json
+//	@Param			mealId				path		string	true	"Meal ID"
+//	@Param			foodConsumptionId	path		string	true	"Food consumption ID"
+//	@Success		200					{object}	dto.BaseResponse[dto.FoodConsumptionDto]
+//	@Router			/meal/trash/{mealId}/consumption/{foodConsumptionId}/restore/ [post]
+func (s *TrashController) RestoreFoodConsumption(c *gin.Context) {
+	mealId, err := uuid.Parse(c.Param("mealId"))
+	if err != nil {
+		abortWithValidationError(c, err)
+		return
+	}
+	foodConsumptionId, err := uuid.Parse(c.Param("foodConsumptionId"))
+	if err != nil {
+		abortWithValidationError(c, err)
+		return
+	}
+	token := middleware.GetToken(c)
+	userId := middleware.GetUserId(c)
+	foodConsumptionDto, err := s.trashService.RestoreFoodConsumption(mealId, foodConsumptionId, userId, token)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(200, dto.BaseResponse[dto.FoodConsumptionDto]{
+		Body: foodConsumptionDto,
+	})
+}
@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"food-track-be/middleware"
+	"food-track-be/model/dto"
+	"food-track-be/service"
+	"github.com/gin-gonic/gin"
+)
+
+type UnitController struct {
+	unitService *service.UnitService
+}
+
+func NewUnitController(unitService *service.UnitService) *UnitController {
+	return &UnitController{unitService: unitService}
+}
+
+// FindAllUnits godoc
+//	@Summary		Get units
+//	@Description	get the known units with the standard unit, g or ml, their quantities are converted to
+//	@Tags			unit
+//	@Produce		json
+//	@Success		200	{object}	dto.BaseResponse[[]dto.UnitDto]
+//	@Router			/unit/ [get]
+func (s *UnitController) FindAllUnits(c *gin.Context) {
+	response := dto.BaseResponse[[]dto.UnitDto]{
+		Body: s.unitService.FindAllUnits(),
+	}
+	c.JSON(200, response)
+}
+
+// FindAllPieceWeights godoc
+//	@Summary		Get piece weights
+//	@Description	get the weight in grams of a piece of the foods, used to convert the quantities in pcs
+//	@Tags			unit
+//	@Produce		json
+//	@Success		200	{object}	dto.BaseResponse[[]dto.FoodPieceWeightDto]
+//	@Router			/unit/piece-weight/ [get]
+func (s *UnitController) FindAllPieceWeights(c *gin.Context) {
+	userId := middleware.GetUserId(c)
+	foodPieceWeightsDto, err := s.unitService.FindAllPieceWeights(userId)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	response := dto.BaseResponse[[]dto.FoodPieceWeightDto]{
+		Body: foodPieceWeightsDto,
+	}
+	c.JSON(200, response)
+}
+
+// SavePieceWeight godoc
+//	@Summary		Save piece weight
+//	@Description	create or overwrite the weight in grams of a piece of the food; the food name is case insensitive
+//	@Tags			unit
+//	@Accept			json
+//	@Produce		json
+//	@Param			foodPieceWeightDto	body		dto.FoodPieceWeightDto	true	"Piece weight to save"
+//	@Success		200					{object}	dto.BaseResponse[dto.FoodPieceWeightDto]
+//	@Router			/unit/piece-weight/ [put]
+func (s *UnitController) SavePieceWeight(c *gin.Context) {
+	var foodPieceWeightDto dto.FoodPieceWeightDto
+	err := c.ShouldBindJSON(&foodPieceWeightDto)
+	if err != nil {
+		abortWithValidationError(c, err)
+		return
+	}
+	userId := middleware.GetUserId(c)
+	foodPieceWeightDto, err = s.unitService.SavePieceWeight(foodPieceWeightDto, userId)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	response := dto.BaseResponse[dto.FoodPieceWeightDto]{
+		Body: foodPieceWeightDto,
+	}
+	c.JSON(200, response)
+}
+
+// DeletePieceWeight godoc
+//	@Summary		Delete piece weight
+//	@Description	delete the weight of a piece of the food
+//	@Tags			unit
+//	@Produce		json
+//	@Param			foodName	path		string	true	"Food name"
+//	@Success		200			{object}	dto.BaseResponse[bool]
+//	@Router			/unit/piece-weight/{foodName}/ [delete]
+func (s *UnitController) DeletePieceWeight(c *gin.Context) {
+	userId := middleware.GetUserId(c)
+	err := s.unitService.DeletePieceWeight(c.Param("foodName"), userId)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	response := dto.BaseResponse[bool]{
+		Body: err == nil,
+	}
+	c.JSON(200, response)
+}
@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"food-track-be/middleware"
+	"food-track-be/model/dto"
+	"food-track-be/service"
+	"github.com/gin-gonic/gin"
+)
+
+type UserProfileController struct {
+	userProfileService *service.UserProfileService
+}
+
+func NewUserProfileController(userProfileService *service.UserProfileService) *UserProfileController {
+	return &UserProfileController{userProfileService: userProfileService}
+}
+
+// FindUserProfile godoc
+//	@Summary		Get profile
+//	@Description	get the profile of the user, with the timezone used to interpret dates when the request doesn't provide one
+//	@Tags			profile
+//	@Produce		json
+//	@Success		200	{object}	dto.BaseResponse[dto.UserProfileDto]
+//	@Router			/profile/ [get]
+func (s *UserProfileController) FindUserProfile(c *gin.Context) {
+	userId := middleware.GetUserId(c)
+	userProfileDto, err := s.userProfileService.FindByUserId(userId)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	response := dto.BaseResponse[dto.UserProfileDto]{
+		Body: userProfileDto,
+	}
+	c.JSON(200, response)
+}
+
+// UpdateUserProfile godoc
+//	@Summary		Update profile
+//	@Description	update the profile of the user; the timezone must be an IANA timezone, e.g. Europe/Rome
+//	@Tags			profile
+//	@Accept			json
+//	@Produce		json
+//	@Param			userProfileDto	body		dto.UserProfileDto	true	"Profile to save"
+//	@Success		200				{object}	dto.BaseResponse[dto.UserProfileDto]
+//	@Router			/profile/ [put]
+func (s *UserProfileController) UpdateUserProfile(c *gin.Context) {
+	var userProfileDto dto.UserProfileDto
+	err := c.ShouldBindJSON(&userProfileDto)
+	if err != nil {
+		abortWithValidationError(c, err)
+		return
+	}
+	userId := middleware.GetUserId(c)
+	userProfileDto, err = s.userProfileService.Update(userProfileDto, userId)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	response := dto.BaseResponse[dto.UserProfileDto]{
+		Body: userProfileDto,
+	}
+	c.JSON(200, response)
+}
@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"food-track-be/model"
+	"food-track-be/model/dto"
+	"food-track-be/service"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"reflect"
+	"strings"
+)
+
+// RegisterValidators adds to the gin validator the custom validations used by the binding tags of the DTOs, and makes
+// it report the JSON name of the invalid fields.
+func RegisterValidators() error {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return errors.New("unexpected gin validator engine")
+	}
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	validations := map[string]validator.Func{
+		"mealtype": func(fl validator.FieldLevel) bool {
+			return model.MealType(fl.Field().String()).IsValid()
+		},
+		"mealstatus": func(fl validator.FieldLevel) bool {
+			return model.MealStatus(fl.Field().String()).IsValid()
+		},
+		"unit": func(fl validator.FieldLevel) bool {
+			return model.Unit(fl.Field().String()).IsValid()
+		},
+	}
+	for tag, fn := range validations {
+		if err := v.RegisterValidation(tag, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newValidationError converts the errors of the request binding, listing the invalid fields when the body doesn't
+// satisfy its binding tags.
+func newValidationError(err error) error {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return service.NewValidationError(err.Error())
+	}
+	fields := make([]dto.FieldErrorDto, 0, len(validationErrors))
+	for _, fieldError := range validationErrors {
+		fields = append(fields, dto.FieldErrorDto{Field: fieldError.Field(), Message: fieldErrorMessage(fieldError)})
+	}
+	return &service.DomainError{Kind: service.Validation, Message: "invalid request body", Fields: fields}
+}
+
+func fieldErrorMessage(fieldError validator.FieldError) string {
+	switch fieldError.Tag() {
+	case "required":
+		return "is required"
+	case "max":
+		return fmt.Sprintf("must be at most %s characters", fieldError.Param())
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", fieldError.Param())
+	case "gte":
+		return fmt.Sprintf("must be greater than or equal to %s", fieldError.Param())
+	case "mealtype":
+		return "must be one of breakfast, lunch, dinner, others"
+	case "mealstatus":
+		return "must be planned or eaten"
+	case "unit":
+		units := make([]string, 0, len(model.Units))
+		for _, unit := range model.Units {
+			units = append(units, string(unit))
+		}
+		return "must be one of " + strings.Join(units, ", ")
+	default:
+		return fmt.Sprintf("failed the %s validation", fieldError.Tag())
+	}
+}
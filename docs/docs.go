@@ -1,4 +1,4 @@
-// Code generated by swaggo/swag. DO NOT EDIT
+// Package docs Code generated by swaggo/swag. DO NOT EDIT
 package docs
 
 import "github.com/swaggo/swag"
@@ -18,27 +18,28 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/": {
+        "/food/": {
             "get": {
-                "description": "get all the meals which satisfies the query parameters (startRange, endRange) or all meals if no query parameters are provided",
+                "description": "search the food catalog by name, alias or barcode; foods named exactly as the query come first",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "meal"
+                    "food"
                 ],
-                "summary": "Get all meals",
+                "summary": "Search foods",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Start date of the range",
-                        "name": "startRange",
-                        "in": "query"
+                        "description": "Name, alias or barcode to search",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "End date of the range",
-                        "name": "endRange",
+                        "type": "integer",
+                        "description": "Number of foods to return, between 1 and 100 (default 20)",
+                        "name": "limit",
                         "in": "query"
                     }
                 ],
@@ -46,13 +47,13 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.BaseResponse-array_dto_MealDto"
+                            "$ref": "#/definitions/dto.BaseResponse-array_dto_CatalogFoodDto"
                         }
                     }
                 }
             },
             "post": {
-                "description": "create a new meal",
+                "description": "add a food to the catalog, with its nutrients per 100 g or ml; administrators only",
                 "consumes": [
                     "application/json"
                 ],
@@ -60,17 +61,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "meal"
+                    "food"
                 ],
-                "summary": "Create meal",
+                "summary": "Create food",
                 "parameters": [
                     {
-                        "description": "Meal to create",
-                        "name": "mealDto",
+                        "description": "Food to create",
+                        "name": "catalogFoodDto",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.MealDto"
+                            "$ref": "#/definitions/dto.CatalogFoodDto"
                         }
                     }
                 ],
@@ -78,33 +79,41 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.BaseResponse-dto_MealDto"
+                            "$ref": "#/definitions/dto.BaseResponse-dto_CatalogFoodDto"
                         }
                     }
                 }
             }
         },
-        "/statistics/": {
+        "/food/barcode/{code}/": {
             "get": {
-                "description": "get the meal statistics for the provided date range (default is the past week)",
+                "description": "find the food with the barcode in the pantry and in the food catalog, with its available transactions and a consumption draft ready to be added to a meal",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "meal"
+                    "food-consumption"
                 ],
-                "summary": "Get meal statistics",
+                "summary": "Lookup barcode",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Start date of the range",
-                        "name": "startRange",
-                        "in": "query"
+                        "description": "Barcode",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "string",
-                        "description": "End date of the range",
-                        "name": "endRange",
+                        "description": "Pantry of grocery-be to search",
+                        "name": "pantryId",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Quantity of the draft, default 100 g or ml or 1 of other units",
+                        "name": "quantity",
                         "in": "query"
                     }
                 ],
@@ -112,27 +121,27 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.BaseResponse-dto_MealStatisticsDto"
+                            "$ref": "#/definitions/dto.BaseResponse-dto_BarcodeLookupDto"
                         }
                     }
                 }
             }
         },
-        "/{mealId}/": {
+        "/food/{foodId}/": {
             "get": {
-                "description": "get the meal with the provided id",
+                "description": "get the food of the catalog with the provided id",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "meal"
+                    "food"
                 ],
-                "summary": "Get meal",
+                "summary": "Get food",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Meal ID",
-                        "name": "mealId",
+                        "description": "Food ID",
+                        "name": "foodId",
                         "in": "path",
                         "required": true
                     }
@@ -141,28 +150,25 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.BaseResponse-dto_MealDto"
+                            "$ref": "#/definitions/dto.BaseResponse-dto_CatalogFoodDto"
                         }
                     }
                 }
             },
             "delete": {
-                "description": "delete the meal with the provided id",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "delete the food of the catalog with the provided id; administrators only",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "meal"
+                    "food"
                 ],
-                "summary": "Delete meal",
+                "summary": "Delete food",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Meal ID",
-                        "name": "mealId",
+                        "description": "Food ID",
+                        "name": "foodId",
                         "in": "path",
                         "required": true
                     }
@@ -177,7 +183,7 @@ const docTemplate = `{
                 }
             },
             "patch": {
-                "description": "update the meal with the provided id",
+                "description": "update the food of the catalog with the provided id; administrators only",
                 "consumes": [
                     "application/json"
                 ],
@@ -185,24 +191,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "meal"
+                    "food"
                 ],
-                "summary": "Update meal",
+                "summary": "Update food",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Meal ID",
-                        "name": "mealId",
+                        "description": "Food ID",
+                        "name": "foodId",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Meal to create",
-                        "name": "mealDto",
+                        "description": "Food to update",
+                        "name": "catalogFoodDto",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.MealDto"
+                            "$ref": "#/definitions/dto.CatalogFoodDto"
                         }
                     }
                 ],
@@ -210,42 +216,33 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.BaseResponse-dto_MealDto"
+                            "$ref": "#/definitions/dto.BaseResponse-dto_CatalogFoodDto"
                         }
                     }
                 }
             }
         },
-        "/{mealId}/consumption/": {
+        "/goal/": {
             "get": {
-                "description": "find all the consumption for the meal by mealId",
+                "description": "get the daily nutrition goal of the user",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "food-consumption"
-                ],
-                "summary": "Get all consumption for the meal",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Meal ID",
-                        "name": "mealId",
-                        "in": "path",
-                        "required": true
-                    }
+                    "goal"
                 ],
+                "summary": "Get goal",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.BaseResponse-array_dto_FoodConsumptionDto"
+                            "$ref": "#/definitions/dto.BaseResponse-dto_GoalDto"
                         }
                     }
                 }
             },
             "post": {
-                "description": "add consumption for the meal by mealId",
+                "description": "create the daily nutrition goal of the user",
                 "consumes": [
                     "application/json"
                 ],
@@ -253,24 +250,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "food-consumption"
+                    "goal"
                 ],
-                "summary": "Add consumption for the meal",
+                "summary": "Create goal",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Meal ID",
-                        "name": "mealId",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Food Consumption",
-                        "name": "foodConsumptionDto",
+                        "description": "Goal to create",
+                        "name": "goalDto",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.FoodConsumptionDto"
+                            "$ref": "#/definitions/dto.GoalDto"
                         }
                     }
                 ],
@@ -278,13 +268,31 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.BaseResponse-dto_FoodConsumptionDto"
+                            "$ref": "#/definitions/dto.BaseResponse-dto_GoalDto"
                         }
                     }
                 }
             },
             "delete": {
-                "description": "delete consumption for the meal by mealId",
+                "description": "delete the daily nutrition goal of the user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "goal"
+                ],
+                "summary": "Delete goal",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BaseResponse-bool"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "update the daily nutrition goal of the user",
                 "consumes": [
                     "application/json"
                 ],
@@ -292,36 +300,125 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "food-consumption"
+                    "goal"
                 ],
-                "summary": "Delete consumption for the meal",
+                "summary": "Update goal",
+                "parameters": [
+                    {
+                        "description": "Goal to update",
+                        "name": "goalDto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.GoalDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BaseResponse-dto_GoalDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/meal/": {
+            "get": {
+                "description": "get all the meals which satisfy the query parameters, sorted by date and id. When limit or cursor is given, the body is a page of them: {\"items\": [...], \"nextCursor\": \"...\"}",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "meal"
+                ],
+                "summary": "Get all meals",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Meal ID",
-                        "name": "mealId",
-                        "in": "path",
-                        "required": true
+                        "description": "Start date of the range",
+                        "name": "startRange",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Food consumption ID",
-                        "name": "foodConsumptionId",
-                        "in": "path",
-                        "required": true
+                        "description": "End date of the range",
+                        "name": "endRange",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Meal status (planned or eaten)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Meal type",
+                        "name": "mealType",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Substring of the meal name",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum kcal of the meal",
+                        "name": "minKcal",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maximum kcal of the meal",
+                        "name": "maxKcal",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum cost of the meal",
+                        "name": "minCost",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maximum cost of the meal",
+                        "name": "maxCost",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort order by date (asc or desc, default desc)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page, as returned by the previous page",
+                        "name": "cursor",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.BaseResponse-bool"
+                            "$ref": "#/definitions/dto.BaseResponse-array_dto_MealDto"
                         }
                     }
                 }
             },
-            "patch": {
-                "description": "update consumption for the meal by mealId",
+            "post": {
+                "description": "create a new meal",
                 "consumes": [
                     "application/json"
                 ],
@@ -329,24 +426,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "food-consumption"
+                    "meal"
                 ],
-                "summary": "Update consumption for the meal",
+                "summary": "Create meal",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Meal ID",
-                        "name": "mealId",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Food Consumption",
-                        "name": "foodConsumptionDto",
+                        "description": "Meal to create",
+                        "name": "mealDto",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.FoodConsumptionDto"
+                            "$ref": "#/definitions/dto.MealDto"
                         }
                     }
                 ],
@@ -354,103 +444,2175 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.BaseResponse-dto_FoodConsumptionDto"
+                            "$ref": "#/definitions/dto.BaseResponse-dto_MealDto"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "dto.AvgKcalPerMealTypeDto": {
-            "type": "object",
-            "properties": {
-                "avgKcal": {
-                    "type": "number"
-                },
-                "mealType": {
-                    "type": "string"
-                }
-            }
         },
-        "dto.BaseResponse-array_dto_FoodConsumptionDto": {
-            "type": "object",
-            "properties": {
-                "body": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/dto.FoodConsumptionDto"
+        "/meal/export/": {
+            "get": {
+                "description": "stream the meals in the date range (default is the whole history) with their food consumptions, as JSON array or as CSV with a row for every food consumption",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "meal"
+                ],
+                "summary": "Export meals",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Export format (json or csv, default json)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date of the range",
+                        "name": "startRange",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date of the range",
+                        "name": "endRange",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.MealExportDto"
+                            }
+                        }
                     }
-                },
-                "errorMessage": {
-                    "type": "string"
                 }
             }
         },
-        "dto.BaseResponse-array_dto_MealDto": {
-            "type": "object",
-            "properties": {
-                "body": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/dto.MealDto"
+        "/meal/import/": {
+            "post": {
+                "description": "import meals and food consumptions from a file with the same shape of the export, nothing is imported if any row is invalid",
+                "consumes": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "meal"
+                ],
+                "summary": "Import meals",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Import format (json or csv, default json)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only validate the rows, without importing them",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Meals to import",
+                        "name": "meals",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.MealExportDto"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BaseResponse-dto_MealImportResultDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/meal/plan/": {
+            "post": {
+                "description": "plan the week which starts at the provided date (default is next monday) copying the meals eaten in the previous week",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "meal"
+                ],
+                "summary": "Generate meal plan",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First day of the week to plan",
+                        "name": "startDate",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BaseResponse-array_dto_MealDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/meal/plan/report/": {
+            "get": {
+                "description": "compare the kcal and cost planned in the date range (default is the last 7 days) with the ones actually eaten",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "meal"
+                ],
+                "summary": "Get meal plan report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start date of the range",
+                        "name": "startRange",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date of the range",
+                        "name": "endRange",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BaseResponse-dto_PlanReportDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/meal/progress/": {
+            "get": {
+                "description": "get the progress of the provided day (default is today) against the user's daily goal",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "goal"
+                ],
+                "summary": "Get daily progress",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Day to check",
+                        "name": "date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BaseResponse-dto_GoalProgressDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/meal/statistics/": {
+            "get": {
+                "description": "get the meal statistics for the provided date range (default is the past week)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "meal"
+                ],
+                "summary": "Get meal statistics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start date of the range",
+                        "name": "startRange",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date of the range",
+                        "name": "endRange",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BaseResponse-dto_MealStatisticsDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/meal/statistics/timeseries/": {
+            "get": {
+                "description": "get the kcal, cost and number of the meals eaten in the date range (default is the past 30 days), in buckets of a day, an ISO week or a month, in total and per meal type",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "meal"
+                ],
+                "summary": "Get meal statistics time series",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start date of the range",
+                        "name": "startRange",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date of the range",
+                        "name": "endRange",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Size of the buckets (day, week or month, default day)",
+                        "name": "granularity",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BaseResponse-dto_MealTimeseriesDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/meal/trash/": {
+            "get": {
+                "description": "get the meals and the food consumptions of the user in the trash, which are purged at the end of the retention period",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trash"
+                ],
+                "summary": "Get the trash",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BaseResponse-dto_TrashDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/meal/trash/{mealId}/consumption/{foodConsumptionId}/restore/": {
+            "post": {
+                "description": "move the consumption of the meal out of the trash, removing again the quantity used from the pantry",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trash"
+                ],
+                "summary": "Restore consumption",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Meal ID",
+                        "name": "mealId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Food consumption ID",
+                        "name": "foodConsumptionId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BaseResponse-dto_FoodConsumptionDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/meal/trash/{mealId}/restore/": {
+            "post": {
+                "description": "move the meal with the provided id out of the trash, together with the consumptions deleted with it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trash"
+                ],
+                "summary": "Restore meal",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Meal ID",
+                        "name": "mealId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BaseResponse-dto_MealDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/meal/{mealId}/": {
+            "get": {
+                "description": "get the meal with the provided id",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "meal"
+                ],
+                "summary": "Get meal",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Meal ID",
+                        "name": "mealId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BaseResponse-dto_MealDto"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "move the meal with the provided id to the trash together with its consumptions, giving the quantities used back to the pantry",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "meal"
+                ],
+                "summary": "Delete meal",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Meal ID",
+                        "name": "mealId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Give the quantities taken from the pantry back to it (default true)",
+                        "name": "restoreStock",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BaseResponse-bool"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "update the meal with the provided id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "meal"
+                ],
+                "summary": "Update meal",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Meal ID",
+                        "name": "mealId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Meal to create",
+                        "name": "mealDto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.MealDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BaseResponse-dto_MealDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/meal/{mealId}/consumption/": {
+            "get": {
+                "description": "find all the consumption for the meal by mealId",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "food-consumption"
+                ],
+                "summary": "Get all consumption for the meal",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Meal ID",
+                        "name": "mealId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BaseResponse-array_dto_FoodConsumptionDto"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "add consumption for the meal by mealId",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "food-consumption"
+                ],
+                "summary": "Add consumption for the meal",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Meal ID",
+                        "name": "mealId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Food Consumption",
+                        "name": "foodConsumptionDto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.FoodConsumptionDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BaseResponse-dto_FoodConsumptionDto"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "update consumption for the meal by mealId",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "food-consumption"
+                ],
+                "summary": "Update consumption for the meal",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Meal ID",
+                        "name": "mealId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Food Consumption",
+                        "name": "foodConsumptionDto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.FoodConsumptionDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BaseResponse-dto_FoodConsumptionDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/meal/{mealId}/consumption/{foodConsumptionId}/": {
+            "delete": {
+                "description": "move the consumption of the meal to the trash",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "food-consumption"
+                ],
+                "summary": "Delete consumption for the meal",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Meal ID",
+                        "name": "mealId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Food consumption ID",
+                        "name": "foodConsumptionId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BaseResponse-bool"
+                        }
+                    }
+                }
+            }
+        },
+        "/meal/{mealId}/duplicate/": {
+            "post": {
+                "description": "copy the meal, with its consumptions, to the provided dates keeping its time of the day; the quantities used by the copies are removed from the pantry at the current price",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "meal"
+                ],
+                "summary": "Duplicate meal",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Meal ID",
+                        "name": "mealId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Target dates",
+                        "name": "duplicateMealDto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.DuplicateMealDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BaseResponse-array_dto_MealDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/meal/{mealId}/eaten/": {
+            "post": {
+                "description": "turn the planned meal into an eaten one, removing its food consumptions from the pantry",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "meal"
+                ],
+                "summary": "Mark meal as eaten",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Meal ID",
+                        "name": "mealId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BaseResponse-dto_MealDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/pantry/": {
+            "get": {
+                "description": "get the foods of the grocery-be pantry with the percentage still available, ordered by name",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pantry"
+                ],
+                "summary": "Get pantry foods",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Pantry of grocery-be",
+                        "name": "pantryId",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include the foods already used up (default false)",
+                        "name": "all",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BaseResponse-array_dto_PantryFoodDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/pantry/{foodId}/transaction/": {
+            "get": {
+                "description": "get the available transactions of the food with price per unit and days until expiry, the ones expiring first at the top",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pantry"
+                ],
+                "summary": "Get pantry food transactions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Food ID of grocery-be",
+                        "name": "foodId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BaseResponse-array_dto_PantryTransactionDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/": {
+            "get": {
+                "description": "get the profile of the user, with the timezone used to interpret dates when the request doesn't provide one",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Get profile",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BaseResponse-dto_UserProfileDto"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "update the profile of the user; the timezone must be an IANA timezone, e.g. Europe/Rome",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Update profile",
+                "parameters": [
+                    {
+                        "description": "Profile to save",
+                        "name": "userProfileDto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserProfileDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BaseResponse-dto_UserProfileDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipe/": {
+            "get": {
+                "description": "get all the recipes of the user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recipe"
+                ],
+                "summary": "Get all recipes",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BaseResponse-array_dto_RecipeDto"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "create a new recipe with its ingredients",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recipe"
+                ],
+                "summary": "Create recipe",
+                "parameters": [
+                    {
+                        "description": "Recipe to create",
+                        "name": "recipeDto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RecipeDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BaseResponse-dto_RecipeDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipe/{recipeId}/": {
+            "get": {
+                "description": "get the recipe with the provided id",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recipe"
+                ],
+                "summary": "Get recipe",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipe ID",
+                        "name": "recipeId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BaseResponse-dto_RecipeDto"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "delete the recipe with the provided id",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recipe"
+                ],
+                "summary": "Delete recipe",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipe ID",
+                        "name": "recipeId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BaseResponse-bool"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "update the recipe with the provided id, replacing all its ingredients",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recipe"
+                ],
+                "summary": "Update recipe",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipe ID",
+                        "name": "recipeId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Recipe to update",
+                        "name": "recipeDto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RecipeDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BaseResponse-dto_RecipeDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipe/{recipeId}/apply/": {
+            "post": {
+                "description": "add the ingredients of the recipe to the meal as food consumptions, scaled to the servings eaten",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recipe"
+                ],
+                "summary": "Apply recipe to a meal",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipe ID",
+                        "name": "recipeId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Meal and servings eaten",
+                        "name": "applyRecipeDto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApplyRecipeDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BaseResponse-array_dto_FoodConsumptionDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/unit/": {
+            "get": {
+                "description": "get the known units with the standard unit, g or ml, their quantities are converted to",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "unit"
+                ],
+                "summary": "Get units",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BaseResponse-array_dto_UnitDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/unit/piece-weight/": {
+            "get": {
+                "description": "get the weight in grams of a piece of the foods, used to convert the quantities in pcs",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "unit"
+                ],
+                "summary": "Get piece weights",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BaseResponse-array_dto_FoodPieceWeightDto"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "create or overwrite the weight in grams of a piece of the food; the food name is case insensitive",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "unit"
+                ],
+                "summary": "Save piece weight",
+                "parameters": [
+                    {
+                        "description": "Piece weight to save",
+                        "name": "foodPieceWeightDto",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.FoodPieceWeightDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BaseResponse-dto_FoodPieceWeightDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/unit/piece-weight/{foodName}/": {
+            "delete": {
+                "description": "delete the weight of a piece of the food",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "unit"
+                ],
+                "summary": "Delete piece weight",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Food name",
+                        "name": "foodName",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BaseResponse-bool"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "dto.ApplyRecipeDto": {
+            "type": "object",
+            "properties": {
+                "mealId": {
+                    "type": "string"
+                },
+                "servings": {
+                    "type": "number"
+                }
+            }
+        },
+        "dto.AvgKcalPerMealTypeDto": {
+            "type": "object",
+            "properties": {
+                "avgKcal": {
+                    "type": "number"
+                },
+                "mealType": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.BarcodeLookupDto": {
+            "type": "object",
+            "properties": {
+                "catalogFood": {
+                    "$ref": "#/definitions/dto.CatalogFoodDto"
+                },
+                "draft": {
+                    "$ref": "#/definitions/dto.FoodConsumptionDto"
+                },
+                "food": {
+                    "$ref": "#/definitions/dto.FoodAvailableDto"
+                },
+                "transactions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FoodTransactionDto"
+                    }
+                }
+            }
+        },
+        "dto.BaseResponse-array_dto_CatalogFoodDto": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CatalogFoodDto"
+                    }
+                },
+                "error": {
+                    "$ref": "#/definitions/dto.ErrorDto"
+                },
+                "errorMessage": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.BaseResponse-array_dto_FoodConsumptionDto": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FoodConsumptionDto"
+                    }
+                },
+                "error": {
+                    "$ref": "#/definitions/dto.ErrorDto"
+                },
+                "errorMessage": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.BaseResponse-array_dto_FoodPieceWeightDto": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FoodPieceWeightDto"
+                    }
+                },
+                "error": {
+                    "$ref": "#/definitions/dto.ErrorDto"
+                },
+                "errorMessage": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.BaseResponse-array_dto_MealDto": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.MealDto"
+                    }
+                },
+                "error": {
+                    "$ref": "#/definitions/dto.ErrorDto"
+                },
+                "errorMessage": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.BaseResponse-array_dto_PantryFoodDto": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.PantryFoodDto"
+                    }
+                },
+                "error": {
+                    "$ref": "#/definitions/dto.ErrorDto"
+                },
+                "errorMessage": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.BaseResponse-array_dto_PantryTransactionDto": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.PantryTransactionDto"
+                    }
+                },
+                "error": {
+                    "$ref": "#/definitions/dto.ErrorDto"
+                },
+                "errorMessage": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.BaseResponse-array_dto_RecipeDto": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.RecipeDto"
+                    }
+                },
+                "error": {
+                    "$ref": "#/definitions/dto.ErrorDto"
+                },
+                "errorMessage": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.BaseResponse-array_dto_UnitDto": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.UnitDto"
+                    }
+                },
+                "error": {
+                    "$ref": "#/definitions/dto.ErrorDto"
+                },
+                "errorMessage": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.BaseResponse-bool": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "boolean"
+                },
+                "error": {
+                    "$ref": "#/definitions/dto.ErrorDto"
+                },
+                "errorMessage": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.BaseResponse-dto_BarcodeLookupDto": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "$ref": "#/definitions/dto.BarcodeLookupDto"
+                },
+                "error": {
+                    "$ref": "#/definitions/dto.ErrorDto"
+                },
+                "errorMessage": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.BaseResponse-dto_CatalogFoodDto": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "$ref": "#/definitions/dto.CatalogFoodDto"
+                },
+                "error": {
+                    "$ref": "#/definitions/dto.ErrorDto"
+                },
+                "errorMessage": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.BaseResponse-dto_FoodConsumptionDto": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "$ref": "#/definitions/dto.FoodConsumptionDto"
+                },
+                "error": {
+                    "$ref": "#/definitions/dto.ErrorDto"
+                },
+                "errorMessage": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.BaseResponse-dto_FoodPieceWeightDto": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "$ref": "#/definitions/dto.FoodPieceWeightDto"
+                },
+                "error": {
+                    "$ref": "#/definitions/dto.ErrorDto"
+                },
+                "errorMessage": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.BaseResponse-dto_GoalDto": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "$ref": "#/definitions/dto.GoalDto"
+                },
+                "error": {
+                    "$ref": "#/definitions/dto.ErrorDto"
+                },
+                "errorMessage": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.BaseResponse-dto_GoalProgressDto": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "$ref": "#/definitions/dto.GoalProgressDto"
+                },
+                "error": {
+                    "$ref": "#/definitions/dto.ErrorDto"
+                },
+                "errorMessage": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.BaseResponse-dto_MealDto": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "$ref": "#/definitions/dto.MealDto"
+                },
+                "error": {
+                    "$ref": "#/definitions/dto.ErrorDto"
+                },
+                "errorMessage": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.BaseResponse-dto_MealImportResultDto": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "$ref": "#/definitions/dto.MealImportResultDto"
+                },
+                "error": {
+                    "$ref": "#/definitions/dto.ErrorDto"
+                },
+                "errorMessage": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.BaseResponse-dto_MealStatisticsDto": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "$ref": "#/definitions/dto.MealStatisticsDto"
+                },
+                "error": {
+                    "$ref": "#/definitions/dto.ErrorDto"
+                },
+                "errorMessage": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.BaseResponse-dto_MealTimeseriesDto": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "$ref": "#/definitions/dto.MealTimeseriesDto"
+                },
+                "error": {
+                    "$ref": "#/definitions/dto.ErrorDto"
+                },
+                "errorMessage": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.BaseResponse-dto_PlanReportDto": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "$ref": "#/definitions/dto.PlanReportDto"
+                },
+                "error": {
+                    "$ref": "#/definitions/dto.ErrorDto"
+                },
+                "errorMessage": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.BaseResponse-dto_RecipeDto": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "$ref": "#/definitions/dto.RecipeDto"
+                },
+                "error": {
+                    "$ref": "#/definitions/dto.ErrorDto"
+                },
+                "errorMessage": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.BaseResponse-dto_TrashDto": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "$ref": "#/definitions/dto.TrashDto"
+                },
+                "error": {
+                    "$ref": "#/definitions/dto.ErrorDto"
+                },
+                "errorMessage": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.BaseResponse-dto_UserProfileDto": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "$ref": "#/definitions/dto.UserProfileDto"
+                },
+                "error": {
+                    "$ref": "#/definitions/dto.ErrorDto"
+                },
+                "errorMessage": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CatalogFoodDto": {
+            "type": "object",
+            "required": [
+                "aliases",
+                "name"
+            ],
+            "properties": {
+                "aliases": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "barcode": {
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "carbohydrate": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "defaultUnit": {
+                    "type": "string"
+                },
+                "fat": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "fiber": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kcal": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "protein": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "sodium": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "sugar": {
+                    "type": "number",
+                    "minimum": 0
+                }
+            }
+        },
+        "dto.DuplicateMealDto": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "dates": {
+                    "type": "array",
+                    "maxItems": 31,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dto.ErrorDto": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FieldErrorDto"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.FieldErrorDto": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.FoodAvailableDto": {
+            "type": "object",
+            "properties": {
+                "availableQuantity": {
+                    "type": "number"
+                },
+                "barcode": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "unit": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.FoodConsumptionDto": {
+            "type": "object",
+            "required": [
+                "foodName",
+                "unit"
+            ],
+            "properties": {
+                "carbohydrate": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "catalogFoodId": {
+                    "type": "string"
+                },
+                "cost": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "fat": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "fiber": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "foodId": {
+                    "type": "string"
+                },
+                "foodName": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kcal": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "mealId": {
+                    "type": "string"
+                },
+                "protein": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "quantityUsed": {
+                    "type": "number"
+                },
+                "quantityUsedStd": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "sodium": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "sugar": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "syncFailed": {
+                    "type": "boolean"
+                },
+                "transactionId": {
+                    "type": "string"
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "unitStd": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.FoodPieceWeightDto": {
+            "type": "object",
+            "required": [
+                "foodName"
+            ],
+            "properties": {
+                "foodName": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "grams": {
+                    "type": "number"
+                }
+            }
+        },
+        "dto.FoodTransactionDto": {
+            "type": "object",
+            "properties": {
+                "availableQuantity": {
+                    "type": "number"
+                },
+                "expirationDate": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "vendor": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.GoalDto": {
+            "type": "object",
+            "properties": {
+                "budget": {
+                    "type": "number"
+                },
+                "carbohydrate": {
+                    "type": "number"
+                },
+                "fat": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kcal": {
+                    "type": "number"
+                },
+                "protein": {
+                    "type": "number"
+                },
+                "userId": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.GoalProgressDto": {
+            "type": "object",
+            "properties": {
+                "budget": {
+                    "$ref": "#/definitions/dto.NutrientProgressDto"
+                },
+                "carbohydrate": {
+                    "$ref": "#/definitions/dto.NutrientProgressDto"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "fat": {
+                    "$ref": "#/definitions/dto.NutrientProgressDto"
+                },
+                "kcal": {
+                    "$ref": "#/definitions/dto.NutrientProgressDto"
+                },
+                "protein": {
+                    "$ref": "#/definitions/dto.NutrientProgressDto"
+                }
+            }
+        },
+        "dto.MealDto": {
+            "type": "object",
+            "required": [
+                "date",
+                "mealType",
+                "name"
+            ],
+            "properties": {
+                "carbohydrate": {
+                    "type": "number"
+                },
+                "cost": {
+                    "type": "number"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "fat": {
+                    "type": "number"
+                },
+                "fiber": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kcal": {
+                    "type": "number"
+                },
+                "mealType": {
+                    "$ref": "#/definitions/model.MealType"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "protein": {
+                    "type": "number"
+                },
+                "sodium": {
+                    "type": "number"
+                },
+                "status": {
+                    "$ref": "#/definitions/model.MealStatus"
+                },
+                "sugar": {
+                    "type": "number"
+                },
+                "userId": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.MealExportDto": {
+            "type": "object",
+            "required": [
+                "date",
+                "mealType",
+                "name"
+            ],
+            "properties": {
+                "carbohydrate": {
+                    "type": "number"
+                },
+                "cost": {
+                    "type": "number"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "fat": {
+                    "type": "number"
+                },
+                "fiber": {
+                    "type": "number"
+                },
+                "foodConsumptions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FoodConsumptionDto"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kcal": {
+                    "type": "number"
+                },
+                "mealType": {
+                    "$ref": "#/definitions/model.MealType"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "protein": {
+                    "type": "number"
+                },
+                "sodium": {
+                    "type": "number"
+                },
+                "status": {
+                    "$ref": "#/definitions/model.MealStatus"
+                },
+                "sugar": {
+                    "type": "number"
+                },
+                "userId": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.MealImportErrorDto": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "row": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.MealImportResultDto": {
+            "type": "object",
+            "properties": {
+                "dryRun": {
+                    "type": "boolean"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.MealImportErrorDto"
+                    }
+                },
+                "foodConsumptions": {
+                    "type": "integer"
+                },
+                "meals": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.MealStatisticsDto": {
+            "type": "object",
+            "properties": {
+                "averageWeekCalories": {
+                    "type": "number"
+                },
+                "averageWeekCaloriesPerMealType": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.AvgKcalPerMealTypeDto"
+                    }
+                },
+                "averageWeekFoodCost": {
+                    "type": "number"
+                },
+                "mostConsumedFood": {
+                    "$ref": "#/definitions/dto.MostConsumedFoodDto"
+                },
+                "sumWeekFoodCost": {
+                    "type": "number"
+                }
+            }
+        },
+        "dto.MealTimeseriesDto": {
+            "type": "object",
+            "properties": {
+                "endRange": {
+                    "type": "string"
+                },
+                "granularity": {
+                    "$ref": "#/definitions/dto.TimeseriesGranularity"
+                },
+                "perMealType": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.MealTypeTimeseriesDto"
+                    }
+                },
+                "startRange": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.TimeseriesPointDto"
+                    }
+                }
+            }
+        },
+        "dto.MealTypeTimeseriesDto": {
+            "type": "object",
+            "properties": {
+                "mealType": {
+                    "$ref": "#/definitions/model.MealType"
+                },
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.TimeseriesPointDto"
                     }
+                }
+            }
+        },
+        "dto.MostConsumedFoodDto": {
+            "type": "object",
+            "properties": {
+                "foodId": {
+                    "type": "string"
+                },
+                "foodName": {
+                    "type": "string"
+                },
+                "quantityUsed": {
+                    "type": "number"
+                },
+                "quantityUsedStd": {
+                    "type": "number"
+                },
+                "unit": {
+                    "type": "string"
                 },
-                "errorMessage": {
+                "unitStd": {
                     "type": "string"
                 }
             }
         },
-        "dto.BaseResponse-bool": {
+        "dto.NutrientProgressDto": {
             "type": "object",
             "properties": {
-                "body": {
-                    "type": "boolean"
+                "consumed": {
+                    "type": "number"
                 },
-                "errorMessage": {
-                    "type": "string"
+                "remaining": {
+                    "type": "number"
+                },
+                "target": {
+                    "type": "number"
                 }
             }
         },
-        "dto.BaseResponse-dto_FoodConsumptionDto": {
+        "dto.NutritionDto": {
             "type": "object",
             "properties": {
-                "body": {
-                    "$ref": "#/definitions/dto.FoodConsumptionDto"
+                "carbohydrate": {
+                    "type": "number"
                 },
-                "errorMessage": {
+                "fat": {
+                    "type": "number"
+                },
+                "fiber": {
+                    "type": "number"
+                },
+                "kcal": {
+                    "type": "number"
+                },
+                "protein": {
+                    "type": "number"
+                },
+                "sodium": {
+                    "type": "number"
+                },
+                "sugar": {
+                    "type": "number"
+                }
+            }
+        },
+        "dto.PantryFoodDto": {
+            "type": "object",
+            "properties": {
+                "availableQuantity": {
+                    "type": "number"
+                },
+                "barcode": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "remainingPercentage": {
+                    "type": "number"
+                },
+                "unit": {
                     "type": "string"
                 }
             }
         },
-        "dto.BaseResponse-dto_MealDto": {
+        "dto.PantryTransactionDto": {
             "type": "object",
             "properties": {
-                "body": {
-                    "$ref": "#/definitions/dto.MealDto"
+                "availableQuantity": {
+                    "type": "number"
                 },
-                "errorMessage": {
+                "daysUntilExpiry": {
+                    "type": "integer"
+                },
+                "expirationDate": {
+                    "type": "string"
+                },
+                "expired": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "pricePerUnit": {
+                    "type": "number"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "remainingValue": {
+                    "type": "number"
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "vendor": {
                     "type": "string"
                 }
             }
         },
-        "dto.BaseResponse-dto_MealStatisticsDto": {
+        "dto.PlanReportDto": {
             "type": "object",
             "properties": {
-                "body": {
-                    "$ref": "#/definitions/dto.MealStatisticsDto"
+                "actual": {
+                    "$ref": "#/definitions/dto.PlanTotalsDto"
                 },
-                "errorMessage": {
+                "difference": {
+                    "$ref": "#/definitions/dto.PlanTotalsDto"
+                },
+                "endRange": {
+                    "type": "string"
+                },
+                "planned": {
+                    "$ref": "#/definitions/dto.PlanTotalsDto"
+                },
+                "startRange": {
                     "type": "string"
                 }
             }
         },
-        "dto.FoodConsumptionDto": {
+        "dto.PlanTotalsDto": {
             "type": "object",
             "properties": {
                 "cost": {
                     "type": "number"
                 },
+                "kcal": {
+                    "type": "number"
+                }
+            }
+        },
+        "dto.RecipeDto": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ingredients": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.RecipeIngredientDto"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "perServing": {
+                    "$ref": "#/definitions/dto.NutritionDto"
+                },
+                "servings": {
+                    "type": "number"
+                },
+                "userId": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.RecipeIngredientDto": {
+            "type": "object",
+            "properties": {
+                "carbohydrate": {
+                    "type": "number"
+                },
+                "catalogFoodId": {
+                    "type": "string"
+                },
+                "fat": {
+                    "type": "number"
+                },
+                "fiber": {
+                    "type": "number"
+                },
                 "foodId": {
                     "type": "string"
                 },
@@ -463,35 +2625,185 @@ const docTemplate = `{
                 "kcal": {
                     "type": "number"
                 },
+                "protein": {
+                    "type": "number"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "quantityStd": {
+                    "type": "number"
+                },
+                "sodium": {
+                    "type": "number"
+                },
+                "sugar": {
+                    "type": "number"
+                },
+                "unit": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.TimeseriesGranularity": {
+            "type": "string",
+            "enum": [
+                "day",
+                "week",
+                "month"
+            ],
+            "x-enum-varnames": [
+                "Day",
+                "Week",
+                "Month"
+            ]
+        },
+        "dto.TimeseriesPointDto": {
+            "type": "object",
+            "properties": {
+                "cost": {
+                    "type": "number"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "kcal": {
+                    "type": "number"
+                },
+                "mealCount": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.TrashDto": {
+            "type": "object",
+            "properties": {
+                "foodConsumptions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.TrashedFoodConsumptionDto"
+                    }
+                },
+                "meals": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.TrashedMealDto"
+                    }
+                }
+            }
+        },
+        "dto.TrashedFoodConsumptionDto": {
+            "type": "object",
+            "required": [
+                "foodName",
+                "unit"
+            ],
+            "properties": {
+                "carbohydrate": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "catalogFoodId": {
+                    "type": "string"
+                },
+                "cost": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "deletedAt": {
+                    "type": "string"
+                },
+                "fat": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "fiber": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "foodId": {
+                    "type": "string"
+                },
+                "foodName": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kcal": {
+                    "type": "number",
+                    "minimum": 0
+                },
                 "mealId": {
                     "type": "string"
                 },
+                "protein": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "purgeAt": {
+                    "type": "string"
+                },
                 "quantityUsed": {
                     "type": "number"
                 },
                 "quantityUsedStd": {
-                    "type": "number"
+                    "type": "number",
+                    "minimum": 0
+                },
+                "sodium": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "sugar": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "syncFailed": {
+                    "type": "boolean"
                 },
                 "transactionId": {
                     "type": "string"
                 },
                 "unit": {
                     "type": "string"
+                },
+                "unitStd": {
+                    "type": "string"
                 }
             }
         },
-        "dto.MealDto": {
+        "dto.TrashedMealDto": {
             "type": "object",
+            "required": [
+                "date",
+                "mealType",
+                "name"
+            ],
             "properties": {
+                "carbohydrate": {
+                    "type": "number"
+                },
                 "cost": {
                     "type": "number"
                 },
                 "date": {
                     "type": "string"
                 },
-                "description": {
+                "deletedAt": {
                     "type": "string"
                 },
+                "description": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "fat": {
+                    "type": "number"
+                },
+                "fiber": {
+                    "type": "number"
+                },
                 "id": {
                     "type": "string"
                 },
@@ -502,56 +2814,68 @@ const docTemplate = `{
                     "$ref": "#/definitions/model.MealType"
                 },
                 "name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "protein": {
+                    "type": "number"
+                },
+                "purgeAt": {
                     "type": "string"
                 },
+                "sodium": {
+                    "type": "number"
+                },
+                "status": {
+                    "$ref": "#/definitions/model.MealStatus"
+                },
+                "sugar": {
+                    "type": "number"
+                },
                 "userId": {
                     "type": "string"
                 }
             }
         },
-        "dto.MealStatisticsDto": {
+        "dto.UnitDto": {
             "type": "object",
             "properties": {
-                "averageWeekCalories": {
-                    "type": "number"
-                },
-                "averageWeekCaloriesPerMealType": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/dto.AvgKcalPerMealTypeDto"
-                    }
-                },
-                "averageWeekFoodCost": {
+                "factor": {
                     "type": "number"
                 },
-                "mostConsumedFood": {
-                    "$ref": "#/definitions/dto.MostConsumedFoodDto"
+                "unit": {
+                    "type": "string"
                 },
-                "sumWeekFoodCost": {
-                    "type": "number"
+                "unitStd": {
+                    "type": "string"
                 }
             }
         },
-        "dto.MostConsumedFoodDto": {
+        "dto.UserProfileDto": {
             "type": "object",
+            "required": [
+                "timezone"
+            ],
             "properties": {
-                "foodId": {
-                    "type": "string"
-                },
-                "foodName": {
+                "timezone": {
                     "type": "string"
                 },
-                "quantityUsed": {
-                    "type": "number"
-                },
-                "quantityUsedStd": {
-                    "type": "number"
-                },
-                "unit": {
+                "userId": {
                     "type": "string"
                 }
             }
         },
+        "model.MealStatus": {
+            "type": "string",
+            "enum": [
+                "planned",
+                "eaten"
+            ],
+            "x-enum-varnames": [
+                "Planned",
+                "Eaten"
+            ]
+        },
         "model.MealType": {
             "type": "string",
             "enum": [
@@ -567,6 +2891,10 @@ const docTemplate = `{
                 "Others"
             ]
         }
+    },
+    "externalDocs": {
+        "description": "OpenAPI",
+        "url": "https://swagger.io/resources/open-api/"
     }
 }`
 
@@ -574,12 +2902,14 @@ const docTemplate = `{
 var SwaggerInfo = &swag.Spec{
 	Version:          "1.0",
 	Host:             "localhost:8080",
-	BasePath:         "/api/meal",
+	BasePath:         "/api",
 	Schemes:          []string{},
 	Title:            "Food track be API",
 	Description:      "This is a sample server celler server.",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
+	LeftDelim:        "{{",
+	RightDelim:       "}}",
 }
 
 func init() {
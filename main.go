@@ -4,7 +4,11 @@ import (
 	"context"
 	"database/sql"
 	firebase "firebase.google.com/go/v4"
+	"fmt"
 	"food-track-be/controller"
+	"food-track-be/middleware"
+	"food-track-be/migrations"
+	"food-track-be/model/dto"
 	"food-track-be/repository"
 	"food-track-be/service"
 	"github.com/gin-contrib/cors"
@@ -22,6 +26,8 @@ import (
 	"github.com/gin-gonic/gin"
 
 	_ "food-track-be/docs"
+	// The timezones of the users are loaded from the embedded database, missing in the runtime image.
+	_ "time/tzdata"
 )
 
 //	@title			Food track be API
@@ -32,7 +38,7 @@ import (
 //	@contact.email	nicolaiacovelli98@gmail.com
 
 //	@host		localhost:8080
-//	@BasePath	/api/meal
+//	@BasePath	/api
 
 // @externalDocs.description	OpenAPI
 // @externalDocs.url			https://swagger.io/resources/open-api/
@@ -45,6 +51,19 @@ func main() {
 	dbPassword := os.Getenv("DB_PASSWORD")
 	dbName := os.Getenv("DB_NAME")
 	dbTimeout, _ := strconv.ParseInt(os.Getenv("DB_TIMEOUT"), 10, 64)
+	groceryOutboxInterval, err := strconv.ParseInt(os.Getenv("GROCERY_OUTBOX_INTERVAL"), 10, 64)
+	if err != nil || groceryOutboxInterval <= 0 {
+		groceryOutboxInterval = 30
+	}
+	trashRetention := service.DefaultTrashRetention
+	trashRetentionDays, err := strconv.ParseInt(os.Getenv("TRASH_RETENTION_DAYS"), 10, 64)
+	if err == nil && trashRetentionDays > 0 {
+		trashRetention = time.Duration(trashRetentionDays) * 24 * time.Hour
+	}
+	trashPurgeInterval, err := strconv.ParseInt(os.Getenv("TRASH_PURGE_INTERVAL"), 10, 64)
+	if err != nil || trashPurgeInterval <= 0 {
+		trashPurgeInterval = 3600
+	}
 
 	var pgconn *pgdriver.Connector
 
@@ -71,33 +90,80 @@ func main() {
 		}
 	}(db)
 
-	err := db.Ping()
+	err = db.Ping()
 	if err != nil {
 		log.Println(err)
 		panic(err)
 	}
 
-	app, err := firebase.NewApp(context.Background(), nil)
+	// The commands run against the migrated schema, except the migrate command which manages it.
+	migrateCommand := len(os.Args) > 1 && os.Args[1] == "migrate"
+	if os.Getenv("DB_AUTO_MIGRATE") != "false" && !migrateCommand {
+		err = migrations.Up(context.Background(), db)
+		if err != nil {
+			log.Fatalf("error migrating database: %v\n", err)
+		}
+	}
+
+	if len(os.Args) > 1 {
+		runCommand(db, os.Args[1:])
+		return
+	}
+
+	authenticator, err := newAuthenticator(os.Getenv("AUTH_PROVIDER"))
 	if err != nil {
-		log.Fatalf("error initializing app: %v\n", err)
+		log.Fatalf("error initializing authentication: %v\n", err)
 	}
 
 	mr := repository.NewMealRepository(*db)
 	fcr := repository.NewFoodConsumptionRepository(*db)
+	gr := repository.NewGoalRepository(*db)
+	gor := repository.NewGroceryOutboxRepository(*db)
+	rr := repository.NewRecipeRepository(*db)
+	upr := repository.NewUserProfileRepository(*db)
+	fpwr := repository.NewFoodPieceWeightRepository(*db)
+	cfr := repository.NewCatalogFoodRepository(*db)
 	gs := service.NewGroceryService()
-	fcs := service.NewFoodConsumptionService(fcr, gs)
+	gos := service.NewGroceryOutboxService(gor, mr, fcr, gs)
+	us := service.NewUnitService(fpwr)
+	cfs := service.NewCatalogFoodService(cfr)
+	fcs := service.NewFoodConsumptionService(fcr, mr, gs, gos, us, cfs)
 	ms := service.NewMealService(mr, fcs)
-	mc := controller.NewMealController(ms, app)
-	fcc := controller.NewFoodConsumptionController(fcs, app)
+	mis := service.NewMealImportService(mr, fcr, us)
+	gls := service.NewGoalService(gr, mr)
+	rs := service.NewRecipeService(rr, mr, fcs)
+	ts := service.NewTrashService(mr, fcr, ms, fcs, trashRetention)
+	ups := service.NewUserProfileService(upr)
+	ps := service.NewPantryService(gs)
+	mc := controller.NewMealController(ms, mis)
+	fcc := controller.NewFoodConsumptionController(fcs)
+	gc := controller.NewGoalController(gls)
+	rc := controller.NewRecipeController(rs)
+	tc := controller.NewTrashController(ts)
+	upc := controller.NewUserProfileController(ups)
+	uc := controller.NewUnitController(us)
+	cfc := controller.NewCatalogFoodController(cfs)
+	pc := controller.NewPantryController(ps)
+	am := middleware.NewAuthMiddleware(authenticator)
+	tm := middleware.NewTimezoneMiddleware(ups)
+
+	gos.Start(time.Duration(groceryOutboxInterval) * time.Second)
+	ts.Start(time.Duration(trashPurgeInterval) * time.Second)
+
+	err = controller.RegisterValidators()
+	if err != nil {
+		log.Fatalf("error registering validators: %v\n", err)
+	}
 
 	r := gin.Default()
 	corsConfig := cors.DefaultConfig()
 	corsConfig.AllowAllOrigins = true
-	corsConfig.AllowHeaders = append(corsConfig.AllowHeaders, "Authorization")
+	corsConfig.AllowHeaders = append(corsConfig.AllowHeaders, "Authorization", middleware.TimezoneHeader)
 	//corsConfig.AllowHeaders = append(corsConfig.AllowHeaders, "iv-user")
 	r.Use(cors.New(corsConfig))
+	r.Use(middleware.ErrorHandler)
 
-	mealApi := r.Group("/api/meal")
+	mealApi := r.Group("/api/meal", am.Handle, tm.Handle)
 	{
 		mealApi.GET("/", mc.FindAllMeals)
 		mealApi.GET(":mealId/", mc.FindMealById)
@@ -105,6 +171,17 @@ func main() {
 		mealApi.PATCH(":mealId/", mc.UpdateMeal)
 		mealApi.DELETE(":mealId/", mc.DeleteMeal)
 		mealApi.GET("/statistics/", mc.GetMealStatistics)
+		mealApi.GET("/statistics/timeseries/", mc.GetMealTimeseries)
+		mealApi.GET("/export/", mc.ExportMeals)
+		mealApi.POST("/import/", mc.ImportMeals)
+		mealApi.GET("/progress/", gc.GetDailyProgress)
+		mealApi.POST("/plan/", mc.GeneratePlan)
+		mealApi.GET("/plan/report/", mc.GetPlanReport)
+		mealApi.POST(":mealId/eaten/", mc.MarkMealAsEaten)
+		mealApi.POST(":mealId/duplicate/", mc.DuplicateMeal)
+		mealApi.GET("/trash/", tc.FindTrash)
+		mealApi.POST("/trash/:mealId/restore/", tc.RestoreMeal)
+		mealApi.POST("/trash/:mealId/consumption/:foodConsumptionId/restore/", tc.RestoreFoodConsumption)
 
 		mealApi.GET(":mealId/consumption/", fcc.FindAllConsumptionForMeal)
 		mealApi.POST(":mealId/consumption/", fcc.AddFoodConsumption)
@@ -112,6 +189,54 @@ func main() {
 		mealApi.DELETE(":mealId/consumption/:foodConsumptionId/", fcc.DeleteFoodConsumption)
 	}
 
+	goalApi := r.Group("/api/goal", am.Handle, tm.Handle)
+	{
+		goalApi.GET("/", gc.FindGoal)
+		goalApi.POST("/", gc.CreateGoal)
+		goalApi.PATCH("/", gc.UpdateGoal)
+		goalApi.DELETE("/", gc.DeleteGoal)
+	}
+
+	recipeApi := r.Group("/api/recipe", am.Handle)
+	{
+		recipeApi.GET("/", rc.FindAllRecipes)
+		recipeApi.GET(":recipeId/", rc.FindRecipeById)
+		recipeApi.POST("/", rc.CreateRecipe)
+		recipeApi.PATCH(":recipeId/", rc.UpdateRecipe)
+		recipeApi.DELETE(":recipeId/", rc.DeleteRecipe)
+		recipeApi.POST(":recipeId/apply/", rc.ApplyRecipe)
+	}
+
+	profileApi := r.Group("/api/profile", am.Handle)
+	{
+		profileApi.GET("/", upc.FindUserProfile)
+		profileApi.PUT("/", upc.UpdateUserProfile)
+	}
+
+	unitApi := r.Group("/api/unit", am.Handle)
+	{
+		unitApi.GET("/", uc.FindAllUnits)
+		unitApi.GET("/piece-weight/", uc.FindAllPieceWeights)
+		unitApi.PUT("/piece-weight/", uc.SavePieceWeight)
+		unitApi.DELETE("/piece-weight/:foodName/", uc.DeletePieceWeight)
+	}
+
+	foodApi := r.Group("/api/food", am.Handle)
+	{
+		foodApi.GET("/", cfc.SearchCatalogFoods)
+		foodApi.GET(":foodId/", cfc.FindCatalogFoodById)
+		foodApi.GET("/barcode/:code/", fcc.LookupBarcode)
+		foodApi.POST("/", am.RequireAdmin, cfc.CreateCatalogFood)
+		foodApi.PATCH(":foodId/", am.RequireAdmin, cfc.UpdateCatalogFood)
+		foodApi.DELETE(":foodId/", am.RequireAdmin, cfc.DeleteCatalogFood)
+	}
+
+	pantryApi := r.Group("/api/pantry", am.Handle, tm.Handle)
+	{
+		pantryApi.GET("/", pc.FindAllPantryFoods)
+		pantryApi.GET(":foodId/transaction/", pc.FindAllPantryTransactions)
+	}
+
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	r.GET("/ping", func(c *gin.Context) {
@@ -122,3 +247,74 @@ func main() {
 
 	r.Run() // listen and serve on 0.0.0.0:8080 (for windows "localhost:8080")
 }
+
+// runCommand executes the cli subcommand passed to the binary instead of starting the server.
+//
+// Supported commands:
+//
+//	migrate up|down|status
+//	catalog import <file> [jsonl|csv]
+func runCommand(db *bun.DB, args []string) {
+	ctx := context.Background()
+	switch args[0] {
+	case "migrate":
+		if len(args) < 2 {
+			log.Fatalln("usage: migrate up|down|status")
+		}
+		var err error
+		switch args[1] {
+		case "up":
+			err = migrations.Up(ctx, db)
+		case "down":
+			err = migrations.Down(ctx, db)
+		case "status":
+			err = migrations.Status(ctx, db)
+		default:
+			log.Fatalf("unknown migrate command %q, expected up|down|status\n", args[1])
+		}
+		if err != nil {
+			log.Fatalf("error running migrate %s: %v\n", args[1], err)
+		}
+	case "catalog":
+		if len(args) < 3 || args[1] != "import" {
+			log.Fatalln("usage: catalog import <file> [jsonl|csv]")
+		}
+		format := ""
+		if len(args) > 3 {
+			format = args[3]
+		}
+		cis := service.NewCatalogImportService(repository.NewCatalogFoodRepository(*db))
+		result, err := cis.ImportFile(args[2], format, func(progress dto.CatalogImportResultDto) {
+			log.Printf("catalog import: %d rows read, %d foods imported\n", progress.Rows, progress.Imported)
+		})
+		if err != nil {
+			log.Fatalf("error importing %s: %v\n", args[2], err)
+		}
+		log.Printf("catalog import completed: %d rows read, %d foods imported, %d rows skipped\n", result.Rows, result.Imported, result.Skipped)
+	default:
+		log.Fatalf("unknown command %q\n", args[0])
+	}
+}
+
+// newAuthenticator creates the authenticator of the provider: firebase, the default, verifies Firebase ID tokens while
+// jwt verifies JWTs with the key configured by the JWT_* environment variables, without reaching any external service.
+func newAuthenticator(provider string) (middleware.Authenticator, error) {
+	switch provider {
+	case "", "firebase":
+		app, err := firebase.NewApp(context.Background(), nil)
+		if err != nil {
+			return nil, err
+		}
+		return middleware.NewFirebaseAuthenticator(app)
+	case "jwt":
+		return middleware.NewJwtAuthenticator(middleware.JwtConfig{
+			Secret:        os.Getenv("JWT_SECRET"),
+			PublicKeyFile: os.Getenv("JWT_PUBLIC_KEY_FILE"),
+			JwksFile:      os.Getenv("JWT_JWKS_FILE"),
+			Issuer:        os.Getenv("JWT_ISSUER"),
+			Audience:      os.Getenv("JWT_AUDIENCE"),
+		})
+	default:
+		return nil, fmt.Errorf("unknown auth provider %s", provider)
+	}
+}
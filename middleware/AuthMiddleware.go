@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"food-track-be/service"
+	"github.com/gin-gonic/gin"
+	"log"
+	"strings"
+)
+
+const (
+	userIdKey = "userId"
+	claimsKey = "claims"
+	tokenKey  = "token"
+	// adminClaim is the token claim, a Firebase custom claim, which marks the administrators.
+	adminClaim = "admin"
+)
+
+// AuthMiddleware authenticates the requests through the token of the Authorization header.
+type AuthMiddleware struct {
+	authenticator Authenticator
+}
+
+func NewAuthMiddleware(authenticator Authenticator) *AuthMiddleware {
+	return &AuthMiddleware{authenticator: authenticator}
+}
+
+// Handle verifies the token and stores the user id, the token claims and the token in the request context, where
+// handlers read them with GetUserId, GetClaims and GetToken. Requests without a valid token are aborted with an
+// Unauthorized error.
+func (m *AuthMiddleware) Handle(c *gin.Context) {
+	token := strings.TrimSpace(strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer "))
+	if token == "" {
+		abortUnauthorized(c, "missing authorization token")
+		return
+	}
+	user, err := m.authenticator.Verify(c.Request.Context(), token)
+	if err != nil {
+		log.Println(err)
+		abortUnauthorized(c, "invalid authorization token")
+		return
+	}
+	c.Set(userIdKey, user.UserId)
+	c.Set(claimsKey, user.Claims)
+	c.Set(tokenKey, token)
+	c.Next()
+}
+
+// RequireAdmin lets through only the requests of administrators, the users whose token has the admin claim set to
+// true, aborting the others with a Forbidden error. It must follow Handle.
+func (m *AuthMiddleware) RequireAdmin(c *gin.Context) {
+	if admin, _ := GetClaims(c)[adminClaim].(bool); !admin {
+		_ = c.Error(service.NewForbiddenError("only administrators can change the food catalog"))
+		c.Abort()
+		return
+	}
+	c.Next()
+}
+
+// GetUserId returns the id of the authenticated user.
+func GetUserId(c *gin.Context) string {
+	return c.GetString(userIdKey)
+}
+
+// GetClaims returns the claims of the authenticated user's token.
+func GetClaims(c *gin.Context) map[string]interface{} {
+	return c.GetStringMap(claimsKey)
+}
+
+// GetToken returns the authenticated user's token, without the Bearer prefix, to be forwarded to the other services.
+func GetToken(c *gin.Context) string {
+	return c.GetString(tokenKey)
+}
+
+func abortUnauthorized(c *gin.Context, message string) {
+	_ = c.Error(service.NewUnauthorizedError(message))
+	c.Abort()
+}
@@ -0,0 +1,14 @@
+package middleware
+
+import "context"
+
+// Authenticator verifies the token of a request and returns the user it belongs to.
+type Authenticator interface {
+	Verify(ctx context.Context, token string) (*AuthenticatedUser, error)
+}
+
+// AuthenticatedUser is the user of a verified token, with the token claims.
+type AuthenticatedUser struct {
+	UserId string
+	Claims map[string]interface{}
+}
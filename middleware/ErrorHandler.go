@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"food-track-be/model/dto"
+	"food-track-be/service"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+)
+
+// ErrorHandler responds to the requests aborted with an error, translating the kind of the service.DomainError to the
+// HTTP status. The message of internal errors isn't disclosed to the client.
+func ErrorHandler(c *gin.Context) {
+	c.Next()
+	if len(c.Errors) == 0 || c.Writer.Written() {
+		return
+	}
+	err := c.Errors.Last().Err
+	log.Println(err)
+
+	domainError := service.ToDomainError(err)
+	status := statusOf(domainError.Kind)
+	message := domainError.Message
+	if domainError.Kind == service.Internal || message == "" {
+		message = http.StatusText(status)
+	}
+	c.JSON(status, dto.BaseResponse[any]{
+		ErrorMessage: message,
+		Error: &dto.ErrorDto{
+			Code:    string(domainError.Kind),
+			Message: message,
+			Fields:  domainError.Fields,
+		},
+	})
+}
+
+func statusOf(kind service.ErrorKind) int {
+	switch kind {
+	case service.Validation:
+		return http.StatusBadRequest
+	case service.Unauthorized:
+		return http.StatusUnauthorized
+	case service.Forbidden:
+		return http.StatusForbidden
+	case service.NotFound:
+		return http.StatusNotFound
+	case service.Conflict:
+		return http.StatusConflict
+	case service.UpstreamFailure:
+		return http.StatusBadGateway
+	case service.UpstreamUnavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
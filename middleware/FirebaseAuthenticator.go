@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"context"
+	firebase "firebase.google.com/go/v4"
+	"firebase.google.com/go/v4/auth"
+)
+
+// FirebaseAuthenticator verifies Firebase ID tokens.
+type FirebaseAuthenticator struct {
+	client *auth.Client
+}
+
+// NewFirebaseAuthenticator creates the authenticator, initializing once the Firebase auth client used by all the
+// requests.
+func NewFirebaseAuthenticator(app *firebase.App) (*FirebaseAuthenticator, error) {
+	client, err := app.Auth(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return &FirebaseAuthenticator{client: client}, nil
+}
+
+func (a *FirebaseAuthenticator) Verify(ctx context.Context, token string) (*AuthenticatedUser, error) {
+	idToken, err := a.client.VerifyIDToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return &AuthenticatedUser{UserId: idToken.UID, Claims: idToken.Claims}, nil
+}
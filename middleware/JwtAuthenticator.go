@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/MicahParks/keyfunc"
+	"github.com/golang-jwt/jwt/v4"
+	"os"
+	"time"
+)
+
+// JwtConfig configures the JwtAuthenticator. Exactly one of Secret, PublicKeyFile and JwksFile must be set.
+type JwtConfig struct {
+	// Secret is the key of HS256 tokens.
+	Secret string
+	// PublicKeyFile is the path of the PEM encoded public key of RS256 tokens.
+	PublicKeyFile string
+	// JwksFile is the path of a JSON Web Key Set, whose keys are selected by the kid header of the tokens.
+	JwksFile string
+	// Issuer, when set, must match the iss claim of the tokens.
+	Issuer string
+	// Audience, when set, must be included in the aud claim of the tokens.
+	Audience string
+}
+
+// JwtAuthenticator verifies JWTs signed with a locally configured key, so that the service can run without reaching
+// Firebase. The user id is the sub claim of the token.
+type JwtAuthenticator struct {
+	parser  *jwt.Parser
+	keyFunc jwt.Keyfunc
+	config  JwtConfig
+}
+
+func NewJwtAuthenticator(config JwtConfig) (*JwtAuthenticator, error) {
+	authenticator := &JwtAuthenticator{config: config}
+	switch {
+	case config.Secret != "" && config.PublicKeyFile == "" && config.JwksFile == "":
+		secret := []byte(config.Secret)
+		authenticator.keyFunc = func(token *jwt.Token) (interface{}, error) {
+			return secret, nil
+		}
+		authenticator.parser = jwt.NewParser(jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	case config.PublicKeyFile != "" && config.Secret == "" && config.JwksFile == "":
+		pem, err := os.ReadFile(config.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		publicKey, err := jwt.ParseRSAPublicKeyFromPEM(pem)
+		if err != nil {
+			return nil, err
+		}
+		authenticator.keyFunc = func(token *jwt.Token) (interface{}, error) {
+			return publicKey, nil
+		}
+		authenticator.parser = jwt.NewParser(jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}))
+	case config.JwksFile != "" && config.Secret == "" && config.PublicKeyFile == "":
+		jwksJson, err := os.ReadFile(config.JwksFile)
+		if err != nil {
+			return nil, err
+		}
+		jwks, err := keyfunc.NewJSON(jwksJson)
+		if err != nil {
+			return nil, err
+		}
+		authenticator.keyFunc = jwks.Keyfunc
+		authenticator.parser = jwt.NewParser(jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg(), jwt.SigningMethodRS256.Alg()}))
+	default:
+		return nil, errors.New("exactly one of the jwt secret, public key file and jwks file must be configured")
+	}
+	return authenticator, nil
+}
+
+func (a *JwtAuthenticator) Verify(ctx context.Context, token string) (*AuthenticatedUser, error) {
+	claims := jwt.MapClaims{}
+	_, err := a.parser.ParseWithClaims(token, claims, a.keyFunc)
+	if err != nil {
+		return nil, err
+	}
+	// The parser verifies exp only when present: tokens which never expire are rejected.
+	if !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return nil, errors.New("token has no expiration")
+	}
+	if a.config.Issuer != "" && !claims.VerifyIssuer(a.config.Issuer, true) {
+		return nil, fmt.Errorf("token issuer is not %s", a.config.Issuer)
+	}
+	if a.config.Audience != "" && !claims.VerifyAudience(a.config.Audience, true) {
+		return nil, fmt.Errorf("token audience doesn't include %s", a.config.Audience)
+	}
+	userId, _ := claims["sub"].(string)
+	if userId == "" {
+		return nil, errors.New("token has no subject")
+	}
+	return &AuthenticatedUser{UserId: userId, Claims: claims}, nil
+}
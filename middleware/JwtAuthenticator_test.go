@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"context"
+	"github.com/golang-jwt/jwt/v4"
+	"testing"
+	"time"
+)
+
+const (
+	testSecret = "test-secret"
+	testIssuer = "https://auth.example.com"
+)
+
+func signTestToken(t *testing.T, claims jwt.MapClaims, secret string) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestJwtAuthenticatorVerify(t *testing.T) {
+	authenticator, err := NewJwtAuthenticator(JwtConfig{Secret: testSecret, Issuer: testIssuer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	inAnHour := time.Now().Add(time.Hour).Unix()
+	tokens := []struct {
+		name   string
+		claims jwt.MapClaims
+		secret string
+		valid  bool
+	}{
+		{"valid", jwt.MapClaims{"sub": "user-1", "iss": testIssuer, "exp": inAnHour}, testSecret, true},
+		{"missing exp", jwt.MapClaims{"sub": "user-1", "iss": testIssuer}, testSecret, false},
+		{"expired", jwt.MapClaims{"sub": "user-1", "iss": testIssuer, "exp": time.Now().Add(-time.Minute).Unix()}, testSecret, false},
+		{"wrong issuer", jwt.MapClaims{"sub": "user-1", "iss": "https://other.example.com", "exp": inAnHour}, testSecret, false},
+		{"missing issuer", jwt.MapClaims{"sub": "user-1", "exp": inAnHour}, testSecret, false},
+		{"bad signature", jwt.MapClaims{"sub": "user-1", "iss": testIssuer, "exp": inAnHour}, "other-secret", false},
+		{"missing subject", jwt.MapClaims{"iss": testIssuer, "exp": inAnHour}, testSecret, false},
+	}
+	for _, token := range tokens {
+		t.Run(token.name, func(t *testing.T) {
+			user, err := authenticator.Verify(context.Background(), signTestToken(t, token.claims, token.secret))
+
+			if !token.valid {
+				if err == nil {
+					t.Fatalf("expected the token to be rejected, got user %+v", user)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if user.UserId != "user-1" {
+				t.Fatalf("expected user-1, got %s", user.UserId)
+			}
+		})
+	}
+}
+
+func TestJwtAuthenticatorRejectsUnsignedTokens(t *testing.T) {
+	authenticator, err := NewJwtAuthenticator(JwtConfig{Secret: testSecret})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{"sub": "user-1", "exp": time.Now().Add(time.Hour).Unix()}).
+		SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = authenticator.Verify(context.Background(), token)
+
+	if err == nil {
+		t.Fatal("expected the unsigned token to be rejected")
+	}
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"food-track-be/service"
+	"github.com/gin-gonic/gin"
+	"time"
+)
+
+const (
+	locationKey = "location"
+	// TimezoneHeader is the header with the IANA timezone of the request.
+	TimezoneHeader = "X-Timezone"
+)
+
+// TimezoneMiddleware resolves the timezone in which the dates of the request are interpreted and the days start and end.
+type TimezoneMiddleware struct {
+	userProfileService *service.UserProfileService
+}
+
+func NewTimezoneMiddleware(userProfileService *service.UserProfileService) *TimezoneMiddleware {
+	return &TimezoneMiddleware{userProfileService: userProfileService}
+}
+
+// Handle stores in the request context, where handlers read it with GetLocation, the timezone of the tz query
+// parameter or, if missing, of the X-Timezone header; requests with neither use the timezone of the user profile. It
+// must run after the AuthMiddleware. Requests with an unknown timezone are aborted with a Validation error.
+func (m *TimezoneMiddleware) Handle(c *gin.Context) {
+	timezone := c.Query("tz")
+	if timezone == "" {
+		timezone = c.GetHeader(TimezoneHeader)
+	}
+	var location *time.Location
+	var err error
+	if timezone != "" {
+		location, err = service.LoadLocation(timezone)
+	} else {
+		location, err = m.userProfileService.GetLocation(GetUserId(c))
+	}
+	if err != nil {
+		_ = c.Error(err)
+		c.Abort()
+		return
+	}
+	c.Set(locationKey, location)
+	c.Next()
+}
+
+// GetLocation returns the timezone of the request, UTC if it hasn't been resolved.
+func GetLocation(c *gin.Context) *time.Location {
+	if location, ok := c.Get(locationKey); ok {
+		return location.(*time.Location)
+	}
+	return time.UTC
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"food-track-be/repository"
+	"food-track-be/service"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newTimezoneTestRouter routes a request of user-1 through the TimezoneMiddleware to a handler answering the name of
+// the resolved timezone, the user profile being read from a mocked database.
+func newTimezoneTestRouter(t *testing.T) (*gin.Engine, sqlmock.Sqlmock) {
+	sqlDb, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := bun.NewDB(sqlDb, pgdialect.New())
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	tm := NewTimezoneMiddleware(service.NewUserProfileService(repository.NewUserProfileRepository(*db)))
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ErrorHandler)
+	r.GET("/", func(c *gin.Context) {
+		c.Set(userIdKey, "user-1")
+	}, tm.Handle, func(c *gin.Context) {
+		c.String(http.StatusOK, GetLocation(c).String())
+	})
+	return r, mock
+}
+
+func TestTimezoneMiddlewareHandle(t *testing.T) {
+	requests := []struct {
+		name   string
+		path   string
+		header string
+		// profile is whether the timezone is read from the user profile, which the user hasn't configured.
+		profile  bool
+		status   int
+		location string
+	}{
+		{"header", "/", "Europe/Rome", false, http.StatusOK, "Europe/Rome"},
+		{"query parameter over header", "/?tz=America/New_York", "Europe/Rome", false, http.StatusOK, "America/New_York"},
+		{"invalid header", "/", "Mars/Olympus_Mons", false, http.StatusBadRequest, ""},
+		{"local timezone of the server", "/", "Local", false, http.StatusBadRequest, ""},
+		{"missing", "/", "", true, http.StatusOK, "UTC"},
+	}
+	for _, request := range requests {
+		t.Run(request.name, func(t *testing.T) {
+			r, mock := newTimezoneTestRouter(t)
+			if request.profile {
+				mock.ExpectQuery(`FROM "user_profile" AS "up" WHERE \(user_id = 'user-1'\)`).WillReturnRows(sqlmock.NewRows([]string{"user_id", "timezone"}))
+			}
+			httpRequest := httptest.NewRequest(http.MethodGet, request.path, nil)
+			if request.header != "" {
+				httpRequest.Header.Set(TimezoneHeader, request.header)
+			}
+			recorder := httptest.NewRecorder()
+
+			r.ServeHTTP(recorder, httpRequest)
+
+			if recorder.Code != request.status {
+				t.Fatalf("expected status %d, got %d: %s", request.status, recorder.Code, recorder.Body.String())
+			}
+			if request.status == http.StatusOK && recorder.Body.String() != request.location {
+				t.Fatalf("expected the timezone %s, got %s", request.location, recorder.Body.String())
+			}
+			// The user profile isn't read when the request has a timezone: the mock fails any query which isn't expected.
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS meal
+(
+    id          uuid primary key,
+    user_id     varchar(255) not null,
+    name        varchar(255) not null,
+    description varchar(255),
+    meal_type   varchar(30)  not null,
+    date        timestamp    not null
+);
+
+--bun:split
+
+-- Early deployments created the column as a plain date, losing the time of the meal.
+ALTER TABLE meal ALTER COLUMN date TYPE timestamp;
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS food_consumption
+(
+    id                uuid primary key,
+    meal_id           uuid         not null,
+    food_id           uuid         not null,
+    transaction_id    uuid         not null,
+    food_name         varchar(255) not null,
+    quantity_used     float        not null,
+    quantity_used_std float        not null,
+    unit              varchar(255) not null,
+    kcal              float        not null,
+    cost              float        not null,
+    foreign key (meal_id) references meal (id)
+);
+
+--bun:split
+
+CREATE INDEX IF NOT EXISTS food_consumption_meal_id_idx ON food_consumption (meal_id);
@@ -0,0 +1,7 @@
+ALTER TABLE food_consumption
+    DROP COLUMN IF EXISTS protein,
+    DROP COLUMN IF EXISTS carbohydrate,
+    DROP COLUMN IF EXISTS fat,
+    DROP COLUMN IF EXISTS fiber,
+    DROP COLUMN IF EXISTS sugar,
+    DROP COLUMN IF EXISTS sodium;
@@ -0,0 +1,7 @@
+ALTER TABLE food_consumption
+    ADD COLUMN IF NOT EXISTS protein      float not null default 0,
+    ADD COLUMN IF NOT EXISTS carbohydrate float not null default 0,
+    ADD COLUMN IF NOT EXISTS fat          float not null default 0,
+    ADD COLUMN IF NOT EXISTS fiber        float not null default 0,
+    ADD COLUMN IF NOT EXISTS sugar        float not null default 0,
+    ADD COLUMN IF NOT EXISTS sodium       float not null default 0;
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS goal
+(
+    id           uuid primary key,
+    user_id      varchar(255) not null unique,
+    kcal         float        not null,
+    protein      float        not null,
+    carbohydrate float        not null,
+    fat          float        not null,
+    budget       float        not null
+);
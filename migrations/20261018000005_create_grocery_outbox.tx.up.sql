@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS grocery_outbox
+(
+    id                  uuid primary key,
+    saga_id             uuid        not null,
+    food_consumption_id uuid        not null,
+    food_id             uuid        not null,
+    transaction_id      uuid        not null,
+    quantity_delta      float       not null,
+    previous            jsonb,
+    is_compensation     boolean     not null default false,
+    status              varchar(30) not null,
+    attempts            integer     not null default 0,
+    last_error          text,
+    next_attempt_at     timestamptz not null,
+    created_at          timestamptz not null default current_timestamp
+);
+
+--bun:split
+
+CREATE INDEX IF NOT EXISTS grocery_outbox_status_next_attempt_at_idx ON grocery_outbox (status, next_attempt_at);
+
+--bun:split
+
+CREATE INDEX IF NOT EXISTS grocery_outbox_saga_id_idx ON grocery_outbox (saga_id);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS recipe_ingredient;
+
+--bun:split
+
+DROP TABLE IF EXISTS recipe;
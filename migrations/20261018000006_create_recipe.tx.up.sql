@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS recipe
+(
+    id          uuid primary key,
+    user_id     varchar(255) not null,
+    name        varchar(255) not null,
+    description varchar(255),
+    servings    float        not null
+);
+
+--bun:split
+
+CREATE INDEX IF NOT EXISTS recipe_user_id_idx ON recipe (user_id);
+
+--bun:split
+
+CREATE TABLE IF NOT EXISTS recipe_ingredient
+(
+    id             uuid primary key,
+    recipe_id      uuid         not null,
+    food_id        uuid         not null,
+    transaction_id uuid         not null,
+    food_name      varchar(255) not null,
+    quantity       float        not null,
+    quantity_std   float        not null,
+    unit           varchar(255) not null,
+    kcal           float        not null,
+    protein        float        not null,
+    carbohydrate   float        not null,
+    fat            float        not null,
+    fiber          float        not null,
+    sugar          float        not null,
+    sodium         float        not null,
+    foreign key (recipe_id) references recipe (id) on delete cascade
+);
+
+--bun:split
+
+CREATE INDEX IF NOT EXISTS recipe_ingredient_recipe_id_idx ON recipe_ingredient (recipe_id);
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS meal_user_id_date_idx;
+
+--bun:split
+
+ALTER TABLE meal
+    DROP COLUMN IF EXISTS status,
+    DROP COLUMN IF EXISTS planned,
+    DROP COLUMN IF EXISTS planned_kcal,
+    DROP COLUMN IF EXISTS planned_cost;
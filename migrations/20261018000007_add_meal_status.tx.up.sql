@@ -0,0 +1,9 @@
+ALTER TABLE meal
+    ADD COLUMN IF NOT EXISTS status       varchar(30) not null default 'eaten',
+    ADD COLUMN IF NOT EXISTS planned      boolean     not null default false,
+    ADD COLUMN IF NOT EXISTS planned_kcal float,
+    ADD COLUMN IF NOT EXISTS planned_cost float;
+
+--bun:split
+
+CREATE INDEX IF NOT EXISTS meal_user_id_date_idx ON meal (user_id, date);
@@ -0,0 +1,24 @@
+DROP INDEX IF EXISTS food_consumption_deleted_at_idx;
+
+--bun:split
+
+DROP INDEX IF EXISTS meal_deleted_at_idx;
+
+--bun:split
+
+-- Soft deleted rows would reappear once the column is gone.
+DELETE FROM food_consumption WHERE deleted_at IS NOT NULL OR meal_id IN (SELECT id FROM meal WHERE deleted_at IS NOT NULL);
+
+--bun:split
+
+DELETE FROM meal WHERE deleted_at IS NOT NULL;
+
+--bun:split
+
+ALTER TABLE food_consumption
+    DROP COLUMN IF EXISTS deleted_at;
+
+--bun:split
+
+ALTER TABLE meal
+    DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,15 @@
+ALTER TABLE meal
+    ADD COLUMN IF NOT EXISTS deleted_at timestamptz;
+
+--bun:split
+
+ALTER TABLE food_consumption
+    ADD COLUMN IF NOT EXISTS deleted_at timestamptz;
+
+--bun:split
+
+CREATE INDEX IF NOT EXISTS meal_deleted_at_idx ON meal (deleted_at) WHERE deleted_at IS NOT NULL;
+
+--bun:split
+
+CREATE INDEX IF NOT EXISTS food_consumption_deleted_at_idx ON food_consumption (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE meal
+    DROP COLUMN IF EXISTS stock_restored;
@@ -0,0 +1,2 @@
+ALTER TABLE meal
+    ADD COLUMN IF NOT EXISTS stock_restored boolean not null default false;
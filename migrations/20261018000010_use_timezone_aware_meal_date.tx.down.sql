@@ -0,0 +1 @@
+ALTER TABLE meal ALTER COLUMN date TYPE timestamp USING date AT TIME ZONE 'UTC';
@@ -0,0 +1,2 @@
+-- Meal dates were stored as naive UTC timestamps.
+ALTER TABLE meal ALTER COLUMN date TYPE timestamptz USING date AT TIME ZONE 'UTC';
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS user_profile
+(
+    user_id  varchar(255) primary key,
+    timezone varchar(64) not null
+);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS food_piece_weight;
+
+--bun:split
+
+ALTER TABLE food_consumption
+    DROP COLUMN IF EXISTS unit_std;
@@ -0,0 +1,31 @@
+ALTER TABLE food_consumption
+    ADD COLUMN IF NOT EXISTS unit_std varchar(8) not null default 'g';
+
+--bun:split
+
+UPDATE food_consumption
+SET unit_std          = CASE WHEN unit IN ('ml', 'cl', 'l', 'cup', 'tbsp', 'tsp') THEN 'ml' ELSE 'g' END,
+    quantity_used_std = CASE unit
+                            WHEN 'mg' THEN quantity_used * 0.001
+                            WHEN 'g' THEN quantity_used
+                            WHEN 'kg' THEN quantity_used * 1000
+                            WHEN 'oz' THEN quantity_used * 28.349523
+                            WHEN 'lb' THEN quantity_used * 453.59237
+                            WHEN 'ml' THEN quantity_used
+                            WHEN 'cl' THEN quantity_used * 10
+                            WHEN 'l' THEN quantity_used * 1000
+                            WHEN 'cup' THEN quantity_used * 240
+                            WHEN 'tbsp' THEN quantity_used * 15
+                            WHEN 'tsp' THEN quantity_used * 5
+                            ELSE quantity_used_std
+        END;
+
+--bun:split
+
+CREATE TABLE IF NOT EXISTS food_piece_weight
+(
+    user_id   varchar(255) not null,
+    food_name varchar(255) not null,
+    grams     float        not null,
+    primary key (user_id, food_name)
+);
@@ -0,0 +1,6 @@
+ALTER TABLE food_consumption
+    DROP COLUMN IF EXISTS catalog_food_id;
+
+--bun:split
+
+DROP TABLE IF EXISTS catalog_food;
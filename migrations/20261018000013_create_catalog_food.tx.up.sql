@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS catalog_food
+(
+    id           uuid primary key,
+    barcode      varchar(64),
+    name         varchar(255) not null,
+    default_unit varchar(8)   not null default 'g',
+    aliases      text[]       not null default '{}',
+    kcal         float        not null default 0,
+    protein      float        not null default 0,
+    carbohydrate float        not null default 0,
+    fat          float        not null default 0,
+    fiber        float        not null default 0,
+    sugar        float        not null default 0,
+    sodium       float        not null default 0
+);
+
+--bun:split
+
+CREATE UNIQUE INDEX IF NOT EXISTS catalog_food_barcode_idx ON catalog_food (barcode);
+
+--bun:split
+
+CREATE INDEX IF NOT EXISTS catalog_food_name_idx ON catalog_food (lower(name));
+
+--bun:split
+
+ALTER TABLE food_consumption
+    ADD COLUMN IF NOT EXISTS catalog_food_id uuid references catalog_food (id) on delete set null;
@@ -0,0 +1,34 @@
+ALTER TABLE grocery_outbox
+    ADD COLUMN IF NOT EXISTS previous jsonb;
+
+--bun:split
+
+ALTER TABLE grocery_outbox
+    DROP COLUMN IF EXISTS user_id;
+
+--bun:split
+
+DROP TABLE IF EXISTS grocery_saga;
+
+--bun:split
+
+DROP TRIGGER IF EXISTS food_consumption_increment_version ON food_consumption;
+
+--bun:split
+
+DROP TRIGGER IF EXISTS meal_increment_version ON meal;
+
+--bun:split
+
+DROP FUNCTION IF EXISTS increment_version();
+
+--bun:split
+
+ALTER TABLE food_consumption
+    DROP COLUMN IF EXISTS sync_failed,
+    DROP COLUMN IF EXISTS version;
+
+--bun:split
+
+ALTER TABLE meal
+    DROP COLUMN IF EXISTS version;
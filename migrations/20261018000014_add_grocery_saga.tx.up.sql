@@ -0,0 +1,67 @@
+ALTER TABLE meal
+    ADD COLUMN IF NOT EXISTS version integer not null default 0;
+
+--bun:split
+
+ALTER TABLE food_consumption
+    ADD COLUMN IF NOT EXISTS version     integer not null default 0,
+    ADD COLUMN IF NOT EXISTS sync_failed boolean not null default false;
+
+--bun:split
+
+CREATE OR REPLACE FUNCTION increment_version() RETURNS trigger AS
+$$
+BEGIN
+    NEW.version := OLD.version + 1;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+--bun:split
+
+CREATE TRIGGER meal_increment_version
+    BEFORE UPDATE
+    ON meal
+    FOR EACH ROW
+EXECUTE FUNCTION increment_version();
+
+--bun:split
+
+CREATE TRIGGER food_consumption_increment_version
+    BEFORE UPDATE
+    ON food_consumption
+    FOR EACH ROW
+EXECUTE FUNCTION increment_version();
+
+--bun:split
+
+CREATE TABLE IF NOT EXISTS grocery_saga
+(
+    id                uuid primary key,
+    user_id           varchar(255) not null,
+    status            varchar(30)  not null,
+    meals             jsonb        not null,
+    food_consumptions jsonb        not null,
+    created_at        timestamptz  not null default current_timestamp
+);
+
+--bun:split
+
+ALTER TABLE grocery_outbox
+    ADD COLUMN IF NOT EXISTS user_id varchar(255) not null default '';
+
+--bun:split
+
+-- The pending entries are delivered on behalf of the owner of their food consumption.
+UPDATE grocery_outbox AS gro
+SET user_id = m.user_id
+FROM food_consumption AS fc
+         JOIN meal AS m ON m.id = fc.meal_id
+WHERE fc.id = gro.food_consumption_id;
+
+--bun:split
+
+-- The token column only exists in databases migrated by an earlier version of the grocery outbox migration.
+ALTER TABLE grocery_outbox
+    DROP COLUMN IF EXISTS token,
+    DROP COLUMN IF EXISTS previous;
@@ -0,0 +1,10 @@
+UPDATE recipe_ingredient
+SET food_id = '00000000-0000-0000-0000-000000000000'
+WHERE food_id IS NULL;
+
+--bun:split
+
+ALTER TABLE recipe_ingredient
+    DROP COLUMN IF EXISTS catalog_food_id,
+    ALTER COLUMN food_id SET NOT NULL,
+    ADD COLUMN IF NOT EXISTS transaction_id uuid not null default '00000000-0000-0000-0000-000000000000';
@@ -0,0 +1,12 @@
+-- Ingredients referenced grocery-be transactions, which are used up after a while: the transaction is now resolved
+-- whenever the recipe is applied.
+ALTER TABLE recipe_ingredient
+    DROP COLUMN IF EXISTS transaction_id,
+    ALTER COLUMN food_id DROP NOT NULL,
+    ADD COLUMN IF NOT EXISTS catalog_food_id uuid references catalog_food (id) on delete set null;
+
+--bun:split
+
+UPDATE recipe_ingredient
+SET food_id = NULL
+WHERE food_id = '00000000-0000-0000-0000-000000000000';
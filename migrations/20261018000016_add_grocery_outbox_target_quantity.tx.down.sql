@@ -0,0 +1,2 @@
+ALTER TABLE grocery_outbox
+    DROP COLUMN IF EXISTS target_available_quantity;
@@ -0,0 +1,4 @@
+-- The available quantity written to the grocery-be transaction by the delivery of the entry, saved before it is sent so
+-- that a retry after a lost response doesn't apply the change twice.
+ALTER TABLE grocery_outbox
+    ADD COLUMN IF NOT EXISTS target_available_quantity float;
@@ -0,0 +1,104 @@
+package migrations
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/migrate"
+	"log"
+)
+
+// Migrations contains every versioned schema migration, discovered from the embedded SQL files.
+//
+// New migrations are added as `<timestamp>_<name>.tx.up.sql` and `<timestamp>_<name>.tx.down.sql` files in this directory.
+var Migrations = migrate.NewMigrations()
+
+//go:embed *.sql
+var sqlMigrations embed.FS
+
+func init() {
+	if err := Migrations.Discover(sqlMigrations); err != nil {
+		panic(err)
+	}
+}
+
+// Up applies all the pending migrations as a new migration group.
+func Up(ctx context.Context, db *bun.DB) error {
+	migrator, err := newMigrator(ctx, db)
+	if err != nil {
+		return err
+	}
+	if err = migrator.Lock(ctx); err != nil {
+		return err
+	}
+	defer unlock(ctx, migrator)
+
+	group, err := migrator.Migrate(ctx)
+	if err != nil {
+		return err
+	}
+	if group.IsZero() {
+		log.Println("database schema is up to date")
+		return nil
+	}
+	log.Printf("migrated to %s\n", group)
+	return nil
+}
+
+// Down rolls back the last applied migration group.
+func Down(ctx context.Context, db *bun.DB) error {
+	migrator, err := newMigrator(ctx, db)
+	if err != nil {
+		return err
+	}
+	if err = migrator.Lock(ctx); err != nil {
+		return err
+	}
+	defer unlock(ctx, migrator)
+
+	group, err := migrator.Rollback(ctx)
+	if err != nil {
+		return err
+	}
+	if group.IsZero() {
+		log.Println("there are no groups to roll back")
+		return nil
+	}
+	log.Printf("rolled back %s\n", group)
+	return nil
+}
+
+// Status prints every known migration together with its state.
+func Status(ctx context.Context, db *bun.DB) error {
+	migrator, err := newMigrator(ctx, db)
+	if err != nil {
+		return err
+	}
+	ms, err := migrator.MigrationsWithStatus(ctx)
+	if err != nil {
+		return err
+	}
+	for _, m := range ms {
+		state := "pending"
+		if m.IsApplied() {
+			state = fmt.Sprintf("applied (group %d, %s)", m.GroupID, m.MigratedAt.Format("2006-01-02 15:04:05"))
+		}
+		fmt.Printf("%s\t%s\n", m, state)
+	}
+	return nil
+}
+
+func newMigrator(ctx context.Context, db *bun.DB) (*migrate.Migrator, error) {
+	migrator := migrate.NewMigrator(db, Migrations)
+	if err := migrator.Init(ctx); err != nil {
+		return nil, err
+	}
+	return migrator, nil
+}
+
+func unlock(ctx context.Context, migrator *migrate.Migrator) {
+	if err := migrator.Unlock(ctx); err != nil {
+		log.Println(err)
+	}
+}
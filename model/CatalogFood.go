@@ -0,0 +1,24 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// CatalogFood is a food of the local catalog, shared by all the users. The nutrients are per 100 g, or 100 ml for
+// liquids, and Aliases are other names the food is known by.
+type CatalogFood struct {
+	bun.BaseModel `bun:"table:catalog_food,alias:cf"`
+	ID            uuid.UUID `bun:"type:uuid,nullzero,pk"`
+	Barcode       string    `bun:"type:varchar(64),nullzero"`
+	Name          string    `bun:"type:varchar(255),notnull"`
+	DefaultUnit   string    `bun:"type:varchar(8),notnull"`
+	Aliases       []string  `bun:",array"`
+	Kcal          float32   `bun:",notnull"`
+	Protein       float32   `bun:",notnull"`
+	Carbohydrate  float32   `bun:",notnull"`
+	Fat           float32   `bun:",notnull"`
+	Fiber         float32   `bun:",notnull"`
+	Sugar         float32   `bun:",notnull"`
+	Sodium        float32   `bun:",notnull"`
+}
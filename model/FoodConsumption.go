@@ -3,35 +3,35 @@ package model
 import (
 	"github.com/google/uuid"
 	"github.com/uptrace/bun"
+	"time"
 )
 
+// FoodConsumption is a food eaten in a meal. QuantityUsedStd is QuantityUsed converted to UnitStd, grams or millilitres,
+// so that quantities in different units can be compared. Deleted food consumptions stay in the trash, with their
+// DeletedAt set, until they are purged. CatalogFoodId is the food of the catalog the nutrients have been computed from.
+// SyncFailed reports that a change of the food consumption couldn't be applied to the pantry, nor undone. Version is
+// incremented by the database at every update of the row.
 type FoodConsumption struct {
 	bun.BaseModel   `bun:"table:food_consumption,alias:fc"`
 	ID              uuid.UUID `bun:"type:uuid,notnull,pk,default:uuid_generate_v4()"`
 	MealID          uuid.UUID
 	FoodId          uuid.UUID
 	TransactionId   uuid.UUID
+	CatalogFoodId   uuid.UUID `bun:"type:uuid,nullzero"`
 	FoodName        string
 	QuantityUsed    float32
 	QuantityUsedStd float32
 	Unit            string
+	UnitStd         string
 	Kcal            float32
+	Protein         float32
+	Carbohydrate    float32
+	Fat             float32
+	Fiber           float32
+	Sugar           float32
+	Sodium          float32
 	Cost            float32
+	SyncFailed      bool      `bun:",notnull"`
+	Version         int       `bun:",notnull"`
+	DeletedAt       time.Time `bun:",soft_delete,nullzero"`
 }
-
-/*
-DDL for table food_consumption (First create Meal table)
-create table food_consumption (
-id uuid primary key,
-meal_id uuid not null,
-food_id uuid not null,
-transaction_id uuid not null,
-food_name varchar(255) not null,
-quantity_used float not null,
-quantity_used_std float not null,
-unit varchar(255) not null,
-kcal float not null,
-cost float not null,
-foreign key (meal_id) references meal(id)
-);
-*/
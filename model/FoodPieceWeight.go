@@ -0,0 +1,12 @@
+package model
+
+import "github.com/uptrace/bun"
+
+// FoodPieceWeight is the weight, in grams, of a piece of a food as configured by the user. It is used to standardize
+// the quantities of the food consumed in pieces. FoodName is stored lowercase, so that it matches regardless of case.
+type FoodPieceWeight struct {
+	bun.BaseModel `bun:"table:food_piece_weight,alias:fpw"`
+	UserId        string  `bun:"type:varchar(255),pk"`
+	FoodName      string  `bun:"type:varchar(255),pk"`
+	Grams         float32 `bun:",notnull"`
+}
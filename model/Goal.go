@@ -0,0 +1,17 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+type Goal struct {
+	bun.BaseModel `bun:"table:goal,alias:g"`
+	ID            uuid.UUID `bun:"type:uuid,nullzero,pk"`
+	UserId        string    `bun:"type:varchar(255),notnull,unique"`
+	Kcal          float32   `bun:",notnull"`
+	Protein       float32   `bun:",notnull"`
+	Carbohydrate  float32   `bun:",notnull"`
+	Fat           float32   `bun:",notnull"`
+	Budget        float32   `bun:",notnull"`
+}
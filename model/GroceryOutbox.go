@@ -0,0 +1,43 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+	"time"
+)
+
+// GroceryOutbox is a change of the available quantity of a grocery-be transaction.
+//
+// Entries are written in the same database transaction of the food consumption change that caused them and are
+// delivered to grocery-be afterwards, so that the pantry quantities eventually match what was consumed. UserId is the
+// owner of the pantry: the user token is never stored. TargetAvailableQuantity is the available quantity written to the
+// transaction by the last delivery, so that a retry finding it already there doesn't apply the change twice.
+type GroceryOutbox struct {
+	bun.BaseModel           `bun:"table:grocery_outbox,alias:gro"`
+	ID                      uuid.UUID           `bun:"type:uuid,nullzero,pk"`
+	SagaId                  uuid.UUID           `bun:"type:uuid,notnull"`
+	UserId                  string              `bun:"type:varchar(255),notnull"`
+	FoodConsumptionId       uuid.UUID           `bun:"type:uuid,notnull"`
+	FoodId                  uuid.UUID           `bun:"type:uuid,notnull"`
+	TransactionId           uuid.UUID           `bun:"type:uuid,notnull"`
+	QuantityDelta           float32             `bun:",notnull"`
+	TargetAvailableQuantity *float32            `bun:"type:float"`
+	IsCompensation          bool                `bun:",notnull"`
+	Status                  GroceryOutboxStatus `bun:"type:varchar(30),notnull"`
+	Attempts                int                 `bun:",notnull"`
+	LastError               string              `bun:"type:text,nullzero"`
+	NextAttemptAt           time.Time           `bun:"type:timestamptz,notnull"`
+	CreatedAt               time.Time           `bun:"type:timestamptz,notnull,default:current_timestamp"`
+}
+
+type GroceryOutboxStatus string
+
+const (
+	OutboxPending     GroceryOutboxStatus = "pending"
+	OutboxDone        GroceryOutboxStatus = "done"
+	OutboxCompensated GroceryOutboxStatus = "compensated"
+	OutboxFailed      GroceryOutboxStatus = "failed"
+	// OutboxDeadLetter is an entry which grocery-be refused for a reason compensation can't fix, like a missing or
+	// rejected credential: it is kept for an operator to deliver again.
+	OutboxDeadLetter GroceryOutboxStatus = "dead_letter"
+)
@@ -0,0 +1,85 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+	"time"
+)
+
+// GrocerySaga is a change of meals and food consumptions together with the grocery outbox entries it requires.
+//
+// The saga keeps every meal and food consumption it changed as it was before the change, nil if the saga created it,
+// and the version the row had right after the change. A saga whose entries can't be delivered is compensated only if
+// none of its rows has changed since, restoring all of them together; otherwise the rows are left as they are and the
+// saga is marked as sync failed.
+type GrocerySaga struct {
+	bun.BaseModel    `bun:"table:grocery_saga,alias:grs"`
+	ID               uuid.UUID              `bun:"type:uuid,nullzero,pk"`
+	UserId           string                 `bun:"type:varchar(255),notnull"`
+	Status           GrocerySagaStatus      `bun:"type:varchar(30),notnull"`
+	Meals            []*SagaMeal            `bun:"type:jsonb,notnull"`
+	FoodConsumptions []*SagaFoodConsumption `bun:"type:jsonb,notnull"`
+	CreatedAt        time.Time              `bun:"type:timestamptz,notnull,default:current_timestamp"`
+	Entries          []*GroceryOutbox       `bun:"rel:has-many,join:id=saga_id"`
+}
+
+// SagaMeal is a meal changed by a saga.
+type SagaMeal struct {
+	ID       uuid.UUID `json:"id"`
+	Previous *Meal     `json:"previous"`
+	Version  int       `json:"version"`
+}
+
+// SagaFoodConsumption is a food consumption changed by a saga.
+type SagaFoodConsumption struct {
+	ID       uuid.UUID        `json:"id"`
+	Previous *FoodConsumption `json:"previous"`
+	Version  int              `json:"version"`
+}
+
+type GrocerySagaStatus string
+
+const (
+	SagaPending     GrocerySagaStatus = "pending"
+	SagaDone        GrocerySagaStatus = "done"
+	SagaCompensated GrocerySagaStatus = "compensated"
+	SagaSyncFailed  GrocerySagaStatus = "sync_failed"
+)
+
+// AddMeal records that the saga changes the meal. previous is the meal before the change, nil if the saga creates it.
+func (s *GrocerySaga) AddMeal(id uuid.UUID, previous *Meal) {
+	var snapshot *Meal
+	if previous != nil {
+		meal := *previous
+		meal.FoodConsumptions = nil
+		snapshot = &meal
+	}
+	s.Meals = append(s.Meals, &SagaMeal{ID: id, Previous: snapshot})
+}
+
+// AddFoodConsumption records that the saga changes the food consumption. previous is the food consumption before the
+// change, nil if the saga creates it.
+func (s *GrocerySaga) AddFoodConsumption(id uuid.UUID, previous *FoodConsumption) {
+	var snapshot *FoodConsumption
+	if previous != nil {
+		foodConsumption := *previous
+		snapshot = &foodConsumption
+	}
+	s.FoodConsumptions = append(s.FoodConsumptions, &SagaFoodConsumption{ID: id, Previous: snapshot})
+}
+
+// AddEntry adds to the saga an outbox entry which removes quantityDelta from the available quantity of the grocery
+// transaction referenced by foodConsumption. A negative quantityDelta gives the quantity back to the transaction.
+func (s *GrocerySaga) AddEntry(foodConsumption *FoodConsumption, quantityDelta float32) {
+	s.Entries = append(s.Entries, &GroceryOutbox{
+		ID:                uuid.New(),
+		SagaId:            s.ID,
+		UserId:            s.UserId,
+		FoodConsumptionId: foodConsumption.ID,
+		FoodId:            foodConsumption.FoodId,
+		TransactionId:     foodConsumption.TransactionId,
+		QuantityDelta:     quantityDelta,
+		Status:            OutboxPending,
+		NextAttemptAt:     time.Now(),
+	})
+}
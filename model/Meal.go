@@ -6,6 +6,13 @@ import (
 	"time"
 )
 
+// Meal is a meal eaten, or planned to be eaten, by the user.
+//
+// Planned reports whether the meal was planned ahead, PlannedKcal and PlannedCost keep its totals at the time it was
+// marked as eaten. Kcal, nutrients and Cost are the totals of the meal food consumptions, filled only by the queries
+// which aggregate them. Deleted meals stay in the trash, with their DeletedAt set, until they are purged; StockRestored
+// reports whether the deletion gave the quantities used by the meal back to the pantry. Version is incremented by the
+// database at every update of the row.
 type Meal struct {
 	bun.BaseModel    `bun:"table:meal,alias:m"`
 	ID               uuid.UUID          `bun:"type:uuid,nullzero,pk"`
@@ -13,8 +20,23 @@ type Meal struct {
 	Name             string             `bun:"type:varchar(255),notnull"`
 	Description      string             `bun:"type:varchar(255),nullzero"`
 	MealType         MealType           `bun:"type:varchar(30),notnull"`
-	Date             time.Time          `bun:"type:timestamp,notnull"`
+	Date             time.Time          `bun:"type:timestamptz,notnull"`
+	Status           MealStatus         `bun:"type:varchar(30),notnull,default:'eaten'"`
+	Planned          bool               `bun:",notnull"`
+	PlannedKcal      float32            `bun:",nullzero"`
+	PlannedCost      float32            `bun:",nullzero"`
+	DeletedAt        time.Time          `bun:",soft_delete,nullzero"`
+	StockRestored    bool               `bun:",notnull"`
+	Version          int                `bun:",notnull"`
 	FoodConsumptions []*FoodConsumption `bun:"rel:has-many,join:id=meal_id"`
+	Kcal             float32            `bun:",scanonly"`
+	Protein          float32            `bun:",scanonly"`
+	Carbohydrate     float32            `bun:",scanonly"`
+	Fat              float32            `bun:",scanonly"`
+	Fiber            float32            `bun:",scanonly"`
+	Sugar            float32            `bun:",scanonly"`
+	Sodium           float32            `bun:",scanonly"`
+	Cost             float32            `bun:",scanonly"`
 	//FoodTypes        []FoodType         `bun:"type:varchar(255)[]"`
 }
 
@@ -27,6 +49,27 @@ const (
 	Others    MealType = "others"
 )
 
+// IsValid reports whether the meal type is one of the known ones.
+func (t MealType) IsValid() bool {
+	switch t {
+	case Breakfast, Lunch, Dinner, Others:
+		return true
+	}
+	return false
+}
+
+type MealStatus string
+
+const (
+	Planned MealStatus = "planned"
+	Eaten   MealStatus = "eaten"
+)
+
+// IsValid reports whether the meal status is one of the known ones.
+func (s MealStatus) IsValid() bool {
+	return s == Planned || s == Eaten
+}
+
 type FoodType string
 
 const (
@@ -36,15 +79,3 @@ const (
 	Bitter   FoodType = "bitter"
 	Beverage FoodType = "beverage"
 )
-
-/*
-DDL for table meal
-create table meal (
-id uuid primary key,
-user_id varchar(255) not null,
-name varchar(255) not null,
-description varchar(255),
-meal_type varchar(255) not null,
-date date not null
-);
-*/
@@ -0,0 +1,16 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+type Recipe struct {
+	bun.BaseModel `bun:"table:recipe,alias:r"`
+	ID            uuid.UUID           `bun:"type:uuid,nullzero,pk"`
+	UserId        string              `bun:"type:varchar(255),notnull"`
+	Name          string              `bun:"type:varchar(255),notnull"`
+	Description   string              `bun:"type:varchar(255),nullzero"`
+	Servings      float32             `bun:",notnull"`
+	Ingredients   []*RecipeIngredient `bun:"rel:has-many,join:id=recipe_id"`
+}
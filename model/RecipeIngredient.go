@@ -0,0 +1,29 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// RecipeIngredient is a food used by a recipe, its quantity and nutrition refer to the whole recipe.
+//
+// The ingredient references the food, of the grocery-be pantry and of the catalog, but not a grocery-be transaction,
+// which is resolved whenever the recipe is applied to a meal since purchases are used up.
+type RecipeIngredient struct {
+	bun.BaseModel `bun:"table:recipe_ingredient,alias:ri"`
+	ID            uuid.UUID `bun:"type:uuid,nullzero,pk"`
+	RecipeID      uuid.UUID `bun:"type:uuid,notnull"`
+	FoodId        uuid.UUID `bun:"type:uuid,nullzero"`
+	CatalogFoodId uuid.UUID `bun:"type:uuid,nullzero"`
+	FoodName      string    `bun:"type:varchar(255),notnull"`
+	Quantity      float32   `bun:",notnull"`
+	QuantityStd   float32   `bun:",notnull"`
+	Unit          string    `bun:"type:varchar(255),notnull"`
+	Kcal          float32   `bun:",notnull"`
+	Protein       float32   `bun:",notnull"`
+	Carbohydrate  float32   `bun:",notnull"`
+	Fat           float32   `bun:",notnull"`
+	Fiber         float32   `bun:",notnull"`
+	Sugar         float32   `bun:",notnull"`
+	Sodium        float32   `bun:",notnull"`
+}
@@ -0,0 +1,69 @@
+package model
+
+// Unit is the unit of measure of a food quantity.
+type Unit string
+
+const (
+	Milligram  Unit = "mg"
+	Gram       Unit = "g"
+	Kilogram   Unit = "kg"
+	Ounce      Unit = "oz"
+	Pound      Unit = "lb"
+	Milliliter Unit = "ml"
+	Centiliter Unit = "cl"
+	Liter      Unit = "l"
+	Cup        Unit = "cup"
+	Tablespoon Unit = "tbsp"
+	Teaspoon   Unit = "tsp"
+	Piece      Unit = "pcs"
+)
+
+// Units are all the known units.
+var Units = []Unit{Milligram, Gram, Kilogram, Ounce, Pound, Milliliter, Centiliter, Liter, Cup, Tablespoon, Teaspoon, Piece}
+
+// unitConversions are the standard unit of every unit which can be converted without knowing the food, with the amount
+// of standard unit in one unit. Pieces are missing, since their weight depends on the food.
+var unitConversions = map[Unit]struct {
+	std    Unit
+	factor float32
+}{
+	Milligram:  {Gram, 0.001},
+	Gram:       {Gram, 1},
+	Kilogram:   {Gram, 1000},
+	Ounce:      {Gram, 28.349523},
+	Pound:      {Gram, 453.59237},
+	Milliliter: {Milliliter, 1},
+	Centiliter: {Milliliter, 10},
+	Liter:      {Milliliter, 1000},
+	Cup:        {Milliliter, 240},
+	Tablespoon: {Milliliter, 15},
+	Teaspoon:   {Milliliter, 5},
+}
+
+// IsValid reports whether the unit is one of the known ones.
+func (u Unit) IsValid() bool {
+	for _, unit := range Units {
+		if u == unit {
+			return true
+		}
+	}
+	return false
+}
+
+// StdUnit returns the unit quantities are standardized to: grams for masses and pieces, millilitres for volumes.
+func (u Unit) StdUnit() Unit {
+	if conversion, ok := unitConversions[u]; ok {
+		return conversion.std
+	}
+	return Gram
+}
+
+// ToStd converts the quantity to the standard unit. It returns false for pieces and unknown units, whose conversion
+// depends on the food.
+func (u Unit) ToStd(quantity float32) (float32, bool) {
+	conversion, ok := unitConversions[u]
+	if !ok {
+		return 0, false
+	}
+	return quantity * conversion.factor, true
+}
@@ -0,0 +1,11 @@
+package model
+
+import "github.com/uptrace/bun"
+
+// UserProfile holds the preferences of the user. Timezone is the IANA name of the zone where the user's days start and
+// end.
+type UserProfile struct {
+	bun.BaseModel `bun:"table:user_profile,alias:up"`
+	UserId        string `bun:"type:varchar(255),pk"`
+	Timezone      string `bun:"type:varchar(64),notnull"`
+}
@@ -0,0 +1,11 @@
+package dto
+
+// BarcodeLookupDto is what is known about a scanned barcode: the food of the user's pantry and its available
+// transactions, the food of the catalog with its nutrition data, and a food consumption draft built from them, ready to
+// be submitted to a meal. Food and CatalogFood are missing when the barcode isn't in the pantry or in the catalog.
+type BarcodeLookupDto struct {
+	Food         *FoodAvailableDto     `json:"food"`
+	Transactions []*FoodTransactionDto `json:"transactions"`
+	CatalogFood  *CatalogFoodDto       `json:"catalogFood"`
+	Draft        FoodConsumptionDto    `json:"draft"`
+}
@@ -1,6 +1,7 @@
 package dto
 
 type BaseResponse[B any] struct {
-	Body         B      `json:"body"`
-	ErrorMessage string `json:"errorMessage"`
+	Body         B         `json:"body"`
+	ErrorMessage string    `json:"errorMessage"`
+	Error        *ErrorDto `json:"error,omitempty"`
 }
@@ -0,0 +1,19 @@
+package dto
+
+import "github.com/google/uuid"
+
+// CatalogFoodDto is a food of the catalog. The nutrients are per 100 g, or 100 ml for liquids.
+type CatalogFoodDto struct {
+	ID           uuid.UUID `json:"id,omitempty"`
+	Barcode      string    `json:"barcode" binding:"max=64"`
+	Name         string    `json:"name" binding:"required,max=255"`
+	DefaultUnit  string    `json:"defaultUnit" binding:"omitempty,unit"`
+	Aliases      []string  `json:"aliases" binding:"dive,required,max=255"`
+	Kcal         float32   `json:"kcal" binding:"gte=0"`
+	Protein      float32   `json:"protein" binding:"gte=0"`
+	Carbohydrate float32   `json:"carbohydrate" binding:"gte=0"`
+	Fat          float32   `json:"fat" binding:"gte=0"`
+	Fiber        float32   `json:"fiber" binding:"gte=0"`
+	Sugar        float32   `json:"sugar" binding:"gte=0"`
+	Sodium       float32   `json:"sodium" binding:"gte=0"`
+}
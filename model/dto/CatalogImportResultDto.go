@@ -0,0 +1,9 @@
+package dto
+
+// CatalogImportResultDto reports the rows of a food dump read by the catalog import: the foods inserted or updated and
+// the rows skipped because they can't be parsed or miss the barcode, the name or the energy.
+type CatalogImportResultDto struct {
+	Rows     int `json:"rows"`
+	Imported int `json:"imported"`
+	Skipped  int `json:"skipped"`
+}
@@ -0,0 +1,9 @@
+package dto
+
+type ConsumptionSumDto struct {
+	Kcal         float64 `json:"kcal"`
+	Protein      float64 `json:"protein"`
+	Carbohydrate float64 `json:"carbohydrate"`
+	Fat          float64 `json:"fat"`
+	Cost         float64 `json:"cost"`
+}
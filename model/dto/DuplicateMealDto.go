@@ -0,0 +1,9 @@
+package dto
+
+import "time"
+
+// DuplicateMealDto is the request to copy a meal, with its food consumptions, to the day of Date and of each of Dates.
+type DuplicateMealDto struct {
+	Date  *time.Time  `json:"date"`
+	Dates []time.Time `json:"dates" binding:"max=31"`
+}
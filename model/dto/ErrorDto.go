@@ -0,0 +1,15 @@
+package dto
+
+// ErrorDto describes why a request failed. Code is the kind of the error, e.g. NOT_FOUND or VALIDATION, Fields lists the
+// invalid fields of the request body.
+type ErrorDto struct {
+	Code    string          `json:"code"`
+	Message string          `json:"message"`
+	Fields  []FieldErrorDto `json:"fields,omitempty"`
+}
+
+// FieldErrorDto is an invalid field of the request body, Field is its JSON name.
+type FieldErrorDto struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
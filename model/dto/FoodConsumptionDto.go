@@ -2,15 +2,26 @@ package dto
 
 import "github.com/google/uuid"
 
+// FoodConsumptionDto is a food eaten in a meal. SyncFailed, set by the server, reports that a change of the food
+// consumption couldn't be applied to the pantry nor undone, so that the pantry must be corrected by hand.
 type FoodConsumptionDto struct {
 	ID              uuid.UUID `json:"id"`
 	MealID          uuid.UUID `json:"mealId"`
 	FoodId          uuid.UUID `json:"foodId"`
 	TransactionId   uuid.UUID `json:"transactionId"`
-	FoodName        string    `json:"foodName"`
-	QuantityUsed    float32   `json:"quantityUsed"`
-	QuantityUsedStd float32   `json:"quantityUsedStd"`
-	Unit            string    `json:"unit"`
-	Kcal            float32   `json:"kcal"`
-	Cost            float32   `json:"cost"`
+	CatalogFoodId   uuid.UUID `json:"catalogFoodId"`
+	FoodName        string    `json:"foodName" binding:"required,max=255"`
+	QuantityUsed    float32   `json:"quantityUsed" binding:"gt=0"`
+	QuantityUsedStd float32   `json:"quantityUsedStd" binding:"gte=0"`
+	Unit            string    `json:"unit" binding:"required,unit"`
+	UnitStd         string    `json:"unitStd"`
+	Kcal            float32   `json:"kcal" binding:"gte=0"`
+	Protein         float32   `json:"protein" binding:"gte=0"`
+	Carbohydrate    float32   `json:"carbohydrate" binding:"gte=0"`
+	Fat             float32   `json:"fat" binding:"gte=0"`
+	Fiber           float32   `json:"fiber" binding:"gte=0"`
+	Sugar           float32   `json:"sugar" binding:"gte=0"`
+	Sodium          float32   `json:"sodium" binding:"gte=0"`
+	Cost            float32   `json:"cost" binding:"gte=0"`
+	SyncFailed      bool      `json:"syncFailed"`
 }
@@ -4,6 +4,8 @@ import (
 	"github.com/google/uuid"
 )
 
+// FoodTransactionDto is a purchase of a food in grocery-be. ExpirationDate is kept as sent by grocery-be, whose layout
+// isn't fixed, and parsed where needed.
 type FoodTransactionDto struct {
 	ID                uuid.UUID `json:"id,omitempty"`
 	Vendor            string    `json:"vendor"`
@@ -11,5 +13,5 @@ type FoodTransactionDto struct {
 	AvailableQuantity float32   `json:"availableQuantity"`
 	Unit              string    `json:"unit"`
 	Price             float32   `json:"price"`
-	//ExpirationDate    time.Time `json:"expirationDate"`
+	ExpirationDate    string    `json:"expirationDate,omitempty"`
 }
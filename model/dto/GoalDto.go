@@ -0,0 +1,13 @@
+package dto
+
+import "github.com/google/uuid"
+
+type GoalDto struct {
+	ID           uuid.UUID `json:"id,omitempty"`
+	UserId       string    `json:"userId,omitempty"`
+	Kcal         float32   `json:"kcal"`
+	Protein      float32   `json:"protein"`
+	Carbohydrate float32   `json:"carbohydrate"`
+	Fat          float32   `json:"fat"`
+	Budget       float32   `json:"budget"`
+}
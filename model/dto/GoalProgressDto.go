@@ -0,0 +1,20 @@
+package dto
+
+import "time"
+
+// NutrientProgressDto reports how much of a daily target has been consumed.
+// Remaining is negative when the target has been exceeded.
+type NutrientProgressDto struct {
+	Target    float64 `json:"target"`
+	Consumed  float64 `json:"consumed"`
+	Remaining float64 `json:"remaining"`
+}
+
+type GoalProgressDto struct {
+	Date         time.Time           `json:"date"`
+	Kcal         NutrientProgressDto `json:"kcal"`
+	Protein      NutrientProgressDto `json:"protein"`
+	Carbohydrate NutrientProgressDto `json:"carbohydrate"`
+	Fat          NutrientProgressDto `json:"fat"`
+	Budget       NutrientProgressDto `json:"budget"`
+}
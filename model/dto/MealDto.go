@@ -7,13 +7,20 @@ import (
 )
 
 type MealDto struct {
-	ID          uuid.UUID      `json:"id,omitempty"`
-	UserId      string         `json:"userId,omitempty"`
-	Name        string         `json:"name"`
-	Description string         `json:"description"`
-	MealType    model.MealType `json:"mealType"`
-	Date        time.Time      `json:"date"`
-	Kcal        float32        `json:"kcal"`
-	Cost        float32        `json:"cost"`
+	ID           uuid.UUID        `json:"id,omitempty"`
+	UserId       string           `json:"userId,omitempty"`
+	Name         string           `json:"name" binding:"required,max=255"`
+	Description  string           `json:"description" binding:"max=255"`
+	MealType     model.MealType   `json:"mealType" binding:"required,mealtype"`
+	Date         time.Time        `json:"date" binding:"required"`
+	Status       model.MealStatus `json:"status" binding:"omitempty,mealstatus"`
+	Kcal         float32          `json:"kcal"`
+	Protein      float32          `json:"protein"`
+	Carbohydrate float32          `json:"carbohydrate"`
+	Fat          float32          `json:"fat"`
+	Fiber        float32          `json:"fiber"`
+	Sugar        float32          `json:"sugar"`
+	Sodium       float32          `json:"sodium"`
+	Cost         float32          `json:"cost"`
 	//FoodTypes   []string  `json:"foodTypes"`
 }
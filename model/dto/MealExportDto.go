@@ -0,0 +1,14 @@
+package dto
+
+// MealExportCsvHeader is the header of the meal CSV export, which has a row for every food consumption of the meals.
+var MealExportCsvHeader = []string{
+	"mealId", "name", "description", "mealType", "date", "status",
+	"foodConsumptionId", "foodId", "transactionId", "foodName", "quantityUsed", "quantityUsedStd", "unit", "unitStd",
+	"kcal", "protein", "carbohydrate", "fat", "fiber", "sugar", "sodium", "cost",
+}
+
+// MealExportDto is a meal of the export, together with its food consumptions.
+type MealExportDto struct {
+	MealDto
+	FoodConsumptions []FoodConsumptionDto `json:"foodConsumptions"`
+}
@@ -0,0 +1,17 @@
+package dto
+
+// MealImportErrorDto is an invalid row of the import. Row is the line of the CSV file, or the position of the meal in
+// the JSON array, starting from 1.
+type MealImportErrorDto struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
+// MealImportResultDto reports the meals and food consumptions imported or, in dry-run mode, which would be imported.
+// Nothing is imported when Errors isn't empty.
+type MealImportResultDto struct {
+	DryRun           bool                 `json:"dryRun"`
+	Meals            int                  `json:"meals"`
+	FoodConsumptions int                  `json:"foodConsumptions"`
+	Errors           []MealImportErrorDto `json:"errors"`
+}
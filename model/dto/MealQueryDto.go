@@ -0,0 +1,29 @@
+package dto
+
+import (
+	"food-track-be/model"
+	"github.com/google/uuid"
+	"time"
+)
+
+// MealQueryDto holds the filters, the sort order and the pagination of the meal listing.
+type MealQueryDto struct {
+	StartRange *time.Time
+	EndRange   *time.Time
+	Status     model.MealStatus
+	MealType   model.MealType
+	Name       string
+	MinKcal    *float32
+	MaxKcal    *float32
+	MinCost    *float32
+	MaxCost    *float32
+	Ascending  bool
+	Limit      int
+	Cursor     string
+}
+
+// MealCursorDto is the position of the last meal of a page, meals are sorted by date and id.
+type MealCursorDto struct {
+	Date time.Time `json:"date"`
+	ID   uuid.UUID `json:"id"`
+}
@@ -0,0 +1,53 @@
+package dto
+
+import (
+	"food-track-be/model"
+	"time"
+)
+
+// TimeseriesGranularity is the size of the buckets of a time series.
+type TimeseriesGranularity string
+
+const (
+	Day   TimeseriesGranularity = "day"
+	Week  TimeseriesGranularity = "week"
+	Month TimeseriesGranularity = "month"
+)
+
+// IsValid reports whether the granularity is one of the known ones.
+func (g TimeseriesGranularity) IsValid() bool {
+	return g == Day || g == Week || g == Month
+}
+
+// MealTimeseriesBucketDto is the aggregate of the meals of a type eaten in the bucket starting at Bucket.
+type MealTimeseriesBucketDto struct {
+	Bucket    time.Time
+	MealType  model.MealType
+	MealCount int
+	Kcal      float64
+	Cost      float64
+}
+
+// TimeseriesPointDto reports the kcal, cost and number of the meals eaten in the bucket starting at Date.
+type TimeseriesPointDto struct {
+	Date      time.Time `json:"date"`
+	Kcal      float64   `json:"kcal"`
+	Cost      float64   `json:"cost"`
+	MealCount int       `json:"mealCount"`
+}
+
+// MealTypeTimeseriesDto is the time series of the meals of a type.
+type MealTypeTimeseriesDto struct {
+	MealType model.MealType       `json:"mealType"`
+	Points   []TimeseriesPointDto `json:"points"`
+}
+
+// MealTimeseriesDto is the time series of the meals eaten in the date range, with a point for every bucket, also the
+// empty ones. Total sums all the meal types, PerMealType has a series for each of them.
+type MealTimeseriesDto struct {
+	Granularity TimeseriesGranularity   `json:"granularity"`
+	StartRange  time.Time               `json:"startRange"`
+	EndRange    time.Time               `json:"endRange"`
+	Total       []TimeseriesPointDto    `json:"total"`
+	PerMealType []MealTypeTimeseriesDto `json:"perMealType"`
+}
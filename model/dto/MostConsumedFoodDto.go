@@ -2,10 +2,14 @@ package dto
 
 import "github.com/google/uuid"
 
+// MostConsumedFoodDto is the food consumed the most in a date range, ranked by QuantityUsedStd, the quantity in the
+// standard unit UnitStd, grams or millilitres. QuantityUsed and Unit are the quantity in the unit the food was consumed
+// in; they are 0 and empty when the food was consumed in different units, which can't be summed.
 type MostConsumedFoodDto struct {
-	FoodId          uuid.UUID 		`json:"foodId"`
-	FoodName        string 			`json:"foodName"`
-	QuantityUsed    float32 		`json:"quantityUsed"`
-	QuantityUsedStd float32 		`json:"quantityUsedStd"`
-	Unit            string 			`json:"unit"`
+	FoodId          uuid.UUID `json:"foodId"`
+	FoodName        string    `json:"foodName"`
+	QuantityUsed    float32   `json:"quantityUsed"`
+	QuantityUsedStd float32   `json:"quantityUsedStd"`
+	Unit            string    `json:"unit"`
+	UnitStd         string    `json:"unitStd"`
 }
@@ -0,0 +1,11 @@
+package dto
+
+type NutritionDto struct {
+	Kcal         float32 `json:"kcal"`
+	Protein      float32 `json:"protein"`
+	Carbohydrate float32 `json:"carbohydrate"`
+	Fat          float32 `json:"fat"`
+	Fiber        float32 `json:"fiber"`
+	Sugar        float32 `json:"sugar"`
+	Sodium       float32 `json:"sodium"`
+}
@@ -0,0 +1,7 @@
+package dto
+
+// PageDto is a page of a cursor paginated listing. NextCursor is empty when there are no more pages.
+type PageDto[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"nextCursor"`
+}
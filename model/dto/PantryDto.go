@@ -0,0 +1,19 @@
+package dto
+
+// PantryFoodDto is a food of the pantry in grocery-be. RemainingPercentage is the available quantity as a percentage of
+// the quantity bought.
+type PantryFoodDto struct {
+	FoodAvailableDto
+	RemainingPercentage float32 `json:"remainingPercentage"`
+}
+
+// PantryTransactionDto is a transaction of a food of the pantry. PricePerUnit is the price of a unit of the quantity
+// bought and RemainingValue the price of the available quantity. DaysUntilExpiry is missing when grocery-be doesn't
+// provide the expiration date, and negative for expired transactions.
+type PantryTransactionDto struct {
+	FoodTransactionDto
+	PricePerUnit    float32 `json:"pricePerUnit"`
+	RemainingValue  float32 `json:"remainingValue"`
+	DaysUntilExpiry *int    `json:"daysUntilExpiry"`
+	Expired         bool    `json:"expired"`
+}
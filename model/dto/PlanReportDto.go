@@ -0,0 +1,19 @@
+package dto
+
+import "time"
+
+// PlanTotalsDto reports the kcal and cost of a set of meals.
+type PlanTotalsDto struct {
+	Kcal float64 `json:"kcal"`
+	Cost float64 `json:"cost"`
+}
+
+// PlanReportDto compares the meals planned in the date range with the ones actually eaten.
+// Difference is Actual minus Planned.
+type PlanReportDto struct {
+	StartRange time.Time     `json:"startRange"`
+	EndRange   time.Time     `json:"endRange"`
+	Planned    PlanTotalsDto `json:"planned"`
+	Actual     PlanTotalsDto `json:"actual"`
+	Difference PlanTotalsDto `json:"difference"`
+}
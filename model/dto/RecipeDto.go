@@ -0,0 +1,36 @@
+package dto
+
+import "github.com/google/uuid"
+
+type RecipeDto struct {
+	ID          uuid.UUID             `json:"id,omitempty"`
+	UserId      string                `json:"userId,omitempty"`
+	Name        string                `json:"name"`
+	Description string                `json:"description"`
+	Servings    float32               `json:"servings"`
+	Ingredients []RecipeIngredientDto `json:"ingredients"`
+	PerServing  NutritionDto          `json:"perServing"`
+}
+
+type RecipeIngredientDto struct {
+	ID            uuid.UUID `json:"id,omitempty"`
+	FoodId        uuid.UUID `json:"foodId"`
+	CatalogFoodId uuid.UUID `json:"catalogFoodId"`
+	FoodName      string    `json:"foodName"`
+	Quantity      float32   `json:"quantity"`
+	QuantityStd   float32   `json:"quantityStd"`
+	Unit          string    `json:"unit"`
+	Kcal          float32   `json:"kcal"`
+	Protein       float32   `json:"protein"`
+	Carbohydrate  float32   `json:"carbohydrate"`
+	Fat           float32   `json:"fat"`
+	Fiber         float32   `json:"fiber"`
+	Sugar         float32   `json:"sugar"`
+	Sodium        float32   `json:"sodium"`
+}
+
+// ApplyRecipeDto is the request to add the ingredients of a recipe to a meal, scaled to the servings eaten.
+type ApplyRecipeDto struct {
+	MealId   uuid.UUID `json:"mealId"`
+	Servings float32   `json:"servings"`
+}
@@ -0,0 +1,24 @@
+package dto
+
+import "time"
+
+// TrashedMealDto is a meal in the trash. PurgeAt is the time after which it is permanently deleted.
+type TrashedMealDto struct {
+	MealDto
+	DeletedAt time.Time `json:"deletedAt"`
+	PurgeAt   time.Time `json:"purgeAt"`
+}
+
+// TrashedFoodConsumptionDto is a food consumption in the trash, whose meal is not. PurgeAt is the time after which it
+// is permanently deleted.
+type TrashedFoodConsumptionDto struct {
+	FoodConsumptionDto
+	DeletedAt time.Time `json:"deletedAt"`
+	PurgeAt   time.Time `json:"purgeAt"`
+}
+
+// TrashDto lists the meals and food consumptions of the user in the trash.
+type TrashDto struct {
+	Meals            []TrashedMealDto            `json:"meals"`
+	FoodConsumptions []TrashedFoodConsumptionDto `json:"foodConsumptions"`
+}
@@ -0,0 +1,15 @@
+package dto
+
+// UnitDto is a known unit with its standard unit. Factor is the amount of standard unit in one unit; it is missing for
+// pieces, whose weight depends on the food.
+type UnitDto struct {
+	Unit    string   `json:"unit"`
+	UnitStd string   `json:"unitStd"`
+	Factor  *float32 `json:"factor,omitempty"`
+}
+
+// FoodPieceWeightDto is the weight, in grams, of a piece of the food.
+type FoodPieceWeightDto struct {
+	FoodName string  `json:"foodName" binding:"required,max=255"`
+	Grams    float32 `json:"grams" binding:"gt=0"`
+}
@@ -0,0 +1,6 @@
+package dto
+
+type UserProfileDto struct {
+	UserId   string `json:"userId,omitempty"`
+	Timezone string `json:"timezone" binding:"required"`
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"food-track-be/model"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+type CatalogFoodRepository struct {
+	db  bun.DB
+	ctx context.Context
+}
+
+func NewCatalogFoodRepository(db bun.DB) *CatalogFoodRepository {
+	return &CatalogFoodRepository{db: db, ctx: context.Background()}
+}
+
+// Search retrieves up to limit foods whose name or an alias contains the query, or whose barcode is the query. Foods
+// named exactly as the query come first, then the others by name.
+func (r *CatalogFoodRepository) Search(query string, limit int) ([]*model.CatalogFood, error) {
+	var catalogFoods []*model.CatalogFood
+	pattern := "%" + escapeLike(query) + "%"
+	err := r.db.NewSelect().Model(&catalogFoods).
+		WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Where("cf.name ILIKE ?", pattern).
+				WhereOr("cf.barcode = ?", query).
+				WhereOr("EXISTS (SELECT 1 FROM unnest(cf.aliases) AS alias WHERE alias ILIKE ?)", pattern)
+		}).
+		OrderExpr("lower(cf.name) = lower(?) DESC", query).
+		Order("cf.name ASC").
+		Limit(limit).
+		Scan(r.ctx)
+	return catalogFoods, err
+}
+
+// FindById retrieves the food of the catalog.
+func (r *CatalogFoodRepository) FindById(id uuid.UUID) (*model.CatalogFood, error) {
+	var catalogFood model.CatalogFood
+	err := r.db.NewSelect().Model(&catalogFood).Where("cf.id = ?", id).Scan(r.ctx)
+	return &catalogFood, err
+}
+
+// FindByBarcode retrieves the food of the catalog with the barcode.
+func (r *CatalogFoodRepository) FindByBarcode(barcode string) (*model.CatalogFood, error) {
+	var catalogFood model.CatalogFood
+	err := r.db.NewSelect().Model(&catalogFood).Where("cf.barcode = ?", barcode).Scan(r.ctx)
+	return &catalogFood, err
+}
+
+// FindByName retrieves the food of the catalog named as name, or with it among the aliases, regardless of case. Foods
+// matching by name are preferred to the ones matching by alias.
+func (r *CatalogFoodRepository) FindByName(name string) (*model.CatalogFood, error) {
+	var catalogFood model.CatalogFood
+	err := r.db.NewSelect().Model(&catalogFood).
+		WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Where("lower(cf.name) = lower(?)", name).
+				WhereOr("EXISTS (SELECT 1 FROM unnest(cf.aliases) AS alias WHERE lower(alias) = lower(?))", name)
+		}).
+		OrderExpr("lower(cf.name) = lower(?) DESC", name).
+		Order("cf.name ASC").
+		Limit(1).
+		Scan(r.ctx)
+	return &catalogFood, err
+}
+
+func (r *CatalogFoodRepository) Create(catalogFood *model.CatalogFood) (sql.Result, error) {
+	return r.db.NewInsert().Model(catalogFood).Exec(r.ctx)
+}
+
+func (r *CatalogFoodRepository) Update(catalogFood *model.CatalogFood) (sql.Result, error) {
+	return r.db.NewUpdate().Model(catalogFood).WherePK().Exec(r.ctx)
+}
+
+func (r *CatalogFoodRepository) Delete(catalogFood *model.CatalogFood) (sql.Result, error) {
+	return r.db.NewDelete().Model(catalogFood).WherePK().Exec(r.ctx)
+}
+
+// UpsertAllByBarcode inserts the foods or, for the barcodes already in the catalog, overwrites their name and nutrients.
+// The id, the default unit and the aliases of the foods already in the catalog are kept. The barcodes must be unique
+// among the foods.
+func (r *CatalogFoodRepository) UpsertAllByBarcode(catalogFoods []*model.CatalogFood) (sql.Result, error) {
+	return r.db.NewInsert().Model(&catalogFoods).
+		On("CONFLICT (barcode) DO UPDATE").
+		Set("name = EXCLUDED.name").
+		Set("kcal = EXCLUDED.kcal").
+		Set("protein = EXCLUDED.protein").
+		Set("carbohydrate = EXCLUDED.carbohydrate").
+		Set("fat = EXCLUDED.fat").
+		Set("fiber = EXCLUDED.fiber").
+		Set("sugar = EXCLUDED.sugar").
+		Set("sodium = EXCLUDED.sodium").
+		Exec(r.ctx)
+}
@@ -11,12 +11,24 @@ import (
 )
 
 type FoodConsumptionRepository struct {
-	db  bun.DB
+	db  bun.IDB
 	ctx context.Context
 }
 
 func NewFoodConsumptionRepository(db bun.DB) *FoodConsumptionRepository {
-	return &FoodConsumptionRepository{db: db, ctx: context.Background()}
+	return &FoodConsumptionRepository{db: &db, ctx: context.Background()}
+}
+
+// WithTx returns a copy of the repository which executes its queries inside the given transaction.
+func (r *FoodConsumptionRepository) WithTx(tx bun.Tx) *FoodConsumptionRepository {
+	return &FoodConsumptionRepository{db: tx, ctx: r.ctx}
+}
+
+// RunInTx runs the function inside a database transaction, which is committed if the function returns no error and rolled back otherwise.
+func (r *FoodConsumptionRepository) RunInTx(fn func(tx bun.Tx) error) error {
+	return r.db.RunInTx(r.ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		return fn(tx)
+	})
 }
 
 // FindAll retrieves all food consumption records from the database.
@@ -58,6 +70,13 @@ func (r *FoodConsumptionRepository) FindById(id uuid.UUID) (*model.FoodConsumpti
 	return &foodConsumption, err
 }
 
+// CreateAll inserts all the food consumption records into the database.
+func (r *FoodConsumptionRepository) CreateAll(foodConsumptions []*model.FoodConsumption) (sql.Result, error) {
+	// Execute an INSERT statement to insert all the food consumption records as new rows in the database.
+	// The result will be stored in a sql.Result value.
+	return r.db.NewInsert().Model(&foodConsumptions).Exec(r.ctx)
+}
+
 // Create inserts a new food consumption record into the database.
 func (r *FoodConsumptionRepository) Create(foodConsumption *model.FoodConsumption) (sql.Result, error) {
 	// Execute an INSERT statement to insert the foodConsumption struct as a new row in the database.
@@ -65,53 +84,132 @@ func (r *FoodConsumptionRepository) Create(foodConsumption *model.FoodConsumptio
 	return r.db.NewInsert().Model(foodConsumption).Exec(r.ctx)
 }
 
+// Upsert inserts the food consumption record or, if a record with the same ID already exists, overwrites it.
+func (r *FoodConsumptionRepository) Upsert(foodConsumption *model.FoodConsumption) (sql.Result, error) {
+	// Execute an INSERT ... ON CONFLICT statement to insert or overwrite the food consumption record with the specified ID.
+	// The result will be stored in a sql.Result value.
+	return r.db.NewInsert().Model(foodConsumption).On("CONFLICT (id) DO UPDATE").Exec(r.ctx)
+}
+
 func (r *FoodConsumptionRepository) Update(foodConsumption *model.FoodConsumption) (sql.Result, error) {
 	// Execute an UPDATE statement to update the food consumption record with the specified ID in the database.
 	// The result will be stored in a sql.Result value.
 	return r.db.NewUpdate().Model(foodConsumption).Where("id = ?", foodConsumption.ID).Exec(r.ctx)
 }
 
-// Delete deletes an existing food consumption record from the database.
+// DeleteById permanently deletes the food consumption record with the specified ID from the database, without moving
+// it to the trash.
+func (r *FoodConsumptionRepository) DeleteById(id uuid.UUID) (sql.Result, error) {
+	// Execute a DELETE statement to delete the food consumption record with the specified ID from the database.
+	// The result will be stored in a sql.Result value.
+	return r.db.NewDelete().Model(&model.FoodConsumption{}).Where("id = ?", id).ForceDelete().Exec(r.ctx)
+}
+
+// FindVersionsForUpdate retrieves the version of the food consumptions, trashed or not, locking them until the end of
+// the transaction. Food consumptions which don't exist are missing from the result.
+func (r *FoodConsumptionRepository) FindVersionsForUpdate(ids []uuid.UUID) (map[uuid.UUID]int, error) {
+	versions := make(map[uuid.UUID]int, len(ids))
+	if len(ids) == 0 {
+		return versions, nil
+	}
+	var foodConsumptions []*model.FoodConsumption
+	err := r.db.NewSelect().Model(&foodConsumptions).Column("id", "version").WhereAllWithDeleted().Where("id IN (?)", bun.In(ids)).For("UPDATE").Scan(r.ctx)
+	for _, foodConsumption := range foodConsumptions {
+		versions[foodConsumption.ID] = foodConsumption.Version
+	}
+	return versions, err
+}
+
+// MarkSyncFailed flags the food consumption, trashed or not, as out of sync with the pantry.
+func (r *FoodConsumptionRepository) MarkSyncFailed(id uuid.UUID) (sql.Result, error) {
+	return r.db.NewUpdate().Model((*model.FoodConsumption)(nil)).Set("sync_failed = TRUE").WhereAllWithDeleted().Where("id = ?", id).Exec(r.ctx)
+}
+
+// Delete moves an existing food consumption record to the trash.
 func (r *FoodConsumptionRepository) Delete(foodConsumption *model.FoodConsumption) (sql.Result, error) {
 	// Execute a DELETE statement to delete the food consumption record with the specified ID from the database.
 	// The result will be stored in a sql.Result value.
 	return r.db.NewDelete().Model(foodConsumption).Exec(r.ctx)
 }
 
-// DeleteAllFoodConsumptionForMeal deletes all food consumption records for a particular meal from the database.
+// DeleteAllFoodConsumptionForMeal moves to the trash all food consumption records for a particular meal from the database.
 func (r *FoodConsumptionRepository) DeleteAllFoodConsumptionForMeal(mealId uuid.UUID) (sql.Result, error) {
 	// Execute a DELETE statement to delete all food consumption records with the specified meal ID from the database.
 	// The result will be stored in a sql.Result value.
 	return r.db.NewDelete().Model(&model.FoodConsumption{}).Where("meal_id = ?", mealId).Exec(r.ctx)
 }
 
-// DeleteFoodConsumptionForMeal deletes a specific food consumption record for a particular meal from the database.
+// DeleteFoodConsumptionForMeal moves to the trash a specific food consumption record for a particular meal from the database.
 func (r *FoodConsumptionRepository) DeleteFoodConsumptionForMeal(mealId uuid.UUID, foodConsumptionId uuid.UUID) (sql.Result, error) {
 	// Execute a DELETE statement to delete the food consumption record with the specified IDs from the database.
 	// The result will be stored in a sql.Result value.
 	return r.db.NewDelete().Model(&model.FoodConsumption{}).Where("meal_id = ?", mealId).Where("id = ?", foodConsumptionId).Exec(r.ctx)
 }
 
-// GetKcalSumForMeal retrieves the sum of the "kcal" column for all food consumption records belonging to a particular meal from the database.
-func (r *FoodConsumptionRepository) GetKcalSumForMeal(mealId uuid.UUID) (float32, error) {
-	// Declare a variable to store the sum of the "kcal" column.
-	var sum float32
-	// Execute a SELECT statement to retrieve the sum of the "kcal" column for all food consumption records with the specified meal ID.
-	// The sum will be stored in the "sum" variable.
-	err := r.db.NewSelect().ColumnExpr("SUM(kcal)").Table("food_consumption").Where("meal_id = ?", mealId).Scan(r.ctx, &sum)
-	// Return the sum and any error that occurred.
-	return sum, err
+// FindDeletedForUser retrieves the food consumptions in the trash which belong to meals of the user not in the trash,
+// the most recently deleted first.
+func (r *FoodConsumptionRepository) FindDeletedForUser(userId string) ([]*model.FoodConsumption, error) {
+	// Initialize a slice to hold the retrieved food consumption records.
+	var foodConsumptions []*model.FoodConsumption
+
+	// Execute a SELECT statement to retrieve the deleted food consumption records of the user's meals.
+	// The result will be stored in the foodConsumptions slice.
+	err := r.db.NewSelect().
+		Model(&foodConsumptions).
+		WhereDeleted().
+		Where("fc.meal_id IN (SELECT id FROM meal WHERE user_id = ? AND deleted_at IS NULL)", userId).
+		Order("fc.deleted_at DESC").
+		Scan(r.ctx)
+
+	// Return the slice and any error that may have occurred.
+	return foodConsumptions, err
+}
+
+// FindDeletedById retrieves a single food consumption record in the trash based on its ID.
+func (r *FoodConsumptionRepository) FindDeletedById(id uuid.UUID) (*model.FoodConsumption, error) {
+	// Initialize a foodConsumption struct to hold the retrieved food consumption record.
+	var foodConsumption model.FoodConsumption
+
+	// Execute a SELECT statement to retrieve the deleted food consumption record with the specified ID.
+	// The result will be stored in the foodConsumption struct.
+	err := r.db.NewSelect().Model(&foodConsumption).WhereDeleted().Where("id = ?", id).Scan(r.ctx)
+
+	// Return a pointer to the foodConsumption struct and any error that may have occurred.
+	return &foodConsumption, err
 }
 
-// GetCostSumForMeal retrieves the sum of the "cost" column for all food consumption records belonging to a particular meal from the database.
-func (r *FoodConsumptionRepository) GetCostSumForMeal(mealId uuid.UUID) (float32, error) {
-	// Declare a variable to store the sum of the "cost" column.
-	var sum float32
-	// Execute a SELECT statement to retrieve the sum of the "cost" column for all food consumption records with the specified meal ID.
-	// The sum will be stored in the "sum" variable.
-	err := r.db.NewSelect().ColumnExpr("SUM(cost)").Table("food_consumption").Where("meal_id = ?", mealId).Scan(r.ctx, &sum)
-	// Return the sum and any error that occurred.
-	return sum, err
+// FindDeletedWithMeal retrieves the food consumption records of the meal moved to the trash together with it, that is
+// not before the meal itself.
+func (r *FoodConsumptionRepository) FindDeletedWithMeal(meal *model.Meal) ([]*model.FoodConsumption, error) {
+	// Initialize a slice to hold the retrieved food consumption records.
+	var foodConsumptions []*model.FoodConsumption
+
+	// Execute a SELECT statement to retrieve the food consumption records deleted together with the meal.
+	// The result will be stored in the foodConsumptions slice.
+	err := r.db.NewSelect().Model(&foodConsumptions).WhereDeleted().Where("meal_id = ?", meal.ID).Where("deleted_at >= ?", meal.DeletedAt).Scan(r.ctx)
+
+	// Return the slice and any error that may have occurred.
+	return foodConsumptions, err
+}
+
+// Restore moves the food consumption record out of the trash.
+func (r *FoodConsumptionRepository) Restore(foodConsumption *model.FoodConsumption) (sql.Result, error) {
+	// Execute an UPDATE statement to clear the deletion time of the food consumption record.
+	// The result will be stored in a sql.Result value.
+	return r.db.NewUpdate().Model(foodConsumption).WhereDeleted().Set("deleted_at = NULL").Where("id = ?", foodConsumption.ID).Exec(r.ctx)
+}
+
+// PurgeDeletedBefore permanently deletes the food consumption records moved to the trash before the given time,
+// together with the ones of the meals moved to the trash before that time.
+func (r *FoodConsumptionRepository) PurgeDeletedBefore(deletedBefore time.Time) (sql.Result, error) {
+	// Execute a DELETE statement to remove the expired food consumption records from the database.
+	// The result will be stored in a sql.Result value.
+	return r.db.NewDelete().
+		Model(&model.FoodConsumption{}).
+		WhereOr("deleted_at < ?", deletedBefore).
+		WhereOr("meal_id IN (SELECT id FROM meal WHERE deleted_at < ?)", deletedBefore).
+		ForceDelete().
+		Exec(r.ctx)
 }
 
 // GetMostConsumedFoodInDateRange retrieves the food that was consumed the most (by standard quantity used) in a given date range for a particular user from the database.
@@ -123,9 +221,11 @@ func (r *FoodConsumptionRepository) GetMostConsumedFoodInDateRange(startRange ti
 	endRange = setEndOfTheDay(endRange)
 
 	// Define the SELECT statement to retrieve the most consumed food.
-	query := "SELECT food_id as foodId, food_name AS foodName, SUM(quantity_used_std) AS quantityUsedStd, SUM(quantity_used) AS quantityUsed, unit  FROM food_consumption where meal_id in (select id from meal where user_id = ? and date >= ? and date <= ?) group by food_id, food_name, unit order by quantityUsedStd desc limit 1"
+	// The quantities are ranked in the standard unit, so that the same food consumed in different units is aggregated.
+	// The quantity used in the unit of the consumptions is summed only when they all share the same unit.
+	query := "SELECT food_id as foodId, food_name AS foodName, SUM(quantity_used_std) AS quantityUsedStd, unit_std AS unitStd, CASE WHEN COUNT(DISTINCT unit) = 1 THEN SUM(quantity_used) ELSE 0 END AS quantityUsed, CASE WHEN COUNT(DISTINCT unit) = 1 THEN MIN(unit) ELSE '' END AS unit FROM food_consumption where deleted_at is null and meal_id in (select id from meal where user_id = ? and status = ? and deleted_at is null and date >= ? and date <= ?) group by food_id, food_name, unit_std order by quantityUsedStd desc limit 1"
 	// Execute the SELECT statement and scan the result into the "mostConsumedFoodDto" variable.
-	err := r.db.QueryRow(query, userId, startRange, endRange).Scan(&mostConsumedFoodDto.FoodId, &mostConsumedFoodDto.FoodName, &mostConsumedFoodDto.QuantityUsedStd, &mostConsumedFoodDto.QuantityUsed, &mostConsumedFoodDto.Unit)
+	err := r.db.QueryRowContext(r.ctx, query, userId, model.Eaten, startRange, endRange).Scan(&mostConsumedFoodDto.FoodId, &mostConsumedFoodDto.FoodName, &mostConsumedFoodDto.QuantityUsedStd, &mostConsumedFoodDto.UnitStd, &mostConsumedFoodDto.QuantityUsed, &mostConsumedFoodDto.Unit)
 	// Return the most consumed food or any error that occurred.
 	if err != nil {
 		return nil, err
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"food-track-be/model"
+	"github.com/uptrace/bun"
+)
+
+type FoodPieceWeightRepository struct {
+	db  bun.DB
+	ctx context.Context
+}
+
+func NewFoodPieceWeightRepository(db bun.DB) *FoodPieceWeightRepository {
+	return &FoodPieceWeightRepository{db: db, ctx: context.Background()}
+}
+
+// FindAllByUserId retrieves the piece weights configured by the user, ordered by food name.
+func (r *FoodPieceWeightRepository) FindAllByUserId(userId string) ([]*model.FoodPieceWeight, error) {
+	var foodPieceWeights []*model.FoodPieceWeight
+	err := r.db.NewSelect().Model(&foodPieceWeights).Where("user_id = ?", userId).Order("food_name").Scan(r.ctx)
+	return foodPieceWeights, err
+}
+
+// Upsert creates the piece weight of the food or, if it already exists, overwrites it.
+func (r *FoodPieceWeightRepository) Upsert(foodPieceWeight *model.FoodPieceWeight) (sql.Result, error) {
+	return r.db.NewInsert().Model(foodPieceWeight).On("CONFLICT (user_id, food_name) DO UPDATE").Exec(r.ctx)
+}
+
+// Delete deletes the piece weight of the food configured by the user.
+func (r *FoodPieceWeightRepository) Delete(userId string, foodName string) (sql.Result, error) {
+	return r.db.NewDelete().Model((*model.FoodPieceWeight)(nil)).
+		Where("user_id = ?", userId).
+		Where("food_name = ?", foodName).
+		Exec(r.ctx)
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"food-track-be/model"
+	"github.com/uptrace/bun"
+)
+
+type GoalRepository struct {
+	db  bun.DB
+	ctx context.Context
+}
+
+func NewGoalRepository(db bun.DB) *GoalRepository {
+	return &GoalRepository{db: db, ctx: context.Background()}
+}
+
+// FindByUserId retrieves the daily goal configured by the user.
+func (r *GoalRepository) FindByUserId(userId string) (*model.Goal, error) {
+	var goal model.Goal
+	err := r.db.NewSelect().Model(&goal).Where("user_id = ?", userId).Scan(r.ctx)
+	return &goal, err
+}
+
+func (r *GoalRepository) Create(goal *model.Goal) (sql.Result, error) {
+	return r.db.NewInsert().Model(goal).Exec(r.ctx)
+}
+
+func (r *GoalRepository) Update(goal *model.Goal, userId string) (sql.Result, error) {
+	return r.db.NewUpdate().Model(goal).Where("id = ?", goal.ID).Where("user_id = ?", userId).Exec(r.ctx)
+}
+
+func (r *GoalRepository) Delete(goal *model.Goal, userId string) (sql.Result, error) {
+	return r.db.NewDelete().Model(goal).Where("id = ?", goal.ID).Where("user_id = ?", userId).Exec(r.ctx)
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"food-track-be/model"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+	"time"
+)
+
+type GroceryOutboxRepository struct {
+	db  bun.IDB
+	ctx context.Context
+}
+
+func NewGroceryOutboxRepository(db bun.DB) *GroceryOutboxRepository {
+	return &GroceryOutboxRepository{db: &db, ctx: context.Background()}
+}
+
+// WithTx returns a copy of the repository which executes its queries inside the given transaction.
+func (r *GroceryOutboxRepository) WithTx(tx bun.Tx) *GroceryOutboxRepository {
+	return &GroceryOutboxRepository{db: tx, ctx: r.ctx}
+}
+
+// CreateAll inserts the outbox entries into the database.
+func (r *GroceryOutboxRepository) CreateAll(entries []*model.GroceryOutbox) (sql.Result, error) {
+	return r.db.NewInsert().Model(&entries).Exec(r.ctx)
+}
+
+// Update updates an outbox entry which is still pending, so that an entry compensated meanwhile isn't overwritten.
+func (r *GroceryOutboxRepository) Update(entry *model.GroceryOutbox) (sql.Result, error) {
+	return r.db.NewUpdate().Model(entry).WherePK().Where("status = ?", model.OutboxPending).Exec(r.ctx)
+}
+
+// SumQuantityDeltas sums, for each of the food consumptions which has entries, the quantity delta of its entries which
+// are delivered or still to deliver: the quantity the food consumption takes from the pantry.
+func (r *GroceryOutboxRepository) SumQuantityDeltas(foodConsumptionIds []uuid.UUID) (map[uuid.UUID]float32, error) {
+	quantities := make(map[uuid.UUID]float32, len(foodConsumptionIds))
+	if len(foodConsumptionIds) == 0 {
+		return quantities, nil
+	}
+	var entries []*model.GroceryOutbox
+	err := r.db.NewSelect().
+		Model(&entries).
+		ColumnExpr("food_consumption_id").
+		ColumnExpr("SUM(quantity_delta) AS quantity_delta").
+		Where("food_consumption_id IN (?)", bun.In(foodConsumptionIds)).
+		Where("status IN (?)", bun.In([]model.GroceryOutboxStatus{model.OutboxDone, model.OutboxPending})).
+		Group("food_consumption_id").
+		Scan(r.ctx)
+	for _, entry := range entries {
+		quantities[entry.FoodConsumptionId] = entry.QuantityDelta
+	}
+	return quantities, err
+}
+
+// CreateSaga inserts the saga into the database.
+func (r *GroceryOutboxRepository) CreateSaga(saga *model.GrocerySaga) (sql.Result, error) {
+	return r.db.NewInsert().Model(saga).Exec(r.ctx)
+}
+
+// UpdateSagaStatus updates the status of the saga.
+func (r *GroceryOutboxRepository) UpdateSagaStatus(saga *model.GrocerySaga) (sql.Result, error) {
+	return r.db.NewUpdate().Model(saga).Column("status").WherePK().Exec(r.ctx)
+}
+
+// FindSagaByIdForUpdate retrieves the saga, locking it until the end of the transaction.
+func (r *GroceryOutboxRepository) FindSagaByIdForUpdate(id uuid.UUID) (*model.GrocerySaga, error) {
+	var saga model.GrocerySaga
+	err := r.db.NewSelect().Model(&saga).Where("id = ?", id).For("UPDATE").Scan(r.ctx)
+	return &saga, err
+}
+
+// CompleteSaga marks the saga as done if it is pending and none of its entries is pending anymore.
+func (r *GroceryOutboxRepository) CompleteSaga(id uuid.UUID) (sql.Result, error) {
+	pendingEntries := r.db.NewSelect().
+		Model((*model.GroceryOutbox)(nil)).
+		Where("saga_id = ?", id).
+		Where("status = ?", model.OutboxPending)
+	return r.db.NewUpdate().
+		Model((*model.GrocerySaga)(nil)).
+		Set("status = ?", model.SagaDone).
+		Where("id = ?", id).
+		Where("status = ?", model.SagaPending).
+		Where("NOT EXISTS (?)", pendingEntries).
+		Exec(r.ctx)
+}
+
+// FindAllBySagaId retrieves all the outbox entries which belong to the saga.
+func (r *GroceryOutboxRepository) FindAllBySagaId(sagaId uuid.UUID) ([]*model.GroceryOutbox, error) {
+	var entries []*model.GroceryOutbox
+	err := r.db.NewSelect().Model(&entries).Where("saga_id = ?", sagaId).Order("created_at ASC").Scan(r.ctx)
+	return entries, err
+}
+
+// ClaimPending retrieves up to limit pending entries whose next attempt is due, postponing their next attempt by lease.
+//
+// Rows locked by another instance are skipped, so that concurrent workers never process the same entry at once.
+func (r *GroceryOutboxRepository) ClaimPending(limit int, lease time.Duration) ([]*model.GroceryOutbox, error) {
+	return r.claim(r.db.NewSelect().Model((*model.GroceryOutbox)(nil)).Limit(limit), lease)
+}
+
+// ClaimPendingForSaga behaves like ClaimPending but only considers the entries of the given saga.
+func (r *GroceryOutboxRepository) ClaimPendingForSaga(sagaId uuid.UUID, lease time.Duration) ([]*model.GroceryOutbox, error) {
+	return r.claim(r.db.NewSelect().Model((*model.GroceryOutbox)(nil)).Where("saga_id = ?", sagaId), lease)
+}
+
+func (r *GroceryOutboxRepository) claim(query *bun.SelectQuery, lease time.Duration) ([]*model.GroceryOutbox, error) {
+	var entries []*model.GroceryOutbox
+	now := time.Now()
+	subQuery := query.
+		Column("id").
+		Where("status = ?", model.OutboxPending).
+		Where("next_attempt_at <= ?", now).
+		Order("created_at ASC").
+		For("UPDATE SKIP LOCKED")
+	err := r.db.NewUpdate().
+		Model((*model.GroceryOutbox)(nil)).
+		Set("next_attempt_at = ?", now.Add(lease)).
+		Where("id IN (?)", subQuery).
+		Returning("*").
+		Scan(r.ctx, &entries)
+	return entries, err
+}
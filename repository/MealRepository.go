@@ -7,27 +7,129 @@ import (
 	"food-track-be/model/dto"
 	"github.com/google/uuid"
 	"github.com/uptrace/bun"
+	"strings"
 	"time"
 )
 
 type MealRepository struct {
-	db  bun.DB
+	db  bun.IDB
 	ctx context.Context
 }
 
 func NewMealRepository(db bun.DB) *MealRepository {
-	return &MealRepository{db: db, ctx: context.Background()}
+	return &MealRepository{db: &db, ctx: context.Background()}
 }
 
-func (r *MealRepository) FindAll(userId string) ([]*model.Meal, error) {
+// WithTx returns a copy of the repository which executes its queries inside the given transaction.
+func (r *MealRepository) WithTx(tx bun.Tx) *MealRepository {
+	return &MealRepository{db: tx, ctx: r.ctx}
+}
+
+// RunInTx runs the function inside a database transaction, which is committed if the function returns no error and rolled back otherwise.
+func (r *MealRepository) RunInTx(fn func(tx bun.Tx) error) error {
+	return r.db.RunInTx(r.ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		return fn(tx)
+	})
+}
+
+// newSelectWithTotals builds a query selecting the meals together with the totals of their food consumptions,
+// aggregated in the same query to avoid a round trip per meal. Deleted meals and food consumptions are excluded.
+func (r *MealRepository) newSelectWithTotals(model interface{}) *bun.SelectQuery {
+	return r.newSelectWithConsumptionTotals(model, "LEFT JOIN food_consumption AS fc ON fc.meal_id = m.id AND fc.deleted_at IS NULL")
+}
+
+// newSelectWithConsumptionTotals builds a query selecting the meals together with the totals of the food consumptions
+// matched by the join.
+func (r *MealRepository) newSelectWithConsumptionTotals(model interface{}, join string) *bun.SelectQuery {
+	return r.db.NewSelect().
+		Model(model).
+		ColumnExpr("m.*").
+		ColumnExpr("COALESCE(SUM(fc.kcal), 0) AS kcal").
+		ColumnExpr("COALESCE(SUM(fc.protein), 0) AS protein").
+		ColumnExpr("COALESCE(SUM(fc.carbohydrate), 0) AS carbohydrate").
+		ColumnExpr("COALESCE(SUM(fc.fat), 0) AS fat").
+		ColumnExpr("COALESCE(SUM(fc.fiber), 0) AS fiber").
+		ColumnExpr("COALESCE(SUM(fc.sugar), 0) AS sugar").
+		ColumnExpr("COALESCE(SUM(fc.sodium), 0) AS sodium").
+		ColumnExpr("COALESCE(SUM(fc.cost), 0) AS cost").
+		Join(join).
+		Group("m.id")
+}
+
+// FindAll retrieves all the meals of the user matching the query filters, sorted by date and id. The limit and the
+// cursor of the query are ignored.
+func (r *MealRepository) FindAll(userId string, query dto.MealQueryDto) ([]*model.Meal, error) {
+	var meals []*model.Meal
+	q := r.newSelectMatching(&meals, userId, query)
+	if query.Ascending {
+		q = q.Order("m.date ASC", "m.id ASC")
+	} else {
+		q = q.Order("m.date DESC", "m.id DESC")
+	}
+	err := q.Scan(r.ctx)
+	return meals, err
+}
+
+// FindPage retrieves up to query.Limit meals of the user matching the query filters, starting after the cursor.
+//
+// Meals are sorted by date and id, so that the cursor identifies a stable position in the listing.
+func (r *MealRepository) FindPage(userId string, query dto.MealQueryDto, cursor *dto.MealCursorDto) ([]*model.Meal, error) {
 	var meals []*model.Meal
-	err := r.db.NewSelect().Model(&meals).Where("user_id = ?", userId).Scan(r.ctx)
+	q := r.newSelectMatching(&meals, userId, query)
+	if query.Ascending {
+		if cursor != nil {
+			q = q.Where("(m.date, m.id) > (?, ?)", cursor.Date, cursor.ID)
+		}
+		q = q.Order("m.date ASC", "m.id ASC")
+	} else {
+		if cursor != nil {
+			q = q.Where("(m.date, m.id) < (?, ?)", cursor.Date, cursor.ID)
+		}
+		q = q.Order("m.date DESC", "m.id DESC")
+	}
+
+	err := q.Limit(query.Limit).Scan(r.ctx)
 	return meals, err
 }
 
+// newSelectMatching builds a query selecting, with their totals, the meals of the user matching the query filters.
+func (r *MealRepository) newSelectMatching(model interface{}, userId string, query dto.MealQueryDto) *bun.SelectQuery {
+	q := r.newSelectWithTotals(model).Where("m.user_id = ?", userId)
+
+	if query.StartRange != nil {
+		q = q.Where("m.date >= ?", *query.StartRange)
+	}
+	if query.EndRange != nil {
+		q = q.Where("m.date <= ?", setEndOfTheDay(*query.EndRange))
+	}
+	if query.Status != "" {
+		q = q.Where("m.status = ?", query.Status)
+	}
+	if query.MealType != "" {
+		q = q.Where("m.meal_type = ?", query.MealType)
+	}
+	if query.Name != "" {
+		q = q.Where("m.name ILIKE ?", "%"+escapeLike(query.Name)+"%")
+	}
+
+	if query.MinKcal != nil {
+		q = q.Having("COALESCE(SUM(fc.kcal), 0) >= ?", *query.MinKcal)
+	}
+	if query.MaxKcal != nil {
+		q = q.Having("COALESCE(SUM(fc.kcal), 0) <= ?", *query.MaxKcal)
+	}
+	if query.MinCost != nil {
+		q = q.Having("COALESCE(SUM(fc.cost), 0) >= ?", *query.MinCost)
+	}
+	if query.MaxCost != nil {
+		q = q.Having("COALESCE(SUM(fc.cost), 0) <= ?", *query.MaxCost)
+	}
+	return q
+}
+
 func (r *MealRepository) FindByIdAndUserId(id uuid.UUID, userId string) (*model.Meal, error) {
 	var meal model.Meal
-	err := r.db.NewSelect().Model(&meal).Where("id = ?", id).Where("user_id = ?", userId).Scan(r.ctx)
+	err := r.newSelectWithTotals(&meal).Where("m.id = ?", id).Where("m.user_id = ?", userId).Scan(r.ctx)
 	return &meal, err
 }
 
@@ -40,21 +142,78 @@ func (r *MealRepository) Create(meal *model.Meal) (sql.Result, error) {
 	return r.db.NewInsert().Model(meal).Exec(r.ctx)
 }
 
+// CreateAll inserts all the meals in the database.
+func (r *MealRepository) CreateAll(meals []*model.Meal) (sql.Result, error) {
+	return r.db.NewInsert().Model(&meals).Exec(r.ctx)
+}
+
 func (r *MealRepository) Update(meal *model.Meal, userId string) (sql.Result, error) {
 	return r.db.NewUpdate().Model(meal).Where("id = ?", meal.ID).Where("user_id = ?", userId).Exec(r.ctx)
 }
 
+// Upsert inserts the meal or, if a meal with the same ID already exists, overwrites it, trashed or not.
+func (r *MealRepository) Upsert(meal *model.Meal) (sql.Result, error) {
+	return r.db.NewInsert().Model(meal).On("CONFLICT (id) DO UPDATE").Exec(r.ctx)
+}
+
+// DeleteById permanently deletes the meal, without moving it to the trash. Its food consumptions must be deleted first.
+func (r *MealRepository) DeleteById(id uuid.UUID) (sql.Result, error) {
+	return r.db.NewDelete().Model(&model.Meal{}).Where("id = ?", id).ForceDelete().Exec(r.ctx)
+}
+
+// FindVersionsForUpdate retrieves the version of the meals, trashed or not, locking them until the end of the
+// transaction. Meals which don't exist are missing from the result.
+func (r *MealRepository) FindVersionsForUpdate(ids []uuid.UUID) (map[uuid.UUID]int, error) {
+	versions := make(map[uuid.UUID]int, len(ids))
+	if len(ids) == 0 {
+		return versions, nil
+	}
+	var meals []*model.Meal
+	err := r.db.NewSelect().Model(&meals).Column("id", "version").WhereAllWithDeleted().Where("id IN (?)", bun.In(ids)).For("UPDATE").Scan(r.ctx)
+	for _, meal := range meals {
+		versions[meal.ID] = meal.Version
+	}
+	return versions, err
+}
+
+// Delete moves the meal to the trash.
 func (r *MealRepository) Delete(meal *model.Meal, userId string) (sql.Result, error) {
 	return r.db.NewDelete().Model(meal).Where("id = ? ", meal.ID, userId).Where("user_id = ?", userId).Exec(r.ctx)
 }
 
+// FindDeleted retrieves the user's meals in the trash, together with the totals of the food consumptions deleted with
+// them, the most recently deleted first.
+func (r *MealRepository) FindDeleted(userId string) ([]*model.Meal, error) {
+	var meals []*model.Meal
+	err := r.newSelectWithConsumptionTotals(&meals, "LEFT JOIN food_consumption AS fc ON fc.meal_id = m.id AND fc.deleted_at >= m.deleted_at").WhereDeleted().Where("m.user_id = ?", userId).Order("m.deleted_at DESC").Scan(r.ctx)
+	return meals, err
+}
+
+// FindDeletedByIdAndUserId retrieves the user's meal in the trash.
+func (r *MealRepository) FindDeletedByIdAndUserId(id uuid.UUID, userId string) (*model.Meal, error) {
+	var meal model.Meal
+	err := r.db.NewSelect().Model(&meal).WhereDeleted().Where("m.id = ?", id).Where("m.user_id = ?", userId).Scan(r.ctx)
+	return &meal, err
+}
+
+// Restore moves the meal out of the trash.
+func (r *MealRepository) Restore(meal *model.Meal, userId string) (sql.Result, error) {
+	return r.db.NewUpdate().Model(meal).WhereDeleted().Set("deleted_at = NULL").Set("stock_restored = FALSE").Where("id = ?", meal.ID).Where("user_id = ?", userId).Exec(r.ctx)
+}
+
+// PurgeDeletedBefore permanently deletes the meals moved to the trash before the given time. Their food consumptions
+// must be purged first.
+func (r *MealRepository) PurgeDeletedBefore(deletedBefore time.Time) (sql.Result, error) {
+	return r.db.NewDelete().Model(&model.Meal{}).WhereDeleted().Where("deleted_at < ?", deletedBefore).ForceDelete().Exec(r.ctx)
+}
+
 func (r *MealRepository) GetAverageKcalEatenInDateRange(startRange time.Time, endRange time.Time, userId string) (float64, error) {
 	var result float64
 
 	endRange = setEndOfTheDay(endRange)
 
-	queryStr := "SELECT COALESCE(SUM(kcal), 0.0) FROM food_consumption WHERE meal_id IN (SELECT id FROM meal WHERE user_id = ? AND date BETWEEN ? AND ?)"
-	err := r.db.QueryRow(queryStr, userId, startRange, endRange).Scan(&result)
+	queryStr := "SELECT COALESCE(SUM(kcal), 0.0) FROM food_consumption WHERE deleted_at IS NULL AND meal_id IN (SELECT id FROM meal WHERE user_id = ? AND status = ? AND deleted_at IS NULL AND date BETWEEN ? AND ?)"
+	err := r.db.QueryRowContext(r.ctx, queryStr, userId, model.Eaten, startRange, endRange).Scan(&result)
 	if err != nil {
 		return 0, err
 	}
@@ -76,8 +235,8 @@ func (r *MealRepository) GetAverageKcalEatenInDateRangePerMealType(startRange ti
 
 	endRange = setEndOfTheDay(endRange)
 
-	queryStr := "SELECT m.meal_type, COALESCE(SUM(kcal), 0.0) / ? as avg_kcal FROM meal m join food_consumption fc on m.id = fc.meal_id WHERE m.user_id = ? AND date BETWEEN ? AND ? group by m.meal_type"
-	queryResult, err := r.db.Query(queryStr, rangeInDays, userId, startRange, endRange)
+	queryStr := "SELECT m.meal_type, COALESCE(SUM(kcal), 0.0) / ? as avg_kcal FROM meal m join food_consumption fc on m.id = fc.meal_id WHERE m.user_id = ? AND m.status = ? AND m.deleted_at IS NULL AND fc.deleted_at IS NULL AND date BETWEEN ? AND ? group by m.meal_type"
+	queryResult, err := r.db.QueryContext(r.ctx, queryStr, rangeInDays, userId, model.Eaten, startRange, endRange)
 
 	if err != nil {
 		return []dto.AvgKcalPerMealTypeDto{}, err
@@ -102,8 +261,8 @@ func (r *MealRepository) GetAverageFoodCostInDateRange(startRange time.Time, end
 
 	endRange = setEndOfTheDay(endRange)
 
-	queryStr := "SELECT COALESCE(SUM(cost), 0.0) FROM food_consumption WHERE meal_id IN (SELECT id FROM meal WHERE user_id = ? AND date BETWEEN ? AND ?)"
-	err := r.db.QueryRow(queryStr, userId, startRange, endRange).Scan(&result)
+	queryStr := "SELECT COALESCE(SUM(cost), 0.0) FROM food_consumption WHERE deleted_at IS NULL AND meal_id IN (SELECT id FROM meal WHERE user_id = ? AND status = ? AND deleted_at IS NULL AND date BETWEEN ? AND ?)"
+	err := r.db.QueryRowContext(r.ctx, queryStr, userId, model.Eaten, startRange, endRange).Scan(&result)
 	if err != nil {
 		return 0, err
 	}
@@ -119,28 +278,115 @@ func (r *MealRepository) GetSumFoodCostInDateRange(startRange time.Time, endRang
 
 	endRange = setEndOfTheDay(endRange)
 
-	queryStr := "SELECT COALESCE(SUM(cost), 0.0) FROM food_consumption WHERE meal_id IN (SELECT id FROM meal WHERE user_id = ? AND date BETWEEN ? AND ?)"
-	err := r.db.QueryRow(queryStr, userId, startRange, endRange).Scan(&result)
+	queryStr := "SELECT COALESCE(SUM(cost), 0.0) FROM food_consumption WHERE deleted_at IS NULL AND meal_id IN (SELECT id FROM meal WHERE user_id = ? AND status = ? AND deleted_at IS NULL AND date BETWEEN ? AND ?)"
+	err := r.db.QueryRowContext(r.ctx, queryStr, userId, model.Eaten, startRange, endRange).Scan(&result)
 	if err != nil {
 		return 0, err
 	}
 	return result, nil
 }
 
-func (r *MealRepository) GetMealInDateRange(startRange time.Time, endRange time.Time, userId string) ([]model.Meal, error) {
-	var meals []model.Meal
+// GetConsumptionSumInDateRange retrieves the sum of kcal, macronutrients and cost of all the food of the user's meals with the status in the date range.
+func (r *MealRepository) GetConsumptionSumInDateRange(startRange time.Time, endRange time.Time, userId string, status model.MealStatus) (dto.ConsumptionSumDto, error) {
+	var result dto.ConsumptionSumDto
+
+	endRange = setEndOfTheDay(endRange)
+
+	queryStr := "SELECT COALESCE(SUM(kcal), 0.0), COALESCE(SUM(protein), 0.0), COALESCE(SUM(carbohydrate), 0.0), COALESCE(SUM(fat), 0.0), COALESCE(SUM(cost), 0.0) FROM food_consumption WHERE deleted_at IS NULL AND meal_id IN (SELECT id FROM meal WHERE user_id = ? AND status = ? AND deleted_at IS NULL AND date BETWEEN ? AND ?)"
+	err := r.db.QueryRowContext(r.ctx, queryStr, userId, status, startRange, endRange).Scan(&result.Kcal, &result.Protein, &result.Carbohydrate, &result.Fat, &result.Cost)
+	if err != nil {
+		return dto.ConsumptionSumDto{}, err
+	}
+	return result, nil
+}
+
+// GetPlannedSumOfEatenMealsInDateRange retrieves the planned kcal and cost of the meals which were planned ahead and then eaten in the date range.
+func (r *MealRepository) GetPlannedSumOfEatenMealsInDateRange(startRange time.Time, endRange time.Time, userId string) (dto.ConsumptionSumDto, error) {
+	var result dto.ConsumptionSumDto
+
+	endRange = setEndOfTheDay(endRange)
+
+	queryStr := "SELECT COALESCE(SUM(planned_kcal), 0.0), COALESCE(SUM(planned_cost), 0.0) FROM meal WHERE user_id = ? AND status = ? AND planned AND deleted_at IS NULL AND date BETWEEN ? AND ?"
+	err := r.db.QueryRowContext(r.ctx, queryStr, userId, model.Eaten, startRange, endRange).Scan(&result.Kcal, &result.Cost)
+	if err != nil {
+		return dto.ConsumptionSumDto{}, err
+	}
+	return result, nil
+}
+
+// GetTimeseriesInDateRange retrieves the number, kcal and cost of the user's eaten meals in the date range, aggregated
+// per meal type in buckets of the granularity which start at midnight in the location. Buckets without meals are not
+// returned.
+func (r *MealRepository) GetTimeseriesInDateRange(startRange time.Time, endRange time.Time, userId string, granularity dto.TimeseriesGranularity, location *time.Location) ([]dto.MealTimeseriesBucketDto, error) {
+	var result = make([]dto.MealTimeseriesBucketDto, 0)
 
 	endRange = setEndOfTheDay(endRange)
 
-	err := r.db.NewSelect().Model(&meals).Where("date BETWEEN ? AND ?", startRange, endRange).Where("user_id = ?", userId).Order("date ASC").Scan(r.ctx)
+	queryStr := "SELECT date_trunc(?, m.date AT TIME ZONE ?) AS bucket, m.meal_type, COUNT(DISTINCT m.id), COALESCE(SUM(fc.kcal), 0.0), COALESCE(SUM(fc.cost), 0.0) FROM meal m LEFT JOIN food_consumption fc ON fc.meal_id = m.id AND fc.deleted_at IS NULL WHERE m.user_id = ? AND m.status = ? AND m.deleted_at IS NULL AND m.date BETWEEN ? AND ? GROUP BY 1, 2 ORDER BY 1, 2"
+	queryResult, err := r.db.QueryContext(r.ctx, queryStr, string(granularity), location.String(), userId, model.Eaten, startRange, endRange)
 	if err != nil {
-		return []model.Meal{}, err
+		return nil, err
 	}
-	return meals, nil
+	defer queryResult.Close()
+	for queryResult.Next() {
+		var e dto.MealTimeseriesBucketDto
+		err = queryResult.Scan(&e.Bucket, &e.MealType, &e.MealCount, &e.Kcal, &e.Cost)
+		if err != nil {
+			return nil, err
+		}
+		// The bucket is the local time of its start, without a timezone.
+		y, m, d := e.Bucket.Date()
+		e.Bucket = time.Date(y, m, d, 0, 0, 0, 0, location)
+		result = append(result, e)
+	}
+	return result, queryResult.Err()
+}
+
+// GetMealWithConsumptionsInDateRange retrieves the user's meals with the status in the date range, together with their food consumptions.
+func (r *MealRepository) GetMealWithConsumptionsInDateRange(startRange time.Time, endRange time.Time, userId string, status model.MealStatus) ([]*model.Meal, error) {
+	var meals []*model.Meal
+
+	endRange = setEndOfTheDay(endRange)
+
+	err := r.db.NewSelect().
+		Model(&meals).
+		Relation("FoodConsumptions").
+		Where("m.date BETWEEN ? AND ?", startRange, endRange).
+		Where("m.user_id = ?", userId).
+		Where("m.status = ?", status).
+		Order("m.date ASC").
+		Scan(r.ctx)
+	return meals, err
+}
+
+// GetMealBatchInDateRange retrieves, together with their food consumptions, at most limit meals of the user in the date
+// range which come after the cursor, sorted by date and id. A nil cursor starts from the first meal.
+func (r *MealRepository) GetMealBatchInDateRange(startRange time.Time, endRange time.Time, userId string, cursor *dto.MealCursorDto, limit int) ([]*model.Meal, error) {
+	var meals []*model.Meal
+
+	endRange = setEndOfTheDay(endRange)
+
+	q := r.newSelectInDateRange(&meals, startRange, endRange, userId).Relation("FoodConsumptions")
+	if cursor != nil {
+		q = q.Where("(m.date, m.id) > (?, ?)", cursor.Date, cursor.ID)
+	}
+	err := q.Order("m.date ASC", "m.id ASC").Limit(limit).Scan(r.ctx)
+	return meals, err
+}
+
+func (r *MealRepository) newSelectInDateRange(model interface{}, startRange time.Time, endRange time.Time, userId string) *bun.SelectQuery {
+	return r.newSelectWithTotals(model).Where("m.date BETWEEN ? AND ?", startRange, endRange).Where("m.user_id = ?", userId)
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern, so that the value is matched literally.
+func escapeLike(value string) string {
+	return strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(value)
 }
 
+// setEndOfTheDay returns the last instant of the day of t in its location, so that the ranges of the queries cover the
+// days of the timezone of the request.
 func setEndOfTheDay(t time.Time) time.Time {
 	y, m, d := t.Date()
-	endOfTheDay := time.Date(y, m, d, 23, 59, 59, 0, t.Location())
+	endOfTheDay := time.Date(y, m, d, 23, 59, 59, int(time.Second-time.Nanosecond), t.Location())
 	return endOfTheDay
 }
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"food-track-be/model/dto"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"testing"
+	"time"
+)
+
+// The totals of the meals are aggregated in the meal query: listing a page of meals takes one query whatever the number
+// of meals, and the mock fails any other query, like the ones reading the totals of each meal.
+func TestFindPageReadsTotalsInTheMealQuery(t *testing.T) {
+	sqlDb, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := bun.NewDB(sqlDb, pgdialect.New())
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	r := NewMealRepository(*db)
+	rows := sqlmock.NewRows([]string{"id", "user_id", "name", "date", "kcal", "protein", "carbohydrate", "fat", "fiber", "sugar", "sodium", "cost"})
+	date := time.Date(2026, 10, 18, 12, 30, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		rows.AddRow(uuid.New().String(), "user-1", "lunch", date, 650.0, 30.0, 80.0, 20.0, 8.0, 12.0, 1.2, 3.5)
+	}
+	mock.ExpectQuery(`^SELECT m\.\*, COALESCE\(SUM\(fc\.kcal\), 0\) AS kcal, COALESCE\(SUM\(fc\.protein\), 0\) AS protein, ` +
+		`COALESCE\(SUM\(fc\.carbohydrate\), 0\) AS carbohydrate, COALESCE\(SUM\(fc\.fat\), 0\) AS fat, ` +
+		`COALESCE\(SUM\(fc\.fiber\), 0\) AS fiber, COALESCE\(SUM\(fc\.sugar\), 0\) AS sugar, ` +
+		`COALESCE\(SUM\(fc\.sodium\), 0\) AS sodium, COALESCE\(SUM\(fc\.cost\), 0\) AS cost ` +
+		`FROM "meal" AS "m" LEFT JOIN food_consumption AS fc ON fc\.meal_id = m\.id AND fc\.deleted_at IS NULL ` +
+		`WHERE \(m\.user_id = 'user-1'\) AND "m"\."deleted_at" IS NULL GROUP BY "m"\."id" .*LIMIT 51$`).
+		WillReturnRows(rows)
+
+	meals, err := r.FindPage("user-1", dto.MealQueryDto{Limit: 51}, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(meals) != 3 {
+		t.Fatalf("expected 3 meals, got %d", len(meals))
+	}
+	for _, meal := range meals {
+		if meal.Kcal != 650 || meal.Protein != 30 || meal.Sodium != 1.2 || meal.Cost != 3.5 {
+			t.Errorf("expected the totals of the meal, got %+v", meal)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"food-track-be/model"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+type RecipeRepository struct {
+	db  bun.DB
+	ctx context.Context
+}
+
+func NewRecipeRepository(db bun.DB) *RecipeRepository {
+	return &RecipeRepository{db: db, ctx: context.Background()}
+}
+
+// FindAll retrieves all the recipes of the user together with their ingredients.
+func (r *RecipeRepository) FindAll(userId string) ([]*model.Recipe, error) {
+	var recipes []*model.Recipe
+	err := r.db.NewSelect().Model(&recipes).Relation("Ingredients").Where("r.user_id = ?", userId).Order("r.name ASC").Scan(r.ctx)
+	return recipes, err
+}
+
+// FindByIdAndUserId retrieves the recipe of the user together with its ingredients.
+func (r *RecipeRepository) FindByIdAndUserId(id uuid.UUID, userId string) (*model.Recipe, error) {
+	var recipe model.Recipe
+	err := r.db.NewSelect().Model(&recipe).Relation("Ingredients").Where("r.id = ?", id).Where("r.user_id = ?", userId).Scan(r.ctx)
+	return &recipe, err
+}
+
+// Create inserts the recipe and its ingredients in a single transaction.
+func (r *RecipeRepository) Create(recipe *model.Recipe) error {
+	return r.db.RunInTx(r.ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewInsert().Model(recipe).Exec(ctx)
+		if err != nil {
+			return err
+		}
+		return insertIngredients(ctx, tx, recipe.Ingredients)
+	})
+}
+
+// Update updates the recipe and replaces all its ingredients in a single transaction.
+func (r *RecipeRepository) Update(recipe *model.Recipe, userId string) error {
+	return r.db.RunInTx(r.ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewUpdate().Model(recipe).Where("id = ?", recipe.ID).Where("user_id = ?", userId).Exec(ctx)
+		if err != nil {
+			return err
+		}
+		_, err = tx.NewDelete().Model((*model.RecipeIngredient)(nil)).Where("recipe_id = ?", recipe.ID).Exec(ctx)
+		if err != nil {
+			return err
+		}
+		return insertIngredients(ctx, tx, recipe.Ingredients)
+	})
+}
+
+// Delete deletes the recipe and all its ingredients in a single transaction.
+func (r *RecipeRepository) Delete(recipe *model.Recipe, userId string) error {
+	return r.db.RunInTx(r.ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewDelete().Model((*model.RecipeIngredient)(nil)).Where("recipe_id = ?", recipe.ID).Exec(ctx)
+		if err != nil {
+			return err
+		}
+		_, err = tx.NewDelete().Model(recipe).Where("id = ?", recipe.ID).Where("user_id = ?", userId).Exec(ctx)
+		return err
+	})
+}
+
+func insertIngredients(ctx context.Context, tx bun.Tx, ingredients []*model.RecipeIngredient) error {
+	if len(ingredients) == 0 {
+		return nil
+	}
+	_, err := tx.NewInsert().Model(&ingredients).Exec(ctx)
+	return err
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"food-track-be/model"
+	"github.com/uptrace/bun"
+)
+
+type UserProfileRepository struct {
+	db  bun.DB
+	ctx context.Context
+}
+
+func NewUserProfileRepository(db bun.DB) *UserProfileRepository {
+	return &UserProfileRepository{db: db, ctx: context.Background()}
+}
+
+// FindByUserId retrieves the profile of the user.
+func (r *UserProfileRepository) FindByUserId(userId string) (*model.UserProfile, error) {
+	var userProfile model.UserProfile
+	err := r.db.NewSelect().Model(&userProfile).Where("user_id = ?", userId).Scan(r.ctx)
+	return &userProfile, err
+}
+
+// Upsert creates the profile of the user or, if it already exists, overwrites it.
+func (r *UserProfileRepository) Upsert(userProfile *model.UserProfile) (sql.Result, error) {
+	return r.db.NewInsert().Model(userProfile).On("CONFLICT (user_id) DO UPDATE").Exec(r.ctx)
+}
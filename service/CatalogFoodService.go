@@ -0,0 +1,252 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"food-track-be/model"
+	"food-track-be/model/dto"
+	"food-track-be/repository"
+	"github.com/google/uuid"
+	"log"
+	"strings"
+)
+
+// ErrCatalogFoodNotFound is returned when the food isn't in the catalog.
+var ErrCatalogFoodNotFound = NewNotFoundError("food not found in the catalog")
+
+const (
+	// DefaultCatalogSearchLimit is the number of foods returned by a search without limit.
+	DefaultCatalogSearchLimit = 20
+	maxCatalogSearchLimit     = 100
+)
+
+// CatalogFoodService manages the local food catalog and computes the nutrients of the food consumptions from it.
+type CatalogFoodService struct {
+	repository *repository.CatalogFoodRepository
+}
+
+func NewCatalogFoodService(repository *repository.CatalogFoodRepository) *CatalogFoodService {
+	return &CatalogFoodService{repository: repository}
+}
+
+// Search retrieves up to limit foods whose name, an alias or the barcode match the query.
+func (s *CatalogFoodService) Search(query string, limit int) ([]dto.CatalogFoodDto, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, NewValidationError("the search query is required")
+	}
+	if limit <= 0 || limit > maxCatalogSearchLimit {
+		return nil, NewValidationError("limit must be between 1 and 100")
+	}
+	catalogFoods, err := s.repository.Search(query, limit)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	catalogFoodsDto := make([]dto.CatalogFoodDto, 0, len(catalogFoods))
+	for _, catalogFood := range catalogFoods {
+		catalogFoodsDto = append(catalogFoodsDto, mapCatalogFoodToDto(catalogFood))
+	}
+	return catalogFoodsDto, nil
+}
+
+func (s *CatalogFoodService) FindById(id uuid.UUID) (dto.CatalogFoodDto, error) {
+	catalogFood, err := s.findById(id)
+	if err != nil {
+		return dto.CatalogFoodDto{}, err
+	}
+	return mapCatalogFoodToDto(catalogFood), nil
+}
+
+// Create adds the food to the catalog. The barcode, when present, must not belong to another food.
+func (s *CatalogFoodService) Create(catalogFoodDto dto.CatalogFoodDto) (dto.CatalogFoodDto, error) {
+	catalogFood := mapDtoToCatalogFood(catalogFoodDto)
+	catalogFood.ID = uuid.New()
+	err := s.checkBarcode(catalogFood)
+	if err != nil {
+		return dto.CatalogFoodDto{}, err
+	}
+	_, err = s.repository.Create(catalogFood)
+	if err != nil {
+		log.Println(err)
+		return dto.CatalogFoodDto{}, err
+	}
+	return mapCatalogFoodToDto(catalogFood), nil
+}
+
+// Update overwrites the food of the catalog. The food consumptions already saved keep their nutrients.
+func (s *CatalogFoodService) Update(catalogFoodDto dto.CatalogFoodDto) (dto.CatalogFoodDto, error) {
+	_, err := s.findById(catalogFoodDto.ID)
+	if err != nil {
+		return dto.CatalogFoodDto{}, err
+	}
+	catalogFood := mapDtoToCatalogFood(catalogFoodDto)
+	err = s.checkBarcode(catalogFood)
+	if err != nil {
+		return dto.CatalogFoodDto{}, err
+	}
+	_, err = s.repository.Update(catalogFood)
+	if err != nil {
+		log.Println(err)
+		return dto.CatalogFoodDto{}, err
+	}
+	return mapCatalogFoodToDto(catalogFood), nil
+}
+
+// Delete removes the food from the catalog. The food consumptions computed from it keep their nutrients.
+func (s *CatalogFoodService) Delete(id uuid.UUID) error {
+	catalogFood, err := s.findById(id)
+	if err != nil {
+		return err
+	}
+	_, err = s.repository.Delete(catalogFood)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	return nil
+}
+
+// FillNutrition computes the nutrients the client omitted, the ones equal to zero, of the food consumption from the
+// food of the catalog and the standardized quantity, so it must be called after the quantity has been normalized.
+// previous is the food consumption before an update, nil on create: when the update changes the quantity, the unit or
+// the food of the catalog, the nutrients the client left unchanged are computed again too.
+//
+// The food is the one referenced by CatalogFoodId or, without it, the one named as the food consumed. Food
+// consumptions which don't match any food of the catalog are left untouched.
+func (s *CatalogFoodService) FillNutrition(foodConsumption *model.FoodConsumption, previous *model.FoodConsumption) error {
+	var catalogFood *model.CatalogFood
+	var err error
+	if foodConsumption.CatalogFoodId != uuid.Nil {
+		catalogFood, err = s.findById(foodConsumption.CatalogFoodId)
+	} else {
+		catalogFood, err = s.repository.FindByName(strings.TrimSpace(foodConsumption.FoodName))
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+	}
+	if err != nil {
+		return err
+	}
+	fillNutrition(foodConsumption, catalogFood, previous)
+	return nil
+}
+
+// fillNutrition links the food consumption to the food of the catalog and sets the nutrients equal to zero from the
+// nutrients per 100 g of the food. If previous was computed from the catalog too and the quantity, the unit or the food
+// of the catalog changed since, the nutrients still equal to the previous ones are set as well, since they were computed
+// for the previous quantity.
+func fillNutrition(foodConsumption *model.FoodConsumption, catalogFood *model.CatalogFood, previous *model.FoodConsumption) {
+	recompute := previous != nil && previous.CatalogFoodId != uuid.Nil && (previous.CatalogFoodId != catalogFood.ID ||
+		previous.QuantityUsed != foodConsumption.QuantityUsed || previous.Unit != foodConsumption.Unit)
+	if previous == nil {
+		previous = &model.FoodConsumption{}
+	}
+	foodConsumption.CatalogFoodId = catalogFood.ID
+	factor := foodConsumption.QuantityUsedStd / 100
+	nutrients := []struct {
+		value    *float32
+		previous float32
+		per100g  float32
+	}{
+		{&foodConsumption.Kcal, previous.Kcal, catalogFood.Kcal},
+		{&foodConsumption.Protein, previous.Protein, catalogFood.Protein},
+		{&foodConsumption.Carbohydrate, previous.Carbohydrate, catalogFood.Carbohydrate},
+		{&foodConsumption.Fat, previous.Fat, catalogFood.Fat},
+		{&foodConsumption.Fiber, previous.Fiber, catalogFood.Fiber},
+		{&foodConsumption.Sugar, previous.Sugar, catalogFood.Sugar},
+		{&foodConsumption.Sodium, previous.Sodium, catalogFood.Sodium},
+	}
+	for _, nutrient := range nutrients {
+		if *nutrient.value == 0 || (recompute && *nutrient.value == nutrient.previous) {
+			*nutrient.value = nutrient.per100g * factor
+		}
+	}
+}
+
+func (s *CatalogFoodService) findById(id uuid.UUID) (*model.CatalogFood, error) {
+	catalogFood, err := s.repository.FindById(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCatalogFoodNotFound
+	}
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return catalogFood, nil
+}
+
+// findByBarcode retrieves the food of the catalog with the barcode, or nil if there is none.
+func (s *CatalogFoodService) findByBarcode(barcode string) (*model.CatalogFood, error) {
+	catalogFood, err := s.repository.FindByBarcode(barcode)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return catalogFood, nil
+}
+
+// checkBarcode makes sure the barcode of the food doesn't belong to another food of the catalog.
+func (s *CatalogFoodService) checkBarcode(catalogFood *model.CatalogFood) error {
+	if catalogFood.Barcode == "" {
+		return nil
+	}
+	existing, err := s.findByBarcode(catalogFood.Barcode)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ID != catalogFood.ID {
+		return NewConflictError("the barcode " + catalogFood.Barcode + " belongs to another food")
+	}
+	return nil
+}
+
+func mapDtoToCatalogFood(catalogFoodDto dto.CatalogFoodDto) *model.CatalogFood {
+	catalogFood := &model.CatalogFood{
+		ID:           catalogFoodDto.ID,
+		Barcode:      strings.TrimSpace(catalogFoodDto.Barcode),
+		Name:         strings.TrimSpace(catalogFoodDto.Name),
+		DefaultUnit:  catalogFoodDto.DefaultUnit,
+		Aliases:      make([]string, 0, len(catalogFoodDto.Aliases)),
+		Kcal:         catalogFoodDto.Kcal,
+		Protein:      catalogFoodDto.Protein,
+		Carbohydrate: catalogFoodDto.Carbohydrate,
+		Fat:          catalogFoodDto.Fat,
+		Fiber:        catalogFoodDto.Fiber,
+		Sugar:        catalogFoodDto.Sugar,
+		Sodium:       catalogFoodDto.Sodium,
+	}
+	if catalogFood.DefaultUnit == "" {
+		catalogFood.DefaultUnit = string(model.Gram)
+	}
+	for _, alias := range catalogFoodDto.Aliases {
+		if alias = strings.TrimSpace(alias); alias != "" {
+			catalogFood.Aliases = append(catalogFood.Aliases, alias)
+		}
+	}
+	return catalogFood
+}
+
+func mapCatalogFoodToDto(catalogFood *model.CatalogFood) dto.CatalogFoodDto {
+	aliases := catalogFood.Aliases
+	if aliases == nil {
+		aliases = make([]string, 0)
+	}
+	return dto.CatalogFoodDto{
+		ID:           catalogFood.ID,
+		Barcode:      catalogFood.Barcode,
+		Name:         catalogFood.Name,
+		DefaultUnit:  catalogFood.DefaultUnit,
+		Aliases:      aliases,
+		Kcal:         catalogFood.Kcal,
+		Protein:      catalogFood.Protein,
+		Carbohydrate: catalogFood.Carbohydrate,
+		Fat:          catalogFood.Fat,
+		Fiber:        catalogFood.Fiber,
+		Sugar:        catalogFood.Sugar,
+		Sodium:       catalogFood.Sodium,
+	}
+}
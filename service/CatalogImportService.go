@@ -0,0 +1,315 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"food-track-be/model"
+	"food-track-be/model/dto"
+	"food-track-be/repository"
+	"github.com/google/uuid"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// catalogImportBatchSize is the number of foods upserted by a single statement of the import.
+	catalogImportBatchSize = 1000
+	// kilojoulesPerKcal converts the energy of the products which report it only in kJ.
+	kilojoulesPerKcal = 4.184
+	// saltPerSodium converts the salt of the products which don't report the sodium.
+	saltPerSodium     = 2.5
+	maxBarcodeLength  = 64
+	maxFoodNameLength = 255
+)
+
+// CatalogImportService loads the products of an Open Food Facts dump into the food catalog.
+//
+// The dump is read as a stream and the foods are upserted by barcode in batches, so that dumps larger than the memory
+// can be imported and imported again to update the catalog.
+type CatalogImportService struct {
+	repository *repository.CatalogFoodRepository
+}
+
+func NewCatalogImportService(repository *repository.CatalogFoodRepository) *CatalogImportService {
+	return &CatalogImportService{repository: repository}
+}
+
+// ImportFile imports the dump at path, which may be gzip compressed. format is either jsonl or csv; when empty, it is
+// deduced from the extension of the file. progress, when not nil, is called with the counts so far after every batch.
+func (s *CatalogImportService) ImportFile(path string, format string, progress func(dto.CatalogImportResultDto)) (dto.CatalogImportResultDto, error) {
+	if format == "" {
+		extension := filepath.Ext(strings.TrimSuffix(path, ".gz"))
+		format = strings.TrimPrefix(extension, ".")
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return dto.CatalogImportResultDto{}, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReaderSize(file, 1<<20)
+	magic, err := reader.Peek(2)
+	if err == nil && bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return dto.CatalogImportResultDto{}, err
+		}
+		defer gzipReader.Close()
+		return s.Import(gzipReader, format, progress)
+	}
+	return s.Import(reader, format, progress)
+}
+
+// Import reads the products of the dump and upserts them into the catalog. format is either jsonl, the Open Food Facts
+// JSONL export, or csv, the Open Food Facts CSV export, separated by tabs or commas.
+//
+// Products without barcode, name or energy, and rows which can't be parsed, are skipped. Foods already imported keep
+// their id, default unit and aliases. progress, when not nil, is called with the counts so far after every batch.
+func (s *CatalogImportService) Import(reader io.Reader, format string, progress func(dto.CatalogImportResultDto)) (dto.CatalogImportResultDto, error) {
+	importer := &catalogImporter{repository: s.repository, progress: progress}
+	var err error
+	switch format {
+	case "jsonl", "json":
+		err = importer.readJsonl(reader)
+	case "csv", "tsv":
+		err = importer.readCsv(reader)
+	default:
+		return dto.CatalogImportResultDto{}, NewValidationError("format must be jsonl or csv")
+	}
+	if err == nil {
+		err = importer.flush()
+	}
+	return importer.result, err
+}
+
+// catalogImporter accumulates the foods read from the dump and upserts them a batch at a time.
+type catalogImporter struct {
+	repository *repository.CatalogFoodRepository
+	result     dto.CatalogImportResultDto
+	progress   func(dto.CatalogImportResultDto)
+	batch      []*model.CatalogFood
+	barcodes   map[string]int
+}
+
+// add parses the product and adds it to the batch, which is upserted once full. A product whose barcode is already in
+// the batch replaces the previous one, since a statement can't upsert the same row twice.
+func (i *catalogImporter) add(value func(name string) string) error {
+	i.result.Rows++
+	catalogFood, ok := parseOpenFoodFactsProduct(value)
+	if !ok {
+		i.result.Skipped++
+		return nil
+	}
+	if i.barcodes == nil {
+		i.barcodes = make(map[string]int, catalogImportBatchSize)
+	}
+	if position, ok := i.barcodes[catalogFood.Barcode]; ok {
+		i.batch[position] = catalogFood
+		i.result.Skipped++
+		return nil
+	}
+	i.barcodes[catalogFood.Barcode] = len(i.batch)
+	i.batch = append(i.batch, catalogFood)
+	if len(i.batch) >= catalogImportBatchSize {
+		return i.flush()
+	}
+	return nil
+}
+
+func (i *catalogImporter) flush() error {
+	if len(i.batch) == 0 {
+		return nil
+	}
+	_, err := i.repository.UpsertAllByBarcode(i.batch)
+	if err != nil {
+		return err
+	}
+	i.result.Imported += len(i.batch)
+	if i.progress != nil {
+		i.progress(i.result)
+	}
+	i.batch = i.batch[:0]
+	clear(i.barcodes)
+	return nil
+}
+
+// readJsonl reads a product per line. The lines of the dump can be very long, so they aren't limited in length.
+func (i *catalogImporter) readJsonl(reader io.Reader) error {
+	bufferedReader := bufio.NewReader(reader)
+	for {
+		line, err := bufferedReader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			if err := i.addJsonProduct(line); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (i *catalogImporter) addJsonProduct(line []byte) error {
+	var product struct {
+		Code        any            `json:"code"`
+		ProductName string         `json:"product_name"`
+		GenericName string         `json:"generic_name"`
+		Nutriments  map[string]any `json:"nutriments"`
+	}
+	if err := json.Unmarshal(line, &product); err != nil {
+		i.result.Rows++
+		i.result.Skipped++
+		return nil
+	}
+	return i.add(func(name string) string {
+		switch name {
+		case "code":
+			return jsonValueToString(product.Code)
+		case "product_name":
+			return product.ProductName
+		case "generic_name":
+			return product.GenericName
+		default:
+			return jsonValueToString(product.Nutriments[name])
+		}
+	})
+}
+
+// readCsv reads a CSV file with a header, in any order, and a product per row. The separator is a tab, like in the
+// Open Food Facts export, if the header contains one, a comma otherwise.
+func (i *catalogImporter) readCsv(reader io.Reader) error {
+	bufferedReader := bufio.NewReader(reader)
+	headerLine, err := bufferedReader.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return err
+	}
+	comma := ','
+	if strings.Contains(headerLine, "\t") {
+		comma = '\t'
+	}
+	headerReader := csv.NewReader(strings.NewReader(headerLine))
+	headerReader.Comma = comma
+	header, err := headerReader.Read()
+	if err != nil {
+		return &DomainError{Kind: Validation, Message: "invalid csv header", Err: err}
+	}
+	columns := make(map[string]int, len(header))
+	for position, name := range header {
+		columns[strings.TrimSpace(name)] = position
+	}
+	if _, ok := columns["code"]; !ok {
+		return NewValidationError("invalid csv header: missing column code")
+	}
+
+	csvReader := csv.NewReader(bufferedReader)
+	csvReader.Comma = comma
+	csvReader.FieldsPerRecord = -1
+	csvReader.LazyQuotes = true
+	csvReader.ReuseRecord = true
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		var parseError *csv.ParseError
+		if errors.As(err, &parseError) {
+			i.result.Rows++
+			i.result.Skipped++
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		err = i.add(func(name string) string {
+			position, ok := columns[name]
+			if !ok || position >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[position])
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// parseOpenFoodFactsProduct maps the fields of an Open Food Facts product, read by name, to a food of the catalog. It
+// returns false if the product misses the barcode, the name or the energy.
+func parseOpenFoodFactsProduct(value func(name string) string) (*model.CatalogFood, bool) {
+	barcode := value("code")
+	name := value("product_name")
+	if name == "" {
+		name = value("generic_name")
+	}
+	if barcode == "" || len(barcode) > maxBarcodeLength || name == "" {
+		return nil, false
+	}
+	if len(name) > maxFoodNameLength {
+		name = strings.ToValidUTF8(name[:maxFoodNameLength], "")
+	}
+
+	kcal, ok := parseNutriment(value("energy-kcal_100g"))
+	if !ok {
+		kilojoules, ok := parseNutriment(value("energy_100g"))
+		if !ok {
+			return nil, false
+		}
+		kcal = kilojoules / kilojoulesPerKcal
+	}
+	catalogFood := &model.CatalogFood{
+		ID:          uuid.New(),
+		Barcode:     barcode,
+		Name:        name,
+		DefaultUnit: string(model.Gram),
+		Aliases:     make([]string, 0),
+		Kcal:        kcal,
+	}
+	catalogFood.Protein, _ = parseNutriment(value("proteins_100g"))
+	catalogFood.Carbohydrate, _ = parseNutriment(value("carbohydrates_100g"))
+	catalogFood.Fat, _ = parseNutriment(value("fat_100g"))
+	catalogFood.Fiber, _ = parseNutriment(value("fiber_100g"))
+	catalogFood.Sugar, _ = parseNutriment(value("sugars_100g"))
+	var hasSodium bool
+	if catalogFood.Sodium, hasSodium = parseNutriment(value("sodium_100g")); !hasSodium {
+		salt, _ := parseNutriment(value("salt_100g"))
+		catalogFood.Sodium = salt / saltPerSodium
+	}
+	return catalogFood, true
+}
+
+// parseNutriment parses the amount of a nutriment, returning false if it is missing or invalid. Negative amounts, which
+// appear in the dumps as data entry errors, are invalid.
+func parseNutriment(value string) (float32, bool) {
+	if value == "" {
+		return 0, false
+	}
+	parsed, err := strconv.ParseFloat(value, 32)
+	if err != nil || parsed < 0 {
+		return 0, false
+	}
+	return float32(parsed), true
+}
+
+func jsonValueToString(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(value)
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	default:
+		return fmt.Sprint(value)
+	}
+}
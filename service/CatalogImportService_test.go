@@ -0,0 +1,102 @@
+package service
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func productValues(values map[string]string) func(name string) string {
+	return func(name string) string {
+		return values[name]
+	}
+}
+
+func TestParseOpenFoodFactsProduct(t *testing.T) {
+	products := []struct {
+		name           string
+		values         map[string]string
+		valid          bool
+		expectedKcal   float32
+		expectedSodium float32
+	}{
+		{"kcal", map[string]string{"code": "1", "product_name": "pasta", "energy-kcal_100g": "350", "energy_100g": "1464", "sodium_100g": "0.01"}, true, 350, 0.01},
+		{"energy only in kJ", map[string]string{"code": "1", "product_name": "pasta", "energy_100g": "1464"}, true, 1464 / kilojoulesPerKcal, 0},
+		{"sodium from the salt", map[string]string{"code": "1", "product_name": "crackers", "energy-kcal_100g": "430", "salt_100g": "2"}, true, 430, 0.8},
+		{"sodium over the salt", map[string]string{"code": "1", "product_name": "crackers", "energy-kcal_100g": "430", "salt_100g": "2", "sodium_100g": "0.5"}, true, 430, 0.5},
+		{"generic name", map[string]string{"code": "1", "generic_name": "pasta", "energy-kcal_100g": "350"}, true, 350, 0},
+		{"missing energy", map[string]string{"code": "1", "product_name": "pasta"}, false, 0, 0},
+		{"negative energy", map[string]string{"code": "1", "product_name": "pasta", "energy-kcal_100g": "-350"}, false, 0, 0},
+		{"missing barcode", map[string]string{"product_name": "pasta", "energy-kcal_100g": "350"}, false, 0, 0},
+		{"missing name", map[string]string{"code": "1", "energy-kcal_100g": "350"}, false, 0, 0},
+	}
+	for _, product := range products {
+		t.Run(product.name, func(t *testing.T) {
+			catalogFood, ok := parseOpenFoodFactsProduct(productValues(product.values))
+
+			if ok != product.valid {
+				t.Fatalf("expected the product to be valid %v, got %v", product.valid, ok)
+			}
+			if !ok {
+				return
+			}
+			if math.Abs(float64(catalogFood.Kcal-product.expectedKcal)) > 0.01 {
+				t.Fatalf("expected %v kcal, got %v", product.expectedKcal, catalogFood.Kcal)
+			}
+			if math.Abs(float64(catalogFood.Sodium-product.expectedSodium)) > 0.0001 {
+				t.Fatalf("expected %v g of sodium, got %v", product.expectedSodium, catalogFood.Sodium)
+			}
+		})
+	}
+}
+
+func TestReadCsv(t *testing.T) {
+	dumps := []struct {
+		name string
+		dump string
+	}{
+		{"tabs", "code\tproduct_name\tenergy-kcal_100g\n8001\tpasta, whole wheat\t350\n8002\trice\t360\n"},
+		{"commas", "product_name,code,energy-kcal_100g\n\"pasta, whole wheat\",8001,350\nrice,8002,360\n"},
+	}
+	for _, dump := range dumps {
+		t.Run(dump.name, func(t *testing.T) {
+			importer := &catalogImporter{}
+
+			err := importer.readCsv(strings.NewReader(dump.dump))
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(importer.batch) != 2 {
+				t.Fatalf("expected 2 foods, got %d", len(importer.batch))
+			}
+			if importer.batch[0].Barcode != "8001" || importer.batch[0].Name != "pasta, whole wheat" || importer.batch[0].Kcal != 350 {
+				t.Fatalf("expected the pasta, got %+v", importer.batch[0])
+			}
+			if importer.batch[1].Barcode != "8002" || importer.batch[1].Name != "rice" {
+				t.Fatalf("expected the rice, got %+v", importer.batch[1])
+			}
+		})
+	}
+}
+
+func TestReadCsvKeepsTheLastProductOfADuplicateBarcode(t *testing.T) {
+	importer := &catalogImporter{}
+	dump := "code,product_name,energy-kcal_100g\n8001,pasta,350\n8002,rice,360\n8001,whole wheat pasta,340\n"
+
+	err := importer.readCsv(strings.NewReader(dump))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A statement can't upsert the same row twice: the batch has the barcode once, with the last product read.
+	if len(importer.batch) != 2 {
+		t.Fatalf("expected 2 foods, got %d", len(importer.batch))
+	}
+	if importer.batch[0].Barcode != "8001" || importer.batch[0].Name != "whole wheat pasta" {
+		t.Fatalf("expected the last product with the barcode, got %+v", importer.batch[0])
+	}
+	if importer.result.Rows != 3 || importer.result.Skipped != 1 {
+		t.Fatalf("expected 3 rows read and 1 skipped, got %+v", importer.result)
+	}
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"food-track-be/model/dto"
+	"github.com/sony/gobreaker"
+	"github.com/uptrace/bun/driver/pgdriver"
+)
+
+// ErrorKind classifies the errors returned by the services, so that the API can report them with the right status.
+type ErrorKind string
+
+const (
+	// NotFound is returned when the resource doesn't exist or belongs to another user.
+	NotFound ErrorKind = "NOT_FOUND"
+	// Validation is returned when the request is invalid.
+	Validation ErrorKind = "VALIDATION"
+	// Unauthorized is returned when the request isn't authenticated.
+	Unauthorized ErrorKind = "UNAUTHORIZED"
+	// Forbidden is returned when the authenticated user isn't allowed to perform the request.
+	Forbidden ErrorKind = "FORBIDDEN"
+	// Conflict is returned when the request clashes with the current state of the resource.
+	Conflict ErrorKind = "CONFLICT"
+	// UpstreamFailure is returned when grocery-be answers with an error.
+	UpstreamFailure ErrorKind = "UPSTREAM_FAILURE"
+	// UpstreamUnavailable is returned when grocery-be can't be reached.
+	UpstreamUnavailable ErrorKind = "UPSTREAM_UNAVAILABLE"
+	// Internal is every other error.
+	Internal ErrorKind = "INTERNAL"
+)
+
+// DomainError is an error of the services, with its kind. Fields lists the invalid fields of Validation errors.
+type DomainError struct {
+	Kind    ErrorKind
+	Message string
+	Fields  []dto.FieldErrorDto
+	Err     error
+}
+
+func (e *DomainError) Error() string {
+	if e.Err != nil && e.Message == "" {
+		return e.Err.Error()
+	}
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *DomainError) Unwrap() error {
+	return e.Err
+}
+
+func NewNotFoundError(message string) error {
+	return &DomainError{Kind: NotFound, Message: message}
+}
+
+func NewValidationError(message string) error {
+	return &DomainError{Kind: Validation, Message: message}
+}
+
+func NewUnauthorizedError(message string) error {
+	return &DomainError{Kind: Unauthorized, Message: message}
+}
+
+func NewForbiddenError(message string) error {
+	return &DomainError{Kind: Forbidden, Message: message}
+}
+
+func NewConflictError(message string) error {
+	return &DomainError{Kind: Conflict, Message: message}
+}
+
+// ToDomainError returns the DomainError wrapped by err, converting the errors of the database and of the grocery-be
+// circuit breaker. Any other error is Internal.
+func ToDomainError(err error) *DomainError {
+	var domainError *DomainError
+	switch {
+	case errors.As(err, &domainError):
+		return domainError
+	case errors.Is(err, sql.ErrNoRows):
+		return &DomainError{Kind: NotFound, Message: "resource not found", Err: err}
+	case errors.Is(err, gobreaker.ErrOpenState), errors.Is(err, gobreaker.ErrTooManyRequests):
+		return &DomainError{Kind: UpstreamUnavailable, Message: "grocery service unavailable", Err: err}
+	default:
+		return &DomainError{Kind: Internal, Err: err}
+	}
+}
+
+// isUniqueViolation reports whether err is the violation of a unique constraint of the database.
+func isUniqueViolation(err error) bool {
+	var pgError pgdriver.Error
+	return errors.As(err, &pgError) && pgError.Field('C') == "23505"
+}
@@ -1,29 +1,46 @@
 package service
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
 	"food-track-be/model"
 	"food-track-be/model/dto"
 	"food-track-be/repository"
 	"github.com/google/uuid"
 	"github.com/mashingan/smapping"
+	"github.com/uptrace/bun"
 	"log"
 	"time"
 )
 
+// ErrMealNotFound is returned when the meal doesn't exist or belongs to another user.
+var ErrMealNotFound = NewNotFoundError("meal not found")
+
 type FoodConsumptionService struct {
-	repository     *repository.FoodConsumptionRepository
-	groceryService *GroceryService
+	repository           *repository.FoodConsumptionRepository
+	mealRepository       *repository.MealRepository
+	groceryService       *GroceryService
+	groceryOutboxService *GroceryOutboxService
+	unitService          *UnitService
+	catalogFoodService   *CatalogFoodService
 }
 
-func NewFoodConsumptionService(repository *repository.FoodConsumptionRepository, groceryService *GroceryService) *FoodConsumptionService {
-	return &FoodConsumptionService{repository: repository, groceryService: groceryService}
+func NewFoodConsumptionService(repository *repository.FoodConsumptionRepository, mealRepository *repository.MealRepository, groceryService *GroceryService, groceryOutboxService *GroceryOutboxService, unitService *UnitService, catalogFoodService *CatalogFoodService) *FoodConsumptionService {
+	return &FoodConsumptionService{repository: repository, mealRepository: mealRepository, groceryService: groceryService, groceryOutboxService: groceryOutboxService, unitService: unitService, catalogFoodService: catalogFoodService}
 }
 
-// FindAllFoodConsumptionForMeal retrieves all food consumptions for a given meal ID
-func (s FoodConsumptionService) FindAllFoodConsumptionForMeal(mealId uuid.UUID) ([]*dto.FoodConsumptionDto, error) {
+// FindAllFoodConsumptionForMeal retrieves all food consumptions for a given meal ID of the user
+func (s FoodConsumptionService) FindAllFoodConsumptionForMeal(mealId uuid.UUID, userId string) ([]*dto.FoodConsumptionDto, error) {
 	// Initialize an empty slice to hold the DTOs
 	var foodConsumptionsDto []*dto.FoodConsumptionDto
 
+	// Make sure the meal belongs to the user
+	_, err := s.findMealForUser(mealId, userId)
+	if err != nil {
+		return nil, err
+	}
+
 	// Retrieve food consumptions from the repository
 	foodConsumptions, err := s.repository.FindAllFoodConsumptionForMeal(mealId)
 	if err != nil {
@@ -43,116 +60,625 @@ func (s FoodConsumptionService) FindAllFoodConsumptionForMeal(mealId uuid.UUID)
 	return foodConsumptionsDto, nil
 }
 
-func (s FoodConsumptionService) CreateFoodConsumptionForMeal(mealId uuid.UUID, foodConsumptionDto dto.FoodConsumptionDto, token string) (dto.FoodConsumptionDto, error) {
-	foodConsumption := model.FoodConsumption{}
-	mappedField := smapping.MapFields(&foodConsumptionDto)
-	err := smapping.FillStruct(&foodConsumption, mappedField)
-	foodConsumption.MealID = mealId
+// CreateFoodConsumptionForMeal creates the food consumption for the meal, with the quantity used converted to the
+// standard unit and the omitted nutrients computed from the food catalog, and, when it references a grocery
+// transaction, removes the quantity used from the transaction's available quantity. The pantry is left untouched for
+// planned meals.
+func (s FoodConsumptionService) CreateFoodConsumptionForMeal(mealId uuid.UUID, userId string, foodConsumptionDto dto.FoodConsumptionDto, token string) (dto.FoodConsumptionDto, error) {
+	foodConsumptionsDto, err := s.CreateAllFoodConsumptionsForMeal(mealId, userId, []dto.FoodConsumptionDto{foodConsumptionDto}, token)
+	if err != nil {
+		return dto.FoodConsumptionDto{}, err
+	}
+	return *foodConsumptionsDto[0], nil
+}
+
+// CreateAllFoodConsumptionsForMeal creates the food consumptions for the meal like CreateFoodConsumptionForMeal, as a
+// single saga: they are all inserted in one database transaction and, if the pantry can't be updated, all deleted.
+func (s FoodConsumptionService) CreateAllFoodConsumptionsForMeal(mealId uuid.UUID, userId string, foodConsumptionsDto []dto.FoodConsumptionDto, token string) ([]*dto.FoodConsumptionDto, error) {
+	meal, err := s.findMealForUser(mealId, userId)
 	if err != nil {
 		log.Println(err)
-		return foodConsumptionDto, err
+		return nil, err
 	}
-	foodConsumption.ID = uuid.New()
-	var transactionDto dto.FoodTransactionDto
-	if foodConsumption.FoodId != uuid.Nil && foodConsumption.TransactionId != uuid.Nil {
-		transactionDto, err = s.groceryService.GetTransactionDetail(foodConsumptionDto.FoodId, foodConsumptionDto.TransactionId, token)
+	planned := meal.Status == model.Planned
+
+	saga := s.groceryOutboxService.NewSaga(userId)
+	foodConsumptions := make([]*model.FoodConsumption, 0, len(foodConsumptionsDto))
+	for _, foodConsumptionDto := range foodConsumptionsDto {
+		foodConsumption := &model.FoodConsumption{}
+		mappedField := smapping.MapFields(&foodConsumptionDto)
+		err = smapping.FillStruct(foodConsumption, mappedField)
 		if err != nil {
 			log.Println(err)
-			return dto.FoodConsumptionDto{}, err
+			return nil, err
+		}
+		foodConsumption.MealID = mealId
+		foodConsumption.ID = uuid.New()
+		foodConsumption.SyncFailed = false
+
+		err = s.unitService.Normalize(foodConsumption, userId)
+		if err != nil {
+			return nil, err
+		}
+		err = s.catalogFoodService.FillNutrition(foodConsumption, nil)
+		if err != nil {
+			return nil, err
 		}
-		foodConsumption.Cost = (transactionDto.Price / transactionDto.Quantity) * foodConsumptionDto.QuantityUsed
-		defer func() {
-			if err != nil {
-				transactionDto.AvailableQuantity -= foodConsumptionDto.QuantityUsed
-				_, err = s.groceryService.UpdateFoodTransaction(foodConsumptionDto.FoodId, transactionDto, token)
-				println(err)
-			}
-		}()
-	}
 
-	_, err = s.repository.Create(&foodConsumption)
+		err = s.computeCost(foodConsumption, token)
+		if err != nil {
+			log.Println(err)
+			return nil, err
+		}
+
+		foodConsumptions = append(foodConsumptions, foodConsumption)
+		saga.AddFoodConsumption(foodConsumption.ID, nil)
+		if !planned && isLinkedToGrocery(foodConsumption) {
+			saga.AddEntry(foodConsumption, foodConsumption.QuantityUsed)
+		}
+	}
+	if len(foodConsumptions) == 0 {
+		return []*dto.FoodConsumptionDto{}, nil
+	}
 
-	foodConsumptionDto, err = s.mapMealConsumptionToDto(&foodConsumption)
+	err = s.repository.RunInTx(func(tx bun.Tx) error {
+		_, err := s.repository.WithTx(tx).CreateAll(foodConsumptions)
+		if err != nil {
+			return err
+		}
+		return s.groceryOutboxService.Enqueue(tx, saga)
+	})
 	if err != nil {
 		log.Println(err)
-		return foodConsumptionDto, err
+		return nil, err
 	}
-	return foodConsumptionDto, nil
+	s.groceryOutboxService.ProcessSaga(saga.ID, token)
+
+	createdDto := make([]*dto.FoodConsumptionDto, 0, len(foodConsumptions))
+	for _, foodConsumption := range foodConsumptions {
+		foodConsumptionDto, err := s.mapMealConsumptionToDto(foodConsumption)
+		if err != nil {
+			return nil, err
+		}
+		createdDto = append(createdDto, &foodConsumptionDto)
+	}
+	return createdDto, nil
 }
 
-func (s FoodConsumptionService) UpdateFoodConsumptionForMeal(mealId uuid.UUID, foodConsumptionDto dto.FoodConsumptionDto, token string) (dto.FoodConsumptionDto, error) {
+// UpdateFoodConsumptionForMeal updates the food consumption of the meal, normalized and completed from the food catalog
+// like a new one, and moves the difference of quantity used between the previous and the new version to the referenced
+// grocery transactions. The pantry is left untouched for planned meals.
+func (s FoodConsumptionService) UpdateFoodConsumptionForMeal(mealId uuid.UUID, userId string, foodConsumptionDto dto.FoodConsumptionDto, token string) (dto.FoodConsumptionDto, error) {
+	meal, err := s.findMealForUser(mealId, userId)
+	if err != nil {
+		log.Println(err)
+		return dto.FoodConsumptionDto{}, err
+	}
+	planned := meal.Status == model.Planned
+	prevConsumption, err := s.findFoodConsumptionForMeal(mealId, foodConsumptionDto.ID)
+	if err != nil {
+		log.Println(err)
+		return dto.FoodConsumptionDto{}, err
+	}
+
 	foodConsumption := model.FoodConsumption{}
-	err := smapping.FillStruct(&foodConsumption, smapping.MapFields(&foodConsumptionDto))
-	foodConsumption.MealID = mealId
+	err = smapping.FillStruct(&foodConsumption, smapping.MapFields(&foodConsumptionDto))
 	if err != nil {
 		return foodConsumptionDto, err
 	}
+	foodConsumption.MealID = mealId
+	// The flag is kept until the pantry is corrected by hand, since the update doesn't resend the lost change.
+	foodConsumption.SyncFailed = prevConsumption.SyncFailed
+
+	err = s.unitService.Normalize(&foodConsumption, userId)
+	if err != nil {
+		return dto.FoodConsumptionDto{}, err
+	}
+	err = s.catalogFoodService.FillNutrition(&foodConsumption, prevConsumption)
+	if err != nil {
+		return dto.FoodConsumptionDto{}, err
+	}
+
+	err = s.computeCost(&foodConsumption, token)
+	if err != nil {
+		log.Println(err)
+		return dto.FoodConsumptionDto{}, err
+	}
+
+	saga := s.groceryOutboxService.NewSaga(userId)
+	saga.AddFoodConsumption(foodConsumption.ID, prevConsumption)
+	sameTransaction := prevConsumption.FoodId == foodConsumption.FoodId && prevConsumption.TransactionId == foodConsumption.TransactionId
+	if !planned && sameTransaction && isLinkedToGrocery(&foodConsumption) {
+		deltaQuantity := foodConsumption.QuantityUsed - prevConsumption.QuantityUsed
+		if deltaQuantity != 0 {
+			saga.AddEntry(&foodConsumption, deltaQuantity)
+		}
+	} else if !planned && !sameTransaction {
+		if isLinkedToGrocery(prevConsumption) {
+			saga.AddEntry(prevConsumption, -prevConsumption.QuantityUsed)
+		}
+		if isLinkedToGrocery(&foodConsumption) {
+			saga.AddEntry(&foodConsumption, foodConsumption.QuantityUsed)
+		}
+	}
 
-	// Initialize variables for handling grocery transactions
-	var transactionDto dto.FoodTransactionDto
-	if foodConsumption.FoodId != uuid.Nil && foodConsumption.TransactionId != uuid.Nil {
-		transactionDto, err := s.groceryService.GetTransactionDetail(foodConsumptionDto.FoodId, foodConsumptionDto.TransactionId, token)
+	err = s.repository.RunInTx(func(tx bun.Tx) error {
+		_, err := s.repository.WithTx(tx).Update(&foodConsumption)
 		if err != nil {
-			return dto.FoodConsumptionDto{}, err
+			return err
 		}
+		return s.groceryOutboxService.Enqueue(tx, saga)
+	})
+	if err != nil {
+		log.Println(err)
+		return dto.FoodConsumptionDto{}, err
+	}
+	s.groceryOutboxService.ProcessSaga(saga.ID, token)
 
-		foodConsumption.Cost = (transactionDto.Price / transactionDto.Quantity) * foodConsumptionDto.QuantityUsed
+	return s.mapMealConsumptionToDto(&foodConsumption)
+}
 
+// DeleteFoodConsumptionForMeal deletes the food consumption of the meal and gives back to the referenced grocery
+// transaction the quantity the food consumption took from it. The pantry is left untouched for planned meals.
+func (s FoodConsumptionService) DeleteFoodConsumptionForMeal(mealId uuid.UUID, userId string, foodConsumptionId uuid.UUID, token string) error {
+	meal, err := s.findMealForUser(mealId, userId)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	planned := meal.Status == model.Planned
+	foodConsumption, err := s.findFoodConsumptionForMeal(mealId, foodConsumptionId)
+	if err != nil {
+		log.Println(err)
+		return err
 	}
 
-	_, err = s.repository.Update(&foodConsumption)
+	takenQuantities, err := s.groceryOutboxService.TakenQuantities([]*model.FoodConsumption{foodConsumption})
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	saga := s.groceryOutboxService.NewSaga(userId)
+	saga.AddFoodConsumption(foodConsumption.ID, foodConsumption)
+	if !planned && isLinkedToGrocery(foodConsumption) && takenQuantities[foodConsumption.ID] > 0 {
+		saga.AddEntry(foodConsumption, -takenQuantities[foodConsumption.ID])
+	}
 
-	if foodConsumption.FoodId != uuid.Nil && foodConsumption.TransactionId != uuid.Nil {
-		prevConsumption, err := s.repository.FindById(foodConsumptionDto.ID)
+	err = s.repository.RunInTx(func(tx bun.Tx) error {
+		_, err := s.repository.WithTx(tx).DeleteFoodConsumptionForMeal(mealId, foodConsumptionId)
 		if err != nil {
-			return dto.FoodConsumptionDto{}, err
+			return err
 		}
-		deltaQuantity := foodConsumptionDto.QuantityUsed - prevConsumption.QuantityUsed
-		transactionDto.AvailableQuantity += deltaQuantity
-		_, err = s.groceryService.UpdateFoodTransaction(foodConsumptionDto.FoodId, transactionDto, token)
+		return s.groceryOutboxService.Enqueue(tx, saga)
+	})
+	if err != nil {
+		log.Println(err)
+		return err
 	}
+	s.groceryOutboxService.ProcessSaga(saga.ID, token)
+
+	return nil
+}
 
-	foodConsumptionDto, err = s.mapMealConsumptionToDto(&foodConsumption)
+// RestoreFoodConsumptionForMeal moves the food consumption of the meal out of the trash and removes again from the
+// referenced grocery transaction the quantity given back by the deletion. The pantry is left untouched for planned meals.
+func (s FoodConsumptionService) RestoreFoodConsumptionForMeal(mealId uuid.UUID, userId string, foodConsumptionId uuid.UUID, token string) (dto.FoodConsumptionDto, error) {
+	meal, err := s.findMealForUser(mealId, userId)
 	if err != nil {
-		return foodConsumptionDto, err
+		log.Println(err)
+		return dto.FoodConsumptionDto{}, err
+	}
+	planned := meal.Status == model.Planned
+	foodConsumption, err := s.repository.FindDeletedById(foodConsumptionId)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && foodConsumption.MealID != mealId) {
+		return dto.FoodConsumptionDto{}, NewNotFoundError("food consumption not found in the trash of the meal")
+	}
+	if err != nil {
+		log.Println(err)
+		return dto.FoodConsumptionDto{}, err
 	}
-	return foodConsumptionDto, nil
-}
 
-func (s FoodConsumptionService) DeleteFoodConsumptionForMeal(mealId uuid.UUID, foodConsumptionId uuid.UUID, token string) error {
-	foodConsumption, err := s.repository.FindById(foodConsumptionId)
+	takenQuantities, err := s.groceryOutboxService.TakenQuantities([]*model.FoodConsumption{foodConsumption})
 	if err != nil {
 		log.Println(err)
+		return dto.FoodConsumptionDto{}, err
+	}
+	// The deleted food consumption is the previous version, so that a compensated saga moves it back to the trash.
+	saga := s.groceryOutboxService.NewSaga(userId)
+	saga.AddFoodConsumption(foodConsumption.ID, foodConsumption)
+	if !planned && isLinkedToGrocery(foodConsumption) {
+		addRetakeEntry(saga, foodConsumption, takenQuantities)
+	}
+
+	err = s.repository.RunInTx(func(tx bun.Tx) error {
+		_, err := s.repository.WithTx(tx).Restore(foodConsumption)
+		if err != nil {
+			return err
+		}
+		return s.groceryOutboxService.Enqueue(tx, saga)
+	})
+	if err != nil {
+		log.Println(err)
+		return dto.FoodConsumptionDto{}, err
+	}
+	s.groceryOutboxService.ProcessSaga(saga.ID, token)
+
+	return s.mapMealConsumptionToDto(foodConsumption)
+}
+
+// DeleteMealWithConsumptions moves the meal to the trash together with its food consumptions and, if restoreStock is
+// set, gives back to the referenced grocery transactions the quantities the food consumptions took from them: the ones
+// which never took any, like the imported ones, give nothing back. The pantry is left untouched for planned meals. If
+// the pantry can't be updated, the meal and all its food consumptions are moved back out of the trash.
+func (s FoodConsumptionService) DeleteMealWithConsumptions(meal *model.Meal, restoreStock bool, token string) error {
+	foodConsumptions, err := s.repository.FindAllFoodConsumptionForMeal(meal.ID)
+	if err != nil {
+		return err
+	}
+
+	takenQuantities, err := s.groceryOutboxService.TakenQuantities(foodConsumptions)
+	if err != nil {
+		return err
+	}
+
+	previous := *meal
+	meal.StockRestored = restoreStock && meal.Status != model.Planned
+	saga := s.groceryOutboxService.NewSaga(meal.UserId)
+	saga.AddMeal(meal.ID, &previous)
+	for _, foodConsumption := range foodConsumptions {
+		saga.AddFoodConsumption(foodConsumption.ID, foodConsumption)
+		if meal.StockRestored && isLinkedToGrocery(foodConsumption) && takenQuantities[foodConsumption.ID] > 0 {
+			saga.AddEntry(foodConsumption, -takenQuantities[foodConsumption.ID])
+		}
+	}
+
+	err = s.repository.RunInTx(func(tx bun.Tx) error {
+		mealRepository := s.mealRepository.WithTx(tx)
+		_, err := mealRepository.Update(meal, meal.UserId)
+		if err != nil {
+			return err
+		}
+		// The meal is deleted first, so that its food consumptions are deleted not before it.
+		_, err = mealRepository.Delete(meal, meal.UserId)
+		if err != nil {
+			return err
+		}
+		_, err = s.repository.WithTx(tx).DeleteAllFoodConsumptionForMeal(meal.ID)
+		if err != nil {
+			return err
+		}
+		return s.groceryOutboxService.Enqueue(tx, saga)
+	})
+	if err != nil {
+		return err
+	}
+	s.groceryOutboxService.ProcessSaga(saga.ID, token)
+
+	return nil
+}
+
+// RestoreMealWithConsumptions moves the meal out of the trash together with the food consumptions deleted with it and,
+// if their quantities were given back to the pantry, removes them again from the referenced grocery transactions. If the
+// pantry can't be updated, the meal and all its food consumptions are moved back to the trash.
+func (s FoodConsumptionService) RestoreMealWithConsumptions(meal *model.Meal, token string) error {
+	foodConsumptions, err := s.repository.FindDeletedWithMeal(meal)
+	if err != nil {
 		return err
 	}
 
-	_, err = s.repository.DeleteFoodConsumptionForMeal(mealId, foodConsumptionId)
+	takenQuantities, err := s.groceryOutboxService.TakenQuantities(foodConsumptions)
+	if err != nil {
+		return err
+	}
 
-	if foodConsumption.FoodId != uuid.Nil && foodConsumption.TransactionId != uuid.Nil {
+	// The deleted meal and food consumptions are the previous versions, so that a compensated saga moves them back to
+	// the trash.
+	saga := s.groceryOutboxService.NewSaga(meal.UserId)
+	saga.AddMeal(meal.ID, meal)
+	for _, foodConsumption := range foodConsumptions {
+		saga.AddFoodConsumption(foodConsumption.ID, foodConsumption)
+		if meal.StockRestored && isLinkedToGrocery(foodConsumption) {
+			addRetakeEntry(saga, foodConsumption, takenQuantities)
+		}
+	}
+
+	err = s.repository.RunInTx(func(tx bun.Tx) error {
+		_, err := s.mealRepository.WithTx(tx).Restore(meal, meal.UserId)
 		if err != nil {
-			transactionDto, err := s.groceryService.GetTransactionDetail(foodConsumption.FoodId, foodConsumption.TransactionId, token)
+			return err
+		}
+		foodConsumptionRepository := s.repository.WithTx(tx)
+		for _, foodConsumption := range foodConsumptions {
+			_, err := foodConsumptionRepository.Restore(foodConsumption)
+			if err != nil {
+				return err
+			}
+		}
+		return s.groceryOutboxService.Enqueue(tx, saga)
+	})
+	if err != nil {
+		return err
+	}
+	s.groceryOutboxService.ProcessSaga(saga.ID, token)
+
+	return nil
+}
+
+// CreateCopiesOfMeal saves the copies of the meal, which the caller has built, and copies into each of them the food
+// consumptions of the meal. The cost of the copied food consumptions is updated to the current price and, for copies
+// which are not planned, the quantities used are removed from the referenced grocery transactions, after making sure
+// they have enough available quantity for all the copies. The copies are a single saga: if the pantry can't be updated,
+// all of them are deleted together with their food consumptions.
+func (s FoodConsumptionService) CreateCopiesOfMeal(meal *model.Meal, copies []*model.Meal, token string) error {
+	foodConsumptions, err := s.repository.FindAllFoodConsumptionForMeal(meal.ID)
+	if err != nil {
+		return err
+	}
+	consumingCopies := 0
+	for _, mealCopy := range copies {
+		if mealCopy.Status != model.Planned {
+			consumingCopies++
+		}
+	}
+	// The price is the same for every copy, so each transaction is read only once.
+	transactions := make(map[uuid.UUID]dto.FoodTransactionDto)
+	quantitiesNeeded := make(map[uuid.UUID]float32)
+	for _, foodConsumption := range foodConsumptions {
+		if !isLinkedToGrocery(foodConsumption) {
+			continue
+		}
+		transactionDto, ok := transactions[foodConsumption.TransactionId]
+		if !ok {
+			transactionDto, err = s.groceryService.GetTransactionDetail(foodConsumption.FoodId, foodConsumption.TransactionId, token)
 			if err != nil {
-				log.Println(err)
 				return err
 			}
-			transactionDto.AvailableQuantity += foodConsumption.QuantityUsed
-			_, err = s.groceryService.UpdateFoodTransaction(foodConsumption.FoodId, transactionDto, token)
+			transactions[foodConsumption.TransactionId] = transactionDto
+		}
+		setCost(foodConsumption, transactionDto)
+		quantitiesNeeded[foodConsumption.TransactionId] += foodConsumption.QuantityUsed * float32(consumingCopies)
+		if quantitiesNeeded[foodConsumption.TransactionId] > transactionDto.AvailableQuantity {
+			return NewConflictError(fmt.Sprintf("not enough %s available in the pantry for %d copies", foodConsumption.FoodName, consumingCopies))
+		}
+	}
+
+	saga := s.groceryOutboxService.NewSaga(meal.UserId)
+	copiedConsumptions := make([]*model.FoodConsumption, 0, len(foodConsumptions)*len(copies))
+	for _, mealCopy := range copies {
+		saga.AddMeal(mealCopy.ID, nil)
+		for _, foodConsumption := range foodConsumptions {
+			copiedConsumption := *foodConsumption
+			copiedConsumption.ID = uuid.New()
+			copiedConsumption.MealID = mealCopy.ID
+			copiedConsumptions = append(copiedConsumptions, &copiedConsumption)
+			saga.AddFoodConsumption(copiedConsumption.ID, nil)
+			if mealCopy.Status != model.Planned && isLinkedToGrocery(&copiedConsumption) {
+				saga.AddEntry(&copiedConsumption, copiedConsumption.QuantityUsed)
+			}
+			mealCopy.Kcal += copiedConsumption.Kcal
+			mealCopy.Protein += copiedConsumption.Protein
+			mealCopy.Carbohydrate += copiedConsumption.Carbohydrate
+			mealCopy.Fat += copiedConsumption.Fat
+			mealCopy.Fiber += copiedConsumption.Fiber
+			mealCopy.Sugar += copiedConsumption.Sugar
+			mealCopy.Sodium += copiedConsumption.Sodium
+			mealCopy.Cost += copiedConsumption.Cost
+		}
+	}
+
+	err = s.repository.RunInTx(func(tx bun.Tx) error {
+		_, err := s.mealRepository.WithTx(tx).CreateAll(copies)
+		if err != nil {
+			return err
+		}
+		if len(copiedConsumptions) > 0 {
+			_, err = s.repository.WithTx(tx).CreateAll(copiedConsumptions)
 			if err != nil {
-				log.Println(err)
 				return err
 			}
 		}
+		return s.groceryOutboxService.Enqueue(tx, saga)
+	})
+	if err != nil {
+		return err
 	}
+	s.groceryOutboxService.ProcessSaga(saga.ID, token)
 
 	return nil
 }
 
-func (s FoodConsumptionService) GetKcalSumForMeal(mealId uuid.UUID) (float32, error) {
-	return s.repository.GetKcalSumForMeal(mealId)
+// CreateAllForPlannedMeals inserts, inside the transaction, the food consumptions of meals which are planned and
+// therefore don't use the pantry yet.
+func (s FoodConsumptionService) CreateAllForPlannedMeals(tx bun.Tx, foodConsumptions []*model.FoodConsumption) error {
+	if len(foodConsumptions) == 0 {
+		return nil
+	}
+	_, err := s.repository.WithTx(tx).CreateAll(foodConsumptions)
+	return err
 }
 
-func (s FoodConsumptionService) GetCostSumForMeal(mealId uuid.UUID) (float32, error) {
-	return s.repository.GetCostSumForMeal(mealId)
+// ConsumePlannedMeal saves the planned meal, which the caller has marked as eaten, and turns its planned food
+// consumptions into real ones: the transaction of the ones referencing a grocery-be food without transaction is
+// resolved, their cost is updated to the current price and the quantities used are removed from the grocery
+// transactions. previous is the meal as it was before being marked as eaten, so that a compensated saga plans it again
+// together with its food consumptions.
+func (s FoodConsumptionService) ConsumePlannedMeal(meal *model.Meal, previous *model.Meal, token string) error {
+	foodConsumptions, err := s.repository.FindAllFoodConsumptionForMeal(meal.ID)
+	if err != nil {
+		return err
+	}
+
+	saga := s.groceryOutboxService.NewSaga(meal.UserId)
+	saga.AddMeal(meal.ID, previous)
+	// Food consumptions of the same food are resolved against what the previous ones left in its transactions.
+	resolver := s.newTransactionResolver(token)
+	for _, foodConsumption := range foodConsumptions {
+		saga.AddFoodConsumption(foodConsumption.ID, foodConsumption)
+		if foodConsumption.FoodId != uuid.Nil && foodConsumption.TransactionId == uuid.Nil {
+			foodConsumption.TransactionId, err = resolver.resolve(foodConsumption.FoodId, foodConsumption.FoodName, foodConsumption.QuantityUsed)
+			if err != nil {
+				return err
+			}
+		}
+		if !isLinkedToGrocery(foodConsumption) {
+			continue
+		}
+		err = s.computeCost(foodConsumption, token)
+		if err != nil {
+			return err
+		}
+		saga.AddEntry(foodConsumption, foodConsumption.QuantityUsed)
+	}
+
+	err = s.repository.RunInTx(func(tx bun.Tx) error {
+		_, err := s.mealRepository.WithTx(tx).Update(meal, meal.UserId)
+		if err != nil {
+			return err
+		}
+		foodConsumptionRepository := s.repository.WithTx(tx)
+		for _, foodConsumption := range foodConsumptions {
+			_, err := foodConsumptionRepository.Update(foodConsumption)
+			if err != nil {
+				return err
+			}
+		}
+		return s.groceryOutboxService.Enqueue(tx, saga)
+	})
+	if err != nil {
+		return err
+	}
+	s.groceryOutboxService.ProcessSaga(saga.ID, token)
+
+	return nil
+}
+
+// LookupBarcode finds the food with the barcode in the pantry of the user and in the food catalog and builds a draft of
+// its consumption. The draft uses the first transaction with an available quantity, the unit of the pantry food, or the
+// default unit of the catalog food, and quantity, or 100 g or ml and 1 of any other unit when quantity is 0. Its
+// nutrients are computed from the catalog and its cost from the price of the transaction.
+func (s FoodConsumptionService) LookupBarcode(barcode string, pantryId string, quantity float32, userId string, token string) (dto.BarcodeLookupDto, error) {
+	lookupDto := dto.BarcodeLookupDto{Transactions: make([]*dto.FoodTransactionDto, 0)}
+	availableFoods, err := s.groceryService.GetAllAvailableFood(token, pantryId)
+	if err != nil {
+		log.Println(err)
+		return dto.BarcodeLookupDto{}, err
+	}
+	for _, availableFood := range availableFoods {
+		if availableFood.Barcode == barcode {
+			lookupDto.Food = availableFood
+			break
+		}
+	}
+	catalogFood, err := s.catalogFoodService.findByBarcode(barcode)
+	if err != nil {
+		return dto.BarcodeLookupDto{}, err
+	}
+	if lookupDto.Food == nil && catalogFood == nil {
+		return dto.BarcodeLookupDto{}, NewNotFoundError("barcode " + barcode + " not found in the pantry nor in the catalog")
+	}
+
+	foodConsumption := model.FoodConsumption{Unit: string(model.Gram)}
+	if catalogFood != nil {
+		catalogFoodDto := mapCatalogFoodToDto(catalogFood)
+		lookupDto.CatalogFood = &catalogFoodDto
+		foodConsumption.FoodName = catalogFood.Name
+		foodConsumption.Unit = catalogFood.DefaultUnit
+	}
+	if lookupDto.Food != nil {
+		foodConsumption.FoodId = lookupDto.Food.ID
+		foodConsumption.FoodName = lookupDto.Food.Name
+		if model.Unit(lookupDto.Food.Unit).IsValid() {
+			foodConsumption.Unit = lookupDto.Food.Unit
+		}
+		transactions, err := s.groceryService.GetAvailableTransactionForFood(lookupDto.Food.ID, token)
+		if err != nil {
+			log.Println(err)
+			return dto.BarcodeLookupDto{}, err
+		}
+		if transactions != nil {
+			lookupDto.Transactions = transactions
+		}
+		for _, transaction := range transactions {
+			if transaction.AvailableQuantity > 0 {
+				foodConsumption.TransactionId = transaction.ID
+				if transaction.Quantity != 0 {
+					foodConsumption.Cost = transaction.Price / transaction.Quantity
+				}
+				break
+			}
+		}
+	}
+
+	if quantity == 0 {
+		quantity = 1
+		if unit := model.Unit(foodConsumption.Unit); unit == model.Gram || unit == model.Milliliter {
+			quantity = 100
+		}
+	}
+	foodConsumption.QuantityUsed = quantity
+	// The cost read above is the price of a unit of the transaction.
+	foodConsumption.Cost *= quantity
+	err = s.unitService.Normalize(&foodConsumption, userId)
+	var domainError *DomainError
+	if errors.As(err, &domainError) && domainError.Kind == Validation {
+		// The weight of a piece of the food is unknown: the client completes the draft with the standard quantity.
+		err = nil
+	}
+	if err != nil {
+		return dto.BarcodeLookupDto{}, err
+	}
+	if catalogFood != nil {
+		fillNutrition(&foodConsumption, catalogFood, nil)
+	}
+
+	lookupDto.Draft, err = s.mapMealConsumptionToDto(&foodConsumption)
+	return lookupDto, err
+}
+
+// transactionResolver resolves the grocery-be transactions used by the food consumptions of a single change. The
+// transactions of each food are read once and the quantity taken by the food consumptions already resolved is removed
+// from them, so that two food consumptions of the change don't both count on the same available quantity.
+type transactionResolver struct {
+	groceryService *GroceryService
+	token          string
+	transactions   map[uuid.UUID][]*dto.FoodTransactionDto
+}
+
+func (s FoodConsumptionService) newTransactionResolver(token string) *transactionResolver {
+	return &transactionResolver{groceryService: s.groceryService, token: token, transactions: make(map[uuid.UUID][]*dto.FoodTransactionDto)}
+}
+
+// resolve picks the transaction of the grocery-be food which quantity is used from: among the ones with enough quantity
+// left, the one expiring first, the ones without expiration date last. It fails with a Conflict error if no transaction
+// has enough quantity left.
+func (r *transactionResolver) resolve(foodId uuid.UUID, foodName string, quantity float32) (uuid.UUID, error) {
+	transactions, ok := r.transactions[foodId]
+	if !ok {
+		var err error
+		transactions, err = r.groceryService.GetAvailableTransactionForFood(foodId, r.token)
+		if err != nil {
+			log.Println(err)
+			return uuid.Nil, err
+		}
+		r.transactions[foodId] = transactions
+	}
+	var resolved *dto.FoodTransactionDto
+	var resolvedExpirationDate time.Time
+	resolvedExpires := false
+	for _, transaction := range transactions {
+		if transaction.AvailableQuantity < quantity {
+			continue
+		}
+		expirationDate, expires := parseExpirationDate(transaction.ExpirationDate, time.UTC)
+		if resolved == nil || (expires && (!resolvedExpires || expirationDate.Before(resolvedExpirationDate))) {
+			resolved, resolvedExpirationDate, resolvedExpires = transaction, expirationDate, expires
+		}
+	}
+	if resolved == nil {
+		return uuid.Nil, NewConflictError(fmt.Sprintf("not enough %s available in the pantry", foodName))
+	}
+	resolved.AvailableQuantity -= quantity
+	return resolved.ID, nil
 }
 
 func (s FoodConsumptionService) GetMostConsumedFoodInDateRange(startDate time.Time, endDate time.Time, userId string) (*dto.MostConsumedFoodDto, error) {
@@ -164,6 +690,64 @@ func (s FoodConsumptionService) GetMostConsumedFoodInDateRange(startDate time.Ti
 	return mostConsumedFood, nil
 }
 
+// findFoodConsumptionForMeal retrieves the food consumption, making sure it belongs to the meal.
+func (s FoodConsumptionService) findFoodConsumptionForMeal(mealId uuid.UUID, foodConsumptionId uuid.UUID) (*model.FoodConsumption, error) {
+	foodConsumption, err := s.repository.FindById(foodConsumptionId)
+	if err != nil {
+		return nil, err
+	}
+	if foodConsumption.MealID != mealId {
+		return nil, NewNotFoundError("food consumption not found for the meal")
+	}
+	return foodConsumption, nil
+}
+
+// findMealForUser retrieves the meal, making sure it belongs to the user.
+func (s FoodConsumptionService) findMealForUser(mealId uuid.UUID, userId string) (*model.Meal, error) {
+	meal, err := s.mealRepository.FindByIdAndUserId(mealId, userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrMealNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return meal, nil
+}
+
+// computeCost sets the cost of the food consumption from the price of the referenced grocery transaction.
+func (s FoodConsumptionService) computeCost(foodConsumption *model.FoodConsumption, token string) error {
+	if !isLinkedToGrocery(foodConsumption) {
+		return nil
+	}
+	transactionDto, err := s.groceryService.GetTransactionDetail(foodConsumption.FoodId, foodConsumption.TransactionId, token)
+	if err != nil {
+		return err
+	}
+	setCost(foodConsumption, transactionDto)
+	return nil
+}
+
+// addRetakeEntry adds to the saga the entry which takes again from the pantry the quantity of the restored food
+// consumption given back by its deletion. Food consumptions which never went through the grocery outbox gave nothing
+// back, so they take nothing.
+func addRetakeEntry(saga *model.GrocerySaga, foodConsumption *model.FoodConsumption, takenQuantities map[uuid.UUID]float32) {
+	takenQuantity, ok := takenQuantities[foodConsumption.ID]
+	if ok && foodConsumption.QuantityUsed > takenQuantity {
+		saga.AddEntry(foodConsumption, foodConsumption.QuantityUsed-takenQuantity)
+	}
+}
+
+// setCost sets the cost of the food consumption from the price of the grocery transaction.
+func setCost(foodConsumption *model.FoodConsumption, transactionDto dto.FoodTransactionDto) {
+	if transactionDto.Quantity != 0 {
+		foodConsumption.Cost = (transactionDto.Price / transactionDto.Quantity) * foodConsumption.QuantityUsed
+	}
+}
+
+func isLinkedToGrocery(foodConsumption *model.FoodConsumption) bool {
+	return foodConsumption.FoodId != uuid.Nil && foodConsumption.TransactionId != uuid.Nil
+}
+
 func (s FoodConsumptionService) mapMealConsumptionToDto(foodConsumption *model.FoodConsumption) (dto.FoodConsumptionDto, error) {
 	foodConsumptionDto := dto.FoodConsumptionDto{}
 	err := smapping.FillStruct(&foodConsumptionDto, smapping.MapFields(&foodConsumption))
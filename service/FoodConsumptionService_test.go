@@ -0,0 +1,235 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"food-track-be/model"
+	"food-track-be/model/dto"
+	"food-track-be/repository"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newTestFoodConsumptionService creates the service against a mocked database and a fake grocery-be which answers every
+// request for a transaction with transactionDto.
+func newTestFoodConsumptionService(t *testing.T, transactionDto dto.FoodTransactionDto) (*FoodConsumptionService, sqlmock.Sqlmock) {
+	return newTestFoodConsumptionServiceWithGrocery(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(dto.BaseResponse[dto.FoodTransactionDto]{Body: transactionDto})
+	}))
+}
+
+// newTestFoodConsumptionServiceWithGrocery creates the service against a mocked database and a fake grocery-be served
+// by grocery.
+func newTestFoodConsumptionServiceWithGrocery(t *testing.T, grocery http.Handler) (*FoodConsumptionService, sqlmock.Sqlmock) {
+	sqlDb, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := bun.NewDB(sqlDb, pgdialect.New())
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	server := httptest.NewServer(grocery)
+	t.Cleanup(server.Close)
+	t.Setenv("GROCERY_BASE_URL", server.URL)
+
+	mealRepository := repository.NewMealRepository(*db)
+	foodConsumptionRepository := repository.NewFoodConsumptionRepository(*db)
+	groceryService := NewGroceryService()
+	foodConsumptionService := NewFoodConsumptionService(
+		foodConsumptionRepository,
+		mealRepository,
+		groceryService,
+		NewGroceryOutboxService(repository.NewGroceryOutboxRepository(*db), mealRepository, foodConsumptionRepository, groceryService),
+		NewUnitService(repository.NewFoodPieceWeightRepository(*db)),
+		NewCatalogFoodService(repository.NewCatalogFoodRepository(*db)),
+	)
+	return foodConsumptionService, mock
+}
+
+func TestCreateCopiesOfMealFailsWithoutEnoughAvailableQuantity(t *testing.T) {
+	foodConsumptionService, mock := newTestFoodConsumptionService(t, dto.FoodTransactionDto{Quantity: 500, AvailableQuantity: 100, Price: 2})
+	meal := &model.Meal{ID: uuid.New(), UserId: "user-1", Status: model.Eaten}
+	mock.ExpectQuery(`FROM "food_consumption" AS "fc" WHERE .*meal_id = '` + meal.ID.String() + `'`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "meal_id", "food_name", "food_id", "transaction_id", "quantity_used", "unit"}).
+			AddRow(uuid.New().String(), meal.ID.String(), "pasta", uuid.New().String(), uuid.New().String(), 40.0, "g"))
+	// Two copies of 40 g fit in the 100 g available, the third one doesn't; the planned copy doesn't use the pantry.
+	copies := make([]*model.Meal, 0, 4)
+	for day := 1; day <= 4; day++ {
+		status := model.Eaten
+		if day == 4 {
+			status = model.Planned
+		}
+		copies = append(copies, &model.Meal{ID: uuid.New(), UserId: "user-1", Date: time.Now().AddDate(0, 0, day), Status: status})
+	}
+
+	err := foodConsumptionService.CreateCopiesOfMeal(meal, copies, "token")
+
+	var domainError *DomainError
+	if !errors.As(err, &domainError) || domainError.Kind != Conflict {
+		t.Fatalf("expected a conflict error, got %v", err)
+	}
+	// Nothing is written: the mock fails any statement which isn't expected.
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFillNutritionRecomputesUnchangedNutrientsWhenTheQuantityChanges(t *testing.T) {
+	catalogFood := &model.CatalogFood{ID: uuid.New(), Kcal: 350, Protein: 12, Fat: 1.5}
+	previous := &model.FoodConsumption{CatalogFoodId: catalogFood.ID, QuantityUsed: 100, QuantityUsedStd: 100, Unit: "g", Kcal: 350, Protein: 12, Fat: 1.5}
+	// The client doubles the quantity and corrects the fat, sending the other nutrients as they were.
+	foodConsumption := *previous
+	foodConsumption.QuantityUsed, foodConsumption.QuantityUsedStd, foodConsumption.Fat = 200, 200, 4
+
+	fillNutrition(&foodConsumption, catalogFood, previous)
+
+	if foodConsumption.Kcal != 700 || foodConsumption.Protein != 24 {
+		t.Fatalf("expected the nutrients of 200 g, got %v kcal and %v g of protein", foodConsumption.Kcal, foodConsumption.Protein)
+	}
+	if foodConsumption.Fat != 4 {
+		t.Fatalf("expected the fat sent by the client, got %v", foodConsumption.Fat)
+	}
+}
+
+func TestFillNutritionKeepsNutrientsWhenTheQuantityIsUnchanged(t *testing.T) {
+	catalogFood := &model.CatalogFood{ID: uuid.New(), Kcal: 350, Protein: 12}
+	previous := &model.FoodConsumption{CatalogFoodId: catalogFood.ID, QuantityUsed: 100, QuantityUsedStd: 100, Unit: "g", Kcal: 300, Protein: 10}
+	foodConsumption := *previous
+
+	fillNutrition(&foodConsumption, catalogFood, previous)
+
+	if foodConsumption.Kcal != 300 || foodConsumption.Protein != 10 {
+		t.Fatalf("expected the nutrients of the client, got %v kcal and %v g of protein", foodConsumption.Kcal, foodConsumption.Protein)
+	}
+}
+
+func TestTransactionResolverDoesNotOverdrawATransaction(t *testing.T) {
+	expiringFirst := &dto.FoodTransactionDto{ID: uuid.New(), AvailableQuantity: 100, ExpirationDate: "2026-10-20"}
+	expiringLater := &dto.FoodTransactionDto{ID: uuid.New(), AvailableQuantity: 100, ExpirationDate: "2026-11-20"}
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_ = json.NewEncoder(w).Encode(dto.BaseResponse[[]*dto.FoodTransactionDto]{Body: []*dto.FoodTransactionDto{expiringLater, expiringFirst}})
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("GROCERY_BASE_URL", server.URL)
+	resolver := FoodConsumptionService{groceryService: NewGroceryService()}.newTransactionResolver("token")
+	foodId := uuid.New()
+
+	// The first 80 g come from the transaction expiring first, which has 20 g left: the next 80 g come from the other.
+	first, err := resolver.resolve(foodId, "pasta", 80)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := resolver.resolve(foodId, "pasta", 80)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = resolver.resolve(foodId, "pasta", 80)
+
+	if first != expiringFirst.ID || second != expiringLater.ID {
+		t.Fatalf("expected %s then %s, got %s then %s", expiringFirst.ID, expiringLater.ID, first, second)
+	}
+	var domainError *DomainError
+	if !errors.As(err, &domainError) || domainError.Kind != Conflict {
+		t.Fatalf("expected a conflict error once both transactions are used, got %v", err)
+	}
+	if requests != 1 {
+		t.Fatalf("expected the transactions to be read once, got %d requests", requests)
+	}
+}
+
+func TestDeleteMealWithConsumptionsGivesBackOnlyWhatWasTaken(t *testing.T) {
+	foodConsumptionService, mock := newTestFoodConsumptionService(t, dto.FoodTransactionDto{})
+	meal := &model.Meal{ID: uuid.New(), UserId: "user-1", Status: model.Eaten}
+	taken := &model.FoodConsumption{ID: uuid.New(), MealID: meal.ID, FoodName: "pasta", FoodId: uuid.New(), TransactionId: uuid.New(), QuantityUsed: 80, Unit: "g"}
+	// The imported food consumption references the pantry but never took anything from it.
+	imported := &model.FoodConsumption{ID: uuid.New(), MealID: meal.ID, FoodName: "rice", FoodId: uuid.New(), TransactionId: uuid.New(), QuantityUsed: 60, Unit: "g"}
+	rows := sqlmock.NewRows([]string{"id", "meal_id", "food_name", "food_id", "transaction_id", "quantity_used", "unit"})
+	for _, foodConsumption := range []*model.FoodConsumption{taken, imported} {
+		rows.AddRow(foodConsumption.ID.String(), meal.ID.String(), foodConsumption.FoodName, foodConsumption.FoodId.String(), foodConsumption.TransactionId.String(), foodConsumption.QuantityUsed, "g")
+	}
+	mock.ExpectQuery(`FROM "food_consumption" AS "fc" WHERE .*meal_id = '` + meal.ID.String() + `'`).WillReturnRows(rows)
+	mock.ExpectQuery(`SELECT food_consumption_id, SUM\(quantity_delta\) AS quantity_delta FROM "grocery_outbox" AS "gro" WHERE .*status IN \('done', 'pending'\)\) GROUP BY "food_consumption_id"`).
+		WillReturnRows(sqlmock.NewRows([]string{"food_consumption_id", "quantity_delta"}).AddRow(taken.ID.String(), 80.0))
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "meal" AS "m" SET .*"stock_restored" = TRUE`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "meal" AS "m" SET "deleted_at"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "food_consumption" AS "fc" SET "deleted_at"`).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectQuery(`SELECT "m"."id", "m"."version" FROM "meal"`).WillReturnRows(versionRows(map[uuid.UUID]int{meal.ID: 2}))
+	mock.ExpectQuery(`SELECT "fc"."id", "fc"."version" FROM "food_consumption"`).WillReturnRows(versionRows(map[uuid.UUID]int{taken.ID: 1, imported.ID: 1}))
+	mock.ExpectQuery(`INSERT INTO "grocery_saga"`).WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+	// A single entry, giving back the quantity taken by the first food consumption.
+	mock.ExpectQuery(`INSERT INTO "grocery_outbox" .* VALUES \([^)]*'` + taken.ID.String() + `'[^)]*, -80, [^)]*\) RETURNING`).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+	mock.ExpectCommit()
+	mock.ExpectQuery(`UPDATE "grocery_outbox" .* RETURNING \*`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	err := foodConsumptionService.DeleteMealWithConsumptions(meal, true, "token")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLookupBarcode(t *testing.T) {
+	pasta := &dto.FoodAvailableDto{ID: uuid.New(), Barcode: "8001", Name: "pasta", AvailableQuantity: 500, Unit: "g"}
+	transaction := &dto.FoodTransactionDto{ID: uuid.New(), Quantity: 500, AvailableQuantity: 500, Price: 2}
+	grocery := http.NewServeMux()
+	grocery.HandleFunc("/api/item/", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(dto.BaseResponse[[]*dto.FoodAvailableDto]{Body: []*dto.FoodAvailableDto{pasta}})
+	})
+	grocery.HandleFunc("/api/item/"+pasta.ID.String()+"/transaction", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(dto.BaseResponse[[]*dto.FoodTransactionDto]{Body: []*dto.FoodTransactionDto{transaction}})
+	})
+	barcodes := []struct {
+		name    string
+		barcode string
+		found   bool
+	}{
+		{"in the pantry but not in the catalog", pasta.Barcode, true},
+		{"neither in the pantry nor in the catalog", "8002", false},
+	}
+	for _, barcode := range barcodes {
+		t.Run(barcode.name, func(t *testing.T) {
+			foodConsumptionService, mock := newTestFoodConsumptionServiceWithGrocery(t, grocery)
+			mock.ExpectQuery(`FROM "catalog_food" AS "cf" WHERE \(cf\.barcode = '` + barcode.barcode + `'\)`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+			lookupDto, err := foodConsumptionService.LookupBarcode(barcode.barcode, "pantry-1", 0, "user-1", "token")
+
+			if !barcode.found {
+				var domainError *DomainError
+				if !errors.As(err, &domainError) || domainError.Kind != NotFound {
+					t.Fatalf("expected a not found error, got %v", err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			} else {
+				// The draft uses the pantry food and its transaction, without the nutrients of a catalog food.
+				if lookupDto.CatalogFood != nil || lookupDto.Food == nil || lookupDto.Food.ID != pasta.ID {
+					t.Fatalf("expected only the pantry food, got %+v", lookupDto)
+				}
+				draft := lookupDto.Draft
+				if draft.FoodId != pasta.ID || draft.TransactionId != transaction.ID || draft.QuantityUsed != 100 || draft.Kcal != 0 {
+					t.Fatalf("expected a draft of 100 g of the pantry food without nutrients, got %+v", draft)
+				}
+				if draft.Cost != 0.4 {
+					t.Fatalf("expected the cost of 100 g of the transaction, got %v", draft.Cost)
+				}
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
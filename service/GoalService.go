@@ -0,0 +1,123 @@
+package service
+
+import (
+	"food-track-be/model"
+	"food-track-be/model/dto"
+	"food-track-be/repository"
+	"github.com/google/uuid"
+	"github.com/mashingan/smapping"
+	"log"
+	"time"
+)
+
+type GoalService struct {
+	repository     *repository.GoalRepository
+	mealRepository *repository.MealRepository
+}
+
+func NewGoalService(repository *repository.GoalRepository, mealRepository *repository.MealRepository) *GoalService {
+	return &GoalService{repository: repository, mealRepository: mealRepository}
+}
+
+func (s *GoalService) FindByUserId(userId string) (dto.GoalDto, error) {
+	goal, err := s.repository.FindByUserId(userId)
+	if err != nil {
+		log.Println(err)
+		return dto.GoalDto{}, err
+	}
+	return s.mapGoalToDto(goal)
+}
+
+func (s *GoalService) Create(goalDto dto.GoalDto) (dto.GoalDto, error) {
+	if _, err := s.repository.FindByUserId(goalDto.UserId); err == nil {
+		return dto.GoalDto{}, NewConflictError("goal already configured for the user")
+	}
+	goal := model.Goal{}
+	err := smapping.FillStruct(&goal, smapping.MapFields(&goalDto))
+	if err != nil {
+		log.Println(err)
+		return goalDto, err
+	}
+	goal.ID = uuid.New()
+	_, err = s.repository.Create(&goal)
+	if isUniqueViolation(err) {
+		// Another request created the goal after the check above.
+		return dto.GoalDto{}, NewConflictError("goal already configured for the user")
+	}
+	if err != nil {
+		log.Println(err)
+		return dto.GoalDto{}, err
+	}
+	return s.mapGoalToDto(&goal)
+}
+
+func (s *GoalService) Update(goalDto dto.GoalDto, userId string) (dto.GoalDto, error) {
+	goal, err := s.repository.FindByUserId(userId)
+	if err != nil {
+		return goalDto, err
+	}
+	goalDto.ID = goal.ID
+	goalDto.UserId = userId
+	err = smapping.FillStruct(goal, smapping.MapFields(&goalDto))
+	if err != nil {
+		return goalDto, err
+	}
+	_, err = s.repository.Update(goal, userId)
+	if err != nil {
+		return dto.GoalDto{}, err
+	}
+	return s.mapGoalToDto(goal)
+}
+
+func (s *GoalService) Delete(userId string) error {
+	goal, err := s.repository.FindByUserId(userId)
+	if err != nil {
+		return err
+	}
+	_, err = s.repository.Delete(goal, userId)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	return nil
+}
+
+// GetDailyProgress compares what the user consumed in the given day against the configured goal.
+func (s *GoalService) GetDailyProgress(date time.Time, userId string) (dto.GoalProgressDto, error) {
+	goal, err := s.repository.FindByUserId(userId)
+	if err != nil {
+		log.Println(err)
+		return dto.GoalProgressDto{}, err
+	}
+	consumed, err := s.mealRepository.GetConsumptionSumInDateRange(date, date, userId, model.Eaten)
+	if err != nil {
+		log.Println(err)
+		return dto.GoalProgressDto{}, err
+	}
+	return dto.GoalProgressDto{
+		Date:         date,
+		Kcal:         computeProgress(goal.Kcal, consumed.Kcal),
+		Protein:      computeProgress(goal.Protein, consumed.Protein),
+		Carbohydrate: computeProgress(goal.Carbohydrate, consumed.Carbohydrate),
+		Fat:          computeProgress(goal.Fat, consumed.Fat),
+		Budget:       computeProgress(goal.Budget, consumed.Cost),
+	}, nil
+}
+
+func (s *GoalService) mapGoalToDto(goal *model.Goal) (dto.GoalDto, error) {
+	goalDto := dto.GoalDto{}
+	err := smapping.FillStruct(&goalDto, smapping.MapFields(goal))
+	if err != nil {
+		log.Println(err)
+		return dto.GoalDto{}, err
+	}
+	return goalDto, nil
+}
+
+func computeProgress(target float32, consumed float64) dto.NutrientProgressDto {
+	return dto.NutrientProgressDto{
+		Target:    float64(target),
+		Consumed:  consumed,
+		Remaining: float64(target) - consumed,
+	}
+}
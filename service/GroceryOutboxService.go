@@ -0,0 +1,424 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"food-track-be/model"
+	"food-track-be/repository"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+	"log"
+	"math"
+	"time"
+)
+
+// errGroceryCredentialMissing is returned when an entry is retried by the worker without a service credential configured.
+var errGroceryCredentialMissing = NewUnauthorizedError("grocery service credential not configured")
+
+const (
+	// outboxMaxAttempts is the number of deliveries tried before the saga is compensated.
+	outboxMaxAttempts = 10
+	// outboxBaseBackoff is the delay before the first retry, doubled at every following attempt.
+	outboxBaseBackoff = 15 * time.Second
+	// outboxMaxBackoff caps the delay between two retries.
+	outboxMaxBackoff = 30 * time.Minute
+	// outboxLease is the time an entry stays reserved for the worker which claimed it.
+	outboxLease = time.Minute
+	// outboxBatchSize is the number of entries processed by the worker at every tick.
+	outboxBatchSize = 50
+)
+
+// GroceryOutboxService delivers to grocery-be the available quantity changes recorded in the grocery outbox.
+//
+// Every change of meals and food consumptions which uses the pantry is a saga: the local change, the saga and its
+// outbox entries are committed in a single database transaction, then the entries are delivered to grocery-be,
+// retrying with an exponential backoff. The first delivery is made during the request with the user's token, the retries
+// of the worker with the service credential. When an entry can't be delivered within outboxMaxAttempts, the whole saga
+// is compensated: if none of its meals and food consumptions has changed since, they are all restored as they were
+// before the saga and the quantities already applied by the other entries are given back; otherwise they are left as
+// they are and the food consumption of the entry is flagged as sync failed. An entry refused because of the
+// configuration or of the credentials is dead-lettered instead, since compensating it would delete what the user logged
+// only because the pantry couldn't be reached.
+type GroceryOutboxService struct {
+	repository                *repository.GroceryOutboxRepository
+	mealRepository            *repository.MealRepository
+	foodConsumptionRepository *repository.FoodConsumptionRepository
+	groceryService            *GroceryService
+}
+
+func NewGroceryOutboxService(repository *repository.GroceryOutboxRepository, mealRepository *repository.MealRepository, foodConsumptionRepository *repository.FoodConsumptionRepository, groceryService *GroceryService) *GroceryOutboxService {
+	return &GroceryOutboxService{repository: repository, mealRepository: mealRepository, foodConsumptionRepository: foodConsumptionRepository, groceryService: groceryService}
+}
+
+// NewSaga starts a saga of the user. The caller records the meals and food consumptions it changes and the outbox
+// entries it requires, then enqueues it.
+func (s *GroceryOutboxService) NewSaga(userId string) *model.GrocerySaga {
+	return &model.GrocerySaga{ID: uuid.New(), UserId: userId, Status: model.SagaPending}
+}
+
+// Enqueue stores the saga and its entries inside the database transaction of the change, after the change, together
+// with the version of every meal and food consumption it changed. A saga without entries isn't stored.
+func (s *GroceryOutboxService) Enqueue(tx bun.Tx, saga *model.GrocerySaga) error {
+	if len(saga.Entries) == 0 {
+		return nil
+	}
+	mealVersions, err := s.mealRepository.WithTx(tx).FindVersionsForUpdate(sagaMealIds(saga))
+	if err != nil {
+		return err
+	}
+	for _, sagaMeal := range saga.Meals {
+		sagaMeal.Version = mealVersions[sagaMeal.ID]
+	}
+	foodConsumptionVersions, err := s.foodConsumptionRepository.WithTx(tx).FindVersionsForUpdate(sagaFoodConsumptionIds(saga))
+	if err != nil {
+		return err
+	}
+	for _, sagaFoodConsumption := range saga.FoodConsumptions {
+		sagaFoodConsumption.Version = foodConsumptionVersions[sagaFoodConsumption.ID]
+	}
+
+	outboxRepository := s.repository.WithTx(tx)
+	_, err = outboxRepository.CreateSaga(saga)
+	if err != nil {
+		return err
+	}
+	_, err = outboxRepository.CreateAll(saga.Entries)
+	return err
+}
+
+// TakenQuantities returns, for the food consumptions whose quantity went through the outbox, the quantity they take from
+// the pantry, counting the changes still to deliver. The food consumptions which never did, like the imported ones or
+// the ones logged before the outbox, are missing, and so are the ones which don't reference a grocery transaction.
+func (s *GroceryOutboxService) TakenQuantities(foodConsumptions []*model.FoodConsumption) (map[uuid.UUID]float32, error) {
+	ids := make([]uuid.UUID, 0, len(foodConsumptions))
+	for _, foodConsumption := range foodConsumptions {
+		if isLinkedToGrocery(foodConsumption) {
+			ids = append(ids, foodConsumption.ID)
+		}
+	}
+	return s.repository.SumQuantityDeltas(ids)
+}
+
+// ProcessSaga tries to deliver right away the entries of the saga with the token of the user, leaving to the worker the
+// ones which fail.
+func (s *GroceryOutboxService) ProcessSaga(sagaId uuid.UUID, token string) {
+	entries, err := s.repository.ClaimPendingForSaga(sagaId, outboxLease)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	s.processAll(entries, token)
+}
+
+// ProcessPending delivers a batch of the pending entries whose next attempt is due.
+func (s *GroceryOutboxService) ProcessPending() {
+	entries, err := s.repository.ClaimPending(outboxBatchSize, outboxLease)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	s.processAll(entries, s.groceryService.serviceToken)
+}
+
+// Start runs the outbox worker in background, processing the pending entries at every interval.
+func (s *GroceryOutboxService) Start(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			s.ProcessPending()
+		}
+	}()
+}
+
+// processAll processes the claimed entries, skipping the ones whose saga has been compensated by a previous entry.
+func (s *GroceryOutboxService) processAll(entries []*model.GroceryOutbox, token string) {
+	compensatedSagas := make(map[uuid.UUID]bool)
+	for _, entry := range entries {
+		if compensatedSagas[entry.SagaId] {
+			continue
+		}
+		if s.process(entry, token) {
+			compensatedSagas[entry.SagaId] = true
+		}
+	}
+}
+
+// process delivers the entry, returning true if its saga has been compensated.
+func (s *GroceryOutboxService) process(entry *model.GroceryOutbox, token string) bool {
+	err := s.apply(entry, token)
+	if err == nil {
+		entry.Status = model.OutboxDone
+		entry.LastError = ""
+		s.save(entry)
+		_, err = s.repository.CompleteSaga(entry.SagaId)
+		if err != nil {
+			log.Println(err)
+		}
+		return false
+	}
+
+	log.Println("failed to deliver grocery outbox entry", entry.ID.String(), ":", err)
+	entry.Attempts++
+	entry.LastError = err.Error()
+	if entry.Attempts < outboxMaxAttempts {
+		backoff := time.Duration(float64(outboxBaseBackoff) * math.Pow(2, float64(entry.Attempts-1)))
+		if backoff > outboxMaxBackoff {
+			backoff = outboxMaxBackoff
+		}
+		entry.NextAttemptAt = time.Now().Add(backoff)
+		s.save(entry)
+		return false
+	}
+
+	if !isCompensable(err) {
+		err = s.deadLetter(entry)
+		if err != nil {
+			log.Println("failed to dead-letter grocery outbox entry", entry.ID.String(), ":", err)
+			entry.NextAttemptAt = time.Now().Add(outboxMaxBackoff)
+			s.save(entry)
+		}
+		return false
+	}
+	compensated, err := s.compensate(entry)
+	if err != nil {
+		// The entry is kept pending, so that the compensation is retried at the next attempt.
+		log.Println("failed to compensate grocery outbox saga", entry.SagaId.String(), ":", err)
+		entry.NextAttemptAt = time.Now().Add(outboxMaxBackoff)
+		s.save(entry)
+		return false
+	}
+	return compensated
+}
+
+// apply removes the quantity of the entry from the available quantity of the grocery-be transaction. grocery-be only
+// updates whole transactions, so the transaction is read, changed and written back. The quantity written is saved in the
+// entry before it is sent: a retry which finds it already in the transaction doesn't apply the change again.
+func (s *GroceryOutboxService) apply(entry *model.GroceryOutbox, token string) error {
+	if token == "" {
+		return errGroceryCredentialMissing
+	}
+	transactionDto, err := s.groceryService.GetTransactionDetail(entry.FoodId, entry.TransactionId, token)
+	if err != nil {
+		return err
+	}
+	if entry.TargetAvailableQuantity != nil && transactionDto.AvailableQuantity == *entry.TargetAvailableQuantity {
+		return nil
+	}
+	targetAvailableQuantity := transactionDto.AvailableQuantity - entry.QuantityDelta
+	entry.TargetAvailableQuantity = &targetAvailableQuantity
+	_, err = s.repository.Update(entry)
+	if err != nil {
+		return err
+	}
+	transactionDto.AvailableQuantity = targetAvailableQuantity
+	_, err = s.groceryService.UpdateFoodTransaction(entry.FoodId, transactionDto, token)
+	return err
+}
+
+// isCompensable reports whether the delivery failed in a way the compensation of the saga settles: grocery-be failing or
+// being unreachable. A missing or rejected credential and a missing transaction aren't: they have to be fixed by an
+// operator, not by deleting the change of the user.
+func isCompensable(err error) bool {
+	kind := ToDomainError(err).Kind
+	return kind != Unauthorized && kind != NotFound
+}
+
+// deadLetter sets aside the entry which can't be delivered nor compensated, flagging its food consumption and its saga
+// as sync failed. Nothing the user logged is deleted.
+func (s *GroceryOutboxService) deadLetter(entry *model.GroceryOutbox) error {
+	return s.foodConsumptionRepository.RunInTx(func(tx bun.Tx) error {
+		saga, err := s.repository.WithTx(tx).FindSagaByIdForUpdate(entry.SagaId)
+		if errors.Is(err, sql.ErrNoRows) {
+			saga = nil
+		} else if err != nil {
+			return err
+		}
+		return s.fail(tx, saga, entry, model.OutboxDeadLetter)
+	})
+}
+
+// compensate handles the entry which couldn't be delivered, returning true if its saga has been compensated.
+//
+// The saga is compensated only if it is still pending, the entry isn't itself a compensation and none of the meals and
+// food consumptions of the saga has changed since: they are restored as they were before the saga, the entries not yet
+// delivered are dropped and the ones already delivered are reverted by new compensation entries. Otherwise the entry
+// fails and its food consumption is flagged as sync failed, leaving the rest of the saga untouched.
+func (s *GroceryOutboxService) compensate(failed *model.GroceryOutbox) (bool, error) {
+	compensated := false
+	err := s.foodConsumptionRepository.RunInTx(func(tx bun.Tx) error {
+		saga, err := s.repository.WithTx(tx).FindSagaByIdForUpdate(failed.SagaId)
+		if errors.Is(err, sql.ErrNoRows) {
+			// The entry was enqueued before sagas were stored: its change can't be undone.
+			saga = nil
+		} else if err != nil {
+			return err
+		}
+
+		if saga != nil && saga.Status == model.SagaPending && !failed.IsCompensation {
+			unchanged, err := s.isUnchanged(tx, saga)
+			if err != nil {
+				return err
+			}
+			if unchanged {
+				compensated = true
+				return s.restore(tx, saga, failed)
+			}
+		}
+
+		return s.fail(tx, saga, failed, model.OutboxFailed)
+	})
+	if err != nil {
+		compensated = false
+	}
+	return compensated, err
+}
+
+// fail settles the entry with the status, flagging its food consumption and its saga, if still pending, as sync failed.
+func (s *GroceryOutboxService) fail(tx bun.Tx, saga *model.GrocerySaga, entry *model.GroceryOutbox, status model.GroceryOutboxStatus) error {
+	outboxRepository := s.repository.WithTx(tx)
+	entry.Status = status
+	_, err := outboxRepository.Update(entry)
+	if err != nil {
+		return err
+	}
+	_, err = s.foodConsumptionRepository.WithTx(tx).MarkSyncFailed(entry.FoodConsumptionId)
+	if err != nil {
+		return err
+	}
+	if saga == nil || saga.Status != model.SagaPending {
+		return nil
+	}
+	saga.Status = model.SagaSyncFailed
+	_, err = outboxRepository.UpdateSagaStatus(saga)
+	return err
+}
+
+// isUnchanged reports whether every meal and food consumption of the saga still has the version it had right after the
+// saga, locking them until the end of the transaction.
+func (s *GroceryOutboxService) isUnchanged(tx bun.Tx, saga *model.GrocerySaga) (bool, error) {
+	mealVersions, err := s.mealRepository.WithTx(tx).FindVersionsForUpdate(sagaMealIds(saga))
+	if err != nil {
+		return false, err
+	}
+	for _, sagaMeal := range saga.Meals {
+		version, ok := mealVersions[sagaMeal.ID]
+		if !ok || version != sagaMeal.Version {
+			return false, nil
+		}
+	}
+	foodConsumptionVersions, err := s.foodConsumptionRepository.WithTx(tx).FindVersionsForUpdate(sagaFoodConsumptionIds(saga))
+	if err != nil {
+		return false, err
+	}
+	for _, sagaFoodConsumption := range saga.FoodConsumptions {
+		version, ok := foodConsumptionVersions[sagaFoodConsumption.ID]
+		if !ok || version != sagaFoodConsumption.Version {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// restore puts back the meals and food consumptions of the saga as they were before it and settles its entries.
+func (s *GroceryOutboxService) restore(tx bun.Tx, saga *model.GrocerySaga, failed *model.GroceryOutbox) error {
+	mealRepository := s.mealRepository.WithTx(tx)
+	foodConsumptionRepository := s.foodConsumptionRepository.WithTx(tx)
+	outboxRepository := s.repository.WithTx(tx)
+
+	// The meals are restored before their food consumptions and the created meals deleted after them.
+	for _, sagaMeal := range saga.Meals {
+		if sagaMeal.Previous != nil {
+			_, err := mealRepository.Upsert(sagaMeal.Previous)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	for _, sagaFoodConsumption := range saga.FoodConsumptions {
+		var err error
+		if sagaFoodConsumption.Previous != nil {
+			_, err = foodConsumptionRepository.Upsert(sagaFoodConsumption.Previous)
+		} else {
+			_, err = foodConsumptionRepository.DeleteById(sagaFoodConsumption.ID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	for _, sagaMeal := range saga.Meals {
+		if sagaMeal.Previous == nil {
+			_, err := mealRepository.DeleteById(sagaMeal.ID)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	entries, err := outboxRepository.FindAllBySagaId(saga.ID)
+	if err != nil {
+		return err
+	}
+	var reverts []*model.GroceryOutbox
+	for _, entry := range entries {
+		if entry.ID == failed.ID {
+			entry = failed
+		}
+		switch entry.Status {
+		case model.OutboxDone:
+			if !entry.IsCompensation {
+				revert := &model.GroceryOutbox{
+					ID:                uuid.New(),
+					SagaId:            saga.ID,
+					UserId:            entry.UserId,
+					FoodConsumptionId: entry.FoodConsumptionId,
+					FoodId:            entry.FoodId,
+					TransactionId:     entry.TransactionId,
+					QuantityDelta:     -entry.QuantityDelta,
+					IsCompensation:    true,
+					Status:            model.OutboxPending,
+					NextAttemptAt:     time.Now(),
+				}
+				reverts = append(reverts, revert)
+			}
+		case model.OutboxPending:
+			entry.Status = model.OutboxCompensated
+			_, err := outboxRepository.Update(entry)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if len(reverts) > 0 {
+		_, err = outboxRepository.CreateAll(reverts)
+		if err != nil {
+			return err
+		}
+	}
+
+	saga.Status = model.SagaCompensated
+	_, err = outboxRepository.UpdateSagaStatus(saga)
+	return err
+}
+
+func (s *GroceryOutboxService) save(entry *model.GroceryOutbox) {
+	_, err := s.repository.Update(entry)
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+func sagaMealIds(saga *model.GrocerySaga) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(saga.Meals))
+	for _, sagaMeal := range saga.Meals {
+		ids = append(ids, sagaMeal.ID)
+	}
+	return ids
+}
+
+func sagaFoodConsumptionIds(saga *model.GrocerySaga) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(saga.FoodConsumptions))
+	for _, sagaFoodConsumption := range saga.FoodConsumptions {
+		ids = append(ids, sagaFoodConsumption.ID)
+	}
+	return ids
+}
@@ -0,0 +1,330 @@
+package service
+
+import (
+	"encoding/json"
+	"food-track-be/model"
+	"food-track-be/model/dto"
+	"food-track-be/repository"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// groceryRequest is a request received by the fake grocery-be.
+type groceryRequest struct {
+	method        string
+	path          string
+	authorization string
+}
+
+// fakeGrocery is a grocery-be holding a single transaction. It records the requests it receives and answers the first
+// ones with the statuses given, without handling them.
+type fakeGrocery struct {
+	mutex             sync.Mutex
+	availableQuantity float32
+	statuses          []int
+	// lostUpdates is the number of updates which are applied but whose response is lost.
+	lostUpdates int
+	requests    []groceryRequest
+}
+
+func (f *fakeGrocery) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.requests = append(f.requests, groceryRequest{method: r.Method, path: r.URL.Path, authorization: r.Header.Get("Authorization")})
+	if len(f.statuses) > 0 {
+		w.WriteHeader(f.statuses[0])
+		f.statuses = f.statuses[1:]
+		return
+	}
+	if r.Method == http.MethodPatch {
+		var body dto.FoodTransactionDto
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.availableQuantity = body.AvailableQuantity
+		if f.lostUpdates > 0 {
+			f.lostUpdates--
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+	}
+	_ = json.NewEncoder(w).Encode(dto.BaseResponse[dto.FoodTransactionDto]{Body: dto.FoodTransactionDto{AvailableQuantity: f.availableQuantity}})
+}
+
+func newTestGroceryOutboxService(t *testing.T, grocery *fakeGrocery) (*GroceryOutboxService, sqlmock.Sqlmock) {
+	sqlDb, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := bun.NewDB(sqlDb, pgdialect.New())
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	server := httptest.NewServer(grocery)
+	t.Cleanup(server.Close)
+	t.Setenv("GROCERY_BASE_URL", server.URL)
+	t.Setenv("GROCERY_SERVICE_TOKEN", "service-token")
+
+	outboxService := NewGroceryOutboxService(
+		repository.NewGroceryOutboxRepository(*db),
+		repository.NewMealRepository(*db),
+		repository.NewFoodConsumptionRepository(*db),
+		NewGroceryService(),
+	)
+	return outboxService, mock
+}
+
+func newTestOutboxEntry(sagaId uuid.UUID, status model.GroceryOutboxStatus, quantityDelta float32) *model.GroceryOutbox {
+	return &model.GroceryOutbox{
+		ID:                uuid.New(),
+		SagaId:            sagaId,
+		UserId:            "user-1",
+		FoodConsumptionId: uuid.New(),
+		FoodId:            uuid.New(),
+		TransactionId:     uuid.New(),
+		QuantityDelta:     quantityDelta,
+		Status:            status,
+		NextAttemptAt:     time.Now(),
+		CreatedAt:         time.Now(),
+	}
+}
+
+func outboxRows(entries ...*model.GroceryOutbox) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"id", "saga_id", "user_id", "food_consumption_id", "food_id", "transaction_id", "quantity_delta", "target_available_quantity", "is_compensation", "status", "attempts", "next_attempt_at", "created_at"})
+	for _, entry := range entries {
+		var targetAvailableQuantity any
+		if entry.TargetAvailableQuantity != nil {
+			targetAvailableQuantity = float64(*entry.TargetAvailableQuantity)
+		}
+		rows.AddRow(entry.ID.String(), entry.SagaId.String(), entry.UserId, entry.FoodConsumptionId.String(), entry.FoodId.String(), entry.TransactionId.String(), entry.QuantityDelta, targetAvailableQuantity, entry.IsCompensation, string(entry.Status), entry.Attempts, entry.NextAttemptAt, entry.CreatedAt)
+	}
+	return rows
+}
+
+func sagaRows(t *testing.T, saga *model.GrocerySaga) *sqlmock.Rows {
+	meals, err := json.Marshal(saga.Meals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	foodConsumptions, err := json.Marshal(saga.FoodConsumptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sqlmock.NewRows([]string{"id", "user_id", "status", "meals", "food_consumptions", "created_at"}).
+		AddRow(saga.ID.String(), saga.UserId, string(saga.Status), meals, foodConsumptions, time.Now())
+}
+
+func versionRows(versions map[uuid.UUID]int) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"id", "version"})
+	for id, version := range versions {
+		rows.AddRow(id.String(), version)
+	}
+	return rows
+}
+
+func TestProcessSagaDeliversEntriesWithTheUserToken(t *testing.T) {
+	grocery := &fakeGrocery{availableQuantity: 500}
+	outboxService, mock := newTestGroceryOutboxService(t, grocery)
+	entry := newTestOutboxEntry(uuid.New(), model.OutboxPending, 150)
+
+	mock.ExpectQuery(`UPDATE "grocery_outbox" .* WHERE \(id IN \(SELECT .*\(saga_id = '` + entry.SagaId.String() + `'.* FOR UPDATE SKIP LOCKED\)\) RETURNING \*`).
+		WillReturnRows(outboxRows(entry))
+	mock.ExpectExec(`UPDATE "grocery_outbox" AS "gro" SET .*"target_available_quantity" = 350, .*"status" = 'pending'.* WHERE \(status = 'pending'\) AND \("gro"."id" = '` + entry.ID.String() + `'\)`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "grocery_outbox" AS "gro" SET .*"status" = 'done'.* WHERE \(status = 'pending'\) AND \("gro"."id" = '` + entry.ID.String() + `'\)`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "grocery_saga" .* SET status = 'done' WHERE \(id = '` + entry.SagaId.String() + `'\) AND \(status = 'pending'\) AND \(NOT EXISTS`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	outboxService.ProcessSaga(entry.SagaId, "user-token")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+	expected := []groceryRequest{
+		{http.MethodGet, "/api/item/" + entry.FoodId.String() + "/transaction/" + entry.TransactionId.String(), "Bearer user-token"},
+		{http.MethodPatch, "/api/item/" + entry.FoodId.String() + "/transaction", "Bearer user-token"},
+	}
+	if !reflect.DeepEqual(grocery.requests, expected) {
+		t.Fatalf("expected the requests %v, got %v", expected, grocery.requests)
+	}
+	if grocery.availableQuantity != 350 {
+		t.Errorf("expected 350 available, got %v", grocery.availableQuantity)
+	}
+}
+
+func TestProcessPendingDoesNotApplyTwiceAfterALostResponse(t *testing.T) {
+	grocery := &fakeGrocery{availableQuantity: 500, lostUpdates: 1}
+	outboxService, mock := newTestGroceryOutboxService(t, grocery)
+	entry := newTestOutboxEntry(uuid.New(), model.OutboxPending, 40)
+
+	mock.ExpectQuery(`UPDATE "grocery_outbox" .* RETURNING \*`).WillReturnRows(outboxRows(entry))
+	mock.ExpectExec(`UPDATE "grocery_outbox" AS "gro" SET .*"target_available_quantity" = 460`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "grocery_outbox" AS "gro" SET .*"status" = 'pending', "attempts" = 1, "last_error" = '.*502.*'.* WHERE \(status = 'pending'\) AND \("gro"."id" = '` + entry.ID.String() + `'\)`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	outboxService.ProcessSaga(entry.SagaId, "user-token")
+
+	// The update was applied: the worker finds the quantity it would write and completes the entry without a request.
+	target := float32(460)
+	entry.TargetAvailableQuantity = &target
+	entry.Attempts = 1
+	mock.ExpectQuery(`UPDATE "grocery_outbox" .* RETURNING \*`).WillReturnRows(outboxRows(entry))
+	mock.ExpectExec(`UPDATE "grocery_outbox" AS "gro" SET .*"status" = 'done'`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "grocery_saga"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	outboxService.ProcessPending()
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+	if grocery.availableQuantity != 460 {
+		t.Errorf("expected 460 available, got %v", grocery.availableQuantity)
+	}
+	if len(grocery.requests) != 3 {
+		t.Fatalf("expected 3 requests to grocery-be, got %d", len(grocery.requests))
+	}
+	if grocery.requests[2].authorization != "Bearer service-token" {
+		t.Errorf("expected the worker to use the service credential, got %q", grocery.requests[2].authorization)
+	}
+}
+
+func TestProcessPendingRetriesWithoutServiceCredential(t *testing.T) {
+	grocery := &fakeGrocery{availableQuantity: 500}
+	outboxService, mock := newTestGroceryOutboxService(t, grocery)
+	outboxService.groceryService.serviceToken = ""
+	entry := newTestOutboxEntry(uuid.New(), model.OutboxPending, 40)
+	entry.Attempts = 1
+
+	mock.ExpectQuery(`UPDATE "grocery_outbox" .* RETURNING \*`).WillReturnRows(outboxRows(entry))
+	mock.ExpectExec(`UPDATE "grocery_outbox" AS "gro" SET .*"status" = 'pending', "attempts" = 2, "last_error" = 'grocery service credential not configured'`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	outboxService.ProcessPending()
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+	if len(grocery.requests) != 0 {
+		t.Fatalf("expected no request to grocery-be, got %d", len(grocery.requests))
+	}
+}
+
+func TestProcessPendingDeadLettersRejectedCredentialInsteadOfCompensating(t *testing.T) {
+	grocery := &fakeGrocery{availableQuantity: 500, statuses: []int{http.StatusUnauthorized}}
+	outboxService, mock := newTestGroceryOutboxService(t, grocery)
+	saga := outboxService.NewSaga("user-1")
+	created := &model.FoodConsumption{ID: uuid.New(), MealID: uuid.New(), FoodName: "pasta", QuantityUsed: 40, Unit: "g"}
+	saga.AddFoodConsumption(created.ID, nil)
+	entry := newTestOutboxEntry(saga.ID, model.OutboxPending, 40)
+	entry.FoodConsumptionId = created.ID
+	entry.Attempts = outboxMaxAttempts - 1
+
+	// The food consumption created by the saga is kept: the mock fails the delete a compensation would run.
+	mock.ExpectQuery(`UPDATE "grocery_outbox" .* RETURNING \*`).WillReturnRows(outboxRows(entry))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT .* FROM "grocery_saga" AS "grs" WHERE \(id = '` + saga.ID.String() + `'\) FOR UPDATE`).
+		WillReturnRows(sagaRows(t, saga))
+	mock.ExpectExec(`UPDATE "grocery_outbox" AS "gro" SET .*"status" = 'dead_letter', "attempts" = 10, .* WHERE \(status = 'pending'\) AND \("gro"."id" = '` + entry.ID.String() + `'\)`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "food_consumption" AS "fc" SET sync_failed = TRUE WHERE \(id = '` + created.ID.String() + `'\)`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "grocery_saga" AS "grs" SET "status" = 'sync_failed'`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	outboxService.ProcessPending()
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+	if grocery.availableQuantity != 500 {
+		t.Errorf("expected the pantry untouched, got %v available", grocery.availableQuantity)
+	}
+}
+
+func TestCompensateRestoresUnchangedSaga(t *testing.T) {
+	outboxService, mock := newTestGroceryOutboxService(t, &fakeGrocery{})
+	saga := outboxService.NewSaga("user-1")
+	updated := &model.FoodConsumption{ID: uuid.New(), MealID: uuid.New(), FoodName: "pasta", QuantityUsed: 80, Unit: "g"}
+	created := &model.FoodConsumption{ID: uuid.New(), MealID: updated.MealID, FoodName: "sauce", QuantityUsed: 50, Unit: "g"}
+	saga.AddFoodConsumption(updated.ID, updated)
+	saga.AddFoodConsumption(created.ID, nil)
+	saga.FoodConsumptions[0].Version = 3
+	delivered := newTestOutboxEntry(saga.ID, model.OutboxDone, 50)
+	delivered.FoodConsumptionId = created.ID
+	failed := newTestOutboxEntry(saga.ID, model.OutboxPending, 20)
+	failed.FoodConsumptionId = updated.ID
+	failed.Attempts = outboxMaxAttempts
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT .* FROM "grocery_saga" AS "grs" WHERE \(id = '` + saga.ID.String() + `'\) FOR UPDATE`).
+		WillReturnRows(sagaRows(t, saga))
+	mock.ExpectQuery(`SELECT "fc"."id", "fc"."version" FROM "food_consumption" AS "fc" WHERE \(id IN \(.*\)\) FOR UPDATE`).
+		WillReturnRows(versionRows(map[uuid.UUID]int{updated.ID: 3, created.ID: 0}))
+	mock.ExpectQuery(`INSERT INTO "food_consumption" .*'` + updated.ID.String() + `'.*ON CONFLICT \(id\) DO UPDATE`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(updated.ID.String()))
+	mock.ExpectExec(`DELETE FROM "food_consumption" AS "fc" WHERE \(id = '` + created.ID.String() + `'\)`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT .* FROM "grocery_outbox" AS "gro" WHERE \(saga_id = '` + saga.ID.String() + `'\)`).
+		WillReturnRows(outboxRows(delivered, failed))
+	mock.ExpectExec(`UPDATE "grocery_outbox" AS "gro" SET .*"status" = 'compensated'.* WHERE \(status = 'pending'\) AND \("gro"."id" = '` + failed.ID.String() + `'\)`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO "grocery_outbox" .*'` + created.ID.String() + `', '` + delivered.FoodId.String() + `', '` + delivered.TransactionId.String() + `', -50, DEFAULT, TRUE, 'pending'`).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+	mock.ExpectExec(`UPDATE "grocery_saga" AS "grs" SET "status" = 'compensated' WHERE \("grs"."id" = '` + saga.ID.String() + `'\)`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	compensated, err := outboxService.compensate(failed)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !compensated {
+		t.Error("expected the saga to be compensated")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCompensateMarksSyncFailedWhenSagaChanged(t *testing.T) {
+	outboxService, mock := newTestGroceryOutboxService(t, &fakeGrocery{})
+	saga := outboxService.NewSaga("user-1")
+	updated := &model.FoodConsumption{ID: uuid.New(), MealID: uuid.New(), FoodName: "pasta", QuantityUsed: 80, Unit: "g"}
+	saga.AddFoodConsumption(updated.ID, updated)
+	saga.FoodConsumptions[0].Version = 3
+	failed := newTestOutboxEntry(saga.ID, model.OutboxPending, 20)
+	failed.FoodConsumptionId = updated.ID
+	failed.Attempts = outboxMaxAttempts
+
+	// The food consumption has been edited again after the saga: the edit must not be overwritten.
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT .* FROM "grocery_saga"`).WillReturnRows(sagaRows(t, saga))
+	mock.ExpectQuery(`SELECT "fc"."id", "fc"."version" FROM "food_consumption"`).
+		WillReturnRows(versionRows(map[uuid.UUID]int{updated.ID: 4}))
+	mock.ExpectExec(`UPDATE "grocery_outbox" AS "gro" SET .*"status" = 'failed'.* WHERE \(status = 'pending'\) AND \("gro"."id" = '` + failed.ID.String() + `'\)`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "food_consumption" AS "fc" SET sync_failed = TRUE WHERE \(id = '` + updated.ID.String() + `'\)`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "grocery_saga" AS "grs" SET "status" = 'sync_failed'`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	compensated, err := outboxService.compensate(failed)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if compensated {
+		t.Error("expected the saga not to be compensated")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
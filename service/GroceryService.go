@@ -3,7 +3,6 @@ package service
 import (
 	"bytes"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"food-track-be/model/dto"
 	"github.com/google/uuid"
@@ -11,18 +10,27 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
 	"time"
 )
 
+// GroceryService calls grocery-be. The requests made for a user forward the user's token; the pantry updates retried by
+// the grocery outbox worker, long after the token has expired, authenticate with the optional service credential.
 type GroceryService struct {
 	baseUrl        string
+	serviceToken   string
 	circuitBreaker *gobreaker.CircuitBreaker
 }
 
 func NewGroceryService() *GroceryService {
+	serviceToken := os.Getenv("GROCERY_SERVICE_TOKEN")
+	if serviceToken == "" {
+		log.Println("GROCERY_SERVICE_TOKEN is not set: the pantry updates which fail during the request won't be retried")
+	}
 	return &GroceryService{
-		baseUrl: os.Getenv("GROCERY_BASE_URL"),
+		baseUrl:      os.Getenv("GROCERY_BASE_URL"),
+		serviceToken: serviceToken,
 		circuitBreaker: gobreaker.NewCircuitBreaker(gobreaker.Settings{
 			Name:        "GroceryService",
 			MaxRequests: 5,
@@ -42,6 +50,10 @@ func (s *GroceryService) getCall(url string, token string) ([]byte, error) {
 	result, err := s.circuitBreaker.Execute(func() (interface{}, error) {
 		response, err := http.DefaultClient.Do(request)
 		if err != nil {
+			return nil, &DomainError{Kind: UpstreamUnavailable, Message: "grocery service unreachable", Err: err}
+		}
+		defer response.Body.Close()
+		if err := responseError(response); err != nil {
 			return nil, err
 		}
 		return io.ReadAll(response.Body)
@@ -56,28 +68,51 @@ func (s *GroceryService) getCall(url string, token string) ([]byte, error) {
 func (s *GroceryService) patchCall(url string, body any, token string) ([]byte, error) {
 	var buf bytes.Buffer
 	err := json.NewEncoder(&buf).Encode(body)
+	if err != nil {
+		return nil, err
+	}
 	request, err := http.NewRequest(http.MethodPatch, url, &buf)
 	if err != nil {
 		return nil, err
 	}
-	request.Header.Add("Content-Type", "application/json")
-	request.Header.Add("Authorization", "Bearer "+token)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "Bearer "+token)
 	response, err := http.DefaultClient.Do(request)
 	if err != nil {
+		return nil, &DomainError{Kind: UpstreamUnavailable, Message: "grocery service unreachable", Err: err}
+	}
+	defer response.Body.Close()
+	if err := responseError(response); err != nil {
 		return nil, err
 	}
 	return io.ReadAll(response.Body)
 }
 
+// responseError converts the error statuses of grocery-be: a rejected credential is Unauthorized, a missing resource
+// NotFound and any failure of grocery-be UpstreamFailure.
+func responseError(response *http.Response) error {
+	message := fmt.Sprintf("grocery service answered %d", response.StatusCode)
+	switch {
+	case response.StatusCode == http.StatusUnauthorized, response.StatusCode == http.StatusForbidden:
+		return &DomainError{Kind: Unauthorized, Message: message}
+	case response.StatusCode == http.StatusNotFound:
+		return &DomainError{Kind: NotFound, Message: message}
+	case response.StatusCode >= http.StatusInternalServerError:
+		return &DomainError{Kind: UpstreamFailure, Message: message}
+	default:
+		return nil
+	}
+}
+
 func (s *GroceryService) GetAllAvailableFood(token string, pantryId string) ([]*dto.FoodAvailableDto, error) {
 	var response dto.BaseResponse[[]*dto.FoodAvailableDto]
-	responseData, err := s.getCall(s.baseUrl+"/api/item/?pantryId="+pantryId, token)
+	responseData, err := s.getCall(s.baseUrl+"/api/item/?pantryId="+url.QueryEscape(pantryId), token)
 	if err != nil {
 		return nil, err
 	}
 	err = json.Unmarshal(responseData, &response)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+		return nil, &DomainError{Kind: UpstreamFailure, Message: "failed to unmarshal response", Err: err}
 	}
 
 	// Return the list of available food items
@@ -92,7 +127,7 @@ func (s *GroceryService) GetAvailableTransactionForFood(foodId uuid.UUID, token
 	}
 	err = json.Unmarshal(responseData, &response)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+		return nil, &DomainError{Kind: UpstreamFailure, Message: "failed to unmarshal response", Err: err}
 	}
 	return response.Body, nil
 }
@@ -105,7 +140,7 @@ func (s *GroceryService) GetTransactionDetail(foodId uuid.UUID, transactionId uu
 	}
 	err = json.Unmarshal(responseData, &response)
 	if err != nil {
-		return dto.FoodTransactionDto{}, fmt.Errorf("failed to unmarshal response: %w", err)
+		return dto.FoodTransactionDto{}, &DomainError{Kind: UpstreamFailure, Message: "failed to unmarshal response", Err: err}
 	}
 	return response.Body, nil
 }
@@ -119,10 +154,10 @@ func (s *GroceryService) UpdateFoodTransaction(foodId uuid.UUID, foodTransaction
 	}
 	err = json.Unmarshal(result, &response)
 	if err != nil {
-		return dto.FoodTransactionDto{}, fmt.Errorf("failed to unmarshal response: %w", err)
+		return dto.FoodTransactionDto{}, &DomainError{Kind: UpstreamFailure, Message: "failed to unmarshal response", Err: err}
 	}
 	if response.ErrorMessage != "" {
-		return dto.FoodTransactionDto{}, errors.New(response.ErrorMessage)
+		return dto.FoodTransactionDto{}, &DomainError{Kind: UpstreamFailure, Message: response.ErrorMessage}
 	}
 	return response.Body, nil
 }
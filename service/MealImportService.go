@@ -0,0 +1,312 @@
+package service
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"food-track-be/model"
+	"food-track-be/model/dto"
+	"food-track-be/repository"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+	"io"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// mealImportBatchSize is the number of rows inserted by a single statement of the import.
+const mealImportBatchSize = 1000
+
+// MealImportService loads meals and their food consumptions from files with the same shape of the meal export.
+//
+// Imported meals are historical data: they get new ids, belong to the authenticated user and don't update the pantry.
+// The quantities of their food consumptions are converted to the standard unit like the ones created through the API.
+type MealImportService struct {
+	mealRepository            *repository.MealRepository
+	foodConsumptionRepository *repository.FoodConsumptionRepository
+	unitService               *UnitService
+}
+
+func NewMealImportService(mealRepository *repository.MealRepository, foodConsumptionRepository *repository.FoodConsumptionRepository, unitService *UnitService) *MealImportService {
+	return &MealImportService{mealRepository: mealRepository, foodConsumptionRepository: foodConsumptionRepository, unitService: unitService}
+}
+
+// importedMeal is a meal read from the import file, with the rows it has been read from.
+type importedMeal struct {
+	meal                *model.Meal
+	row                 int
+	foodConsumptions    []*model.FoodConsumption
+	foodConsumptionRows []int
+}
+
+// Import validates every row of the file and, if all of them are valid and dryRun is false, inserts all the meals and
+// food consumptions in a single transaction. format is either json or csv.
+func (s *MealImportService) Import(reader io.Reader, format string, userId string, dryRun bool) (dto.MealImportResultDto, error) {
+	result := dto.MealImportResultDto{DryRun: dryRun, Errors: make([]dto.MealImportErrorDto, 0)}
+	var meals []*importedMeal
+	var err error
+	switch format {
+	case "json":
+		meals, err = s.readJson(reader)
+	case "csv":
+		meals, result.Errors, err = s.readCsv(reader)
+	default:
+		return result, NewValidationError("format must be json or csv")
+	}
+	if err != nil {
+		return result, err
+	}
+	pieceWeights, err := s.unitService.LoadPieceWeights(userId)
+	if err != nil {
+		log.Println(err)
+		return result, err
+	}
+
+	var mealsToCreate []*model.Meal
+	var foodConsumptionsToCreate []*model.FoodConsumption
+	for _, importedMeal := range meals {
+		meal := importedMeal.meal
+		meal.ID = uuid.New()
+		meal.UserId = userId
+		if err := validateImportedMeal(meal); err != nil {
+			result.Errors = append(result.Errors, dto.MealImportErrorDto{Row: importedMeal.row, Message: err.Error()})
+		}
+		for i, foodConsumption := range importedMeal.foodConsumptions {
+			foodConsumption.ID = uuid.New()
+			foodConsumption.MealID = meal.ID
+			err := validateImportedFoodConsumption(foodConsumption)
+			if err == nil {
+				err = pieceWeights.Normalize(foodConsumption)
+			}
+			if err != nil {
+				result.Errors = append(result.Errors, dto.MealImportErrorDto{Row: importedMeal.foodConsumptionRows[i], Message: err.Error()})
+			}
+		}
+		mealsToCreate = append(mealsToCreate, meal)
+		foodConsumptionsToCreate = append(foodConsumptionsToCreate, importedMeal.foodConsumptions...)
+	}
+	sort.SliceStable(result.Errors, func(i, j int) bool {
+		return result.Errors[i].Row < result.Errors[j].Row
+	})
+	result.Meals = len(mealsToCreate)
+	result.FoodConsumptions = len(foodConsumptionsToCreate)
+	if dryRun || len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	err = s.mealRepository.RunInTx(func(tx bun.Tx) error {
+		mealRepository := s.mealRepository.WithTx(tx)
+		for start := 0; start < len(mealsToCreate); start += mealImportBatchSize {
+			end := min(start+mealImportBatchSize, len(mealsToCreate))
+			if _, err := mealRepository.CreateAll(mealsToCreate[start:end]); err != nil {
+				return err
+			}
+		}
+		foodConsumptionRepository := s.foodConsumptionRepository.WithTx(tx)
+		for start := 0; start < len(foodConsumptionsToCreate); start += mealImportBatchSize {
+			end := min(start+mealImportBatchSize, len(foodConsumptionsToCreate))
+			if _, err := foodConsumptionRepository.CreateAll(foodConsumptionsToCreate[start:end]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Println(err)
+		return result, err
+	}
+	return result, nil
+}
+
+// readJson reads a JSON array of meals, each with its foodConsumptions. Rows are the positions in the array.
+func (s *MealImportService) readJson(reader io.Reader) ([]*importedMeal, error) {
+	var mealsDto []dto.MealExportDto
+	err := json.NewDecoder(reader).Decode(&mealsDto)
+	if err != nil {
+		return nil, &DomainError{Kind: Validation, Message: "invalid json", Err: err}
+	}
+	meals := make([]*importedMeal, 0, len(mealsDto))
+	for i, mealDto := range mealsDto {
+		meal := &importedMeal{
+			meal: &model.Meal{
+				Name:        mealDto.Name,
+				Description: mealDto.Description,
+				MealType:    mealDto.MealType,
+				Date:        mealDto.Date,
+				Status:      mealDto.Status,
+			},
+			row: i + 1,
+		}
+		for _, foodConsumptionDto := range mealDto.FoodConsumptions {
+			meal.foodConsumptions = append(meal.foodConsumptions, mapImportedFoodConsumption(foodConsumptionDto))
+			meal.foodConsumptionRows = append(meal.foodConsumptionRows, i+1)
+		}
+		meals = append(meals, meal)
+	}
+	return meals, nil
+}
+
+// readCsv reads a CSV file with the header of the export, in any order, and a row for every food consumption.
+// Consecutive rows with the same mealId belong to the same meal, a row without mealId is a meal of its own and a row
+// without foodName is a meal without food consumptions. The rows which can't be parsed are returned as errors.
+func (s *MealImportService) readCsv(reader io.Reader) ([]*importedMeal, []dto.MealImportErrorDto, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, nil, &DomainError{Kind: Validation, Message: "invalid csv header", Err: err}
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, name := range []string{"name", "mealType", "date"} {
+		if _, ok := columns[name]; !ok {
+			return nil, nil, NewValidationError("invalid csv header: missing column " + name)
+		}
+	}
+
+	var meals []*importedMeal
+	rowErrors := make([]dto.MealImportErrorDto, 0)
+	lastMealId := ""
+	for row := 2; ; row++ {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, &DomainError{Kind: Validation, Message: fmt.Sprintf("invalid csv at row %d", row), Err: err}
+		}
+		value := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		mealId := value("mealId")
+		if mealId == "" || mealId != lastMealId {
+			meal, err := parseCsvMeal(value)
+			if err != nil {
+				rowErrors = append(rowErrors, dto.MealImportErrorDto{Row: row, Message: err.Error()})
+				lastMealId = ""
+				continue
+			}
+			meals = append(meals, &importedMeal{meal: meal, row: row})
+			lastMealId = mealId
+		}
+		if value("foodName") == "" && value("foodId") == "" {
+			continue
+		}
+		foodConsumption, err := parseCsvFoodConsumption(value)
+		if err != nil {
+			rowErrors = append(rowErrors, dto.MealImportErrorDto{Row: row, Message: err.Error()})
+			continue
+		}
+		meal := meals[len(meals)-1]
+		meal.foodConsumptions = append(meal.foodConsumptions, foodConsumption)
+		meal.foodConsumptionRows = append(meal.foodConsumptionRows, row)
+	}
+	return meals, rowErrors, nil
+}
+
+func parseCsvMeal(value func(name string) string) (*model.Meal, error) {
+	meal := &model.Meal{
+		Name:        value("name"),
+		Description: value("description"),
+		MealType:    model.MealType(value("mealType")),
+		Status:      model.MealStatus(value("status")),
+	}
+	date, err := time.Parse(time.RFC3339, value("date"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid date: %w", err)
+	}
+	meal.Date = date
+	return meal, nil
+}
+
+// parseCsvFoodConsumption reads the food consumption of the row. Like mapImportedFoodConsumption, it ignores the grocery
+// food and transaction: an imported food consumption never took its quantity from the pantry, so it mustn't give it
+// back when it is deleted.
+func parseCsvFoodConsumption(value func(name string) string) (*model.FoodConsumption, error) {
+	foodConsumption := &model.FoodConsumption{FoodName: value("foodName"), Unit: value("unit")}
+	fields := map[string]*float32{
+		"quantityUsed":    &foodConsumption.QuantityUsed,
+		"quantityUsedStd": &foodConsumption.QuantityUsedStd,
+		"kcal":            &foodConsumption.Kcal,
+		"protein":         &foodConsumption.Protein,
+		"carbohydrate":    &foodConsumption.Carbohydrate,
+		"fat":             &foodConsumption.Fat,
+		"fiber":           &foodConsumption.Fiber,
+		"sugar":           &foodConsumption.Sugar,
+		"sodium":          &foodConsumption.Sodium,
+		"cost":            &foodConsumption.Cost,
+	}
+	for name, field := range fields {
+		param := value(name)
+		if param == "" {
+			continue
+		}
+		parsed, err := strconv.ParseFloat(param, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		*field = float32(parsed)
+	}
+	return foodConsumption, nil
+}
+
+// mapImportedFoodConsumption maps the imported food consumption without its grocery food and transaction, which are
+// left out of the pantry synchronization.
+func mapImportedFoodConsumption(foodConsumptionDto dto.FoodConsumptionDto) *model.FoodConsumption {
+	return &model.FoodConsumption{
+		FoodName:        foodConsumptionDto.FoodName,
+		QuantityUsed:    foodConsumptionDto.QuantityUsed,
+		QuantityUsedStd: foodConsumptionDto.QuantityUsedStd,
+		Unit:            foodConsumptionDto.Unit,
+		Kcal:            foodConsumptionDto.Kcal,
+		Protein:         foodConsumptionDto.Protein,
+		Carbohydrate:    foodConsumptionDto.Carbohydrate,
+		Fat:             foodConsumptionDto.Fat,
+		Fiber:           foodConsumptionDto.Fiber,
+		Sugar:           foodConsumptionDto.Sugar,
+		Sodium:          foodConsumptionDto.Sodium,
+		Cost:            foodConsumptionDto.Cost,
+	}
+}
+
+func validateImportedMeal(meal *model.Meal) error {
+	if meal.Name == "" {
+		return errors.New("name is required")
+	}
+	if !meal.MealType.IsValid() {
+		return fmt.Errorf("invalid mealType %q", meal.MealType)
+	}
+	if meal.Date.IsZero() {
+		return errors.New("date is required")
+	}
+	// Planned meals are imported even when in the past, so that an export of planned meals can be imported back later.
+	return normalizeMealStatus(meal)
+}
+
+func validateImportedFoodConsumption(foodConsumption *model.FoodConsumption) error {
+	if foodConsumption.FoodName == "" {
+		return errors.New("foodName is required")
+	}
+	values := []float32{
+		foodConsumption.QuantityUsed, foodConsumption.QuantityUsedStd, foodConsumption.Kcal, foodConsumption.Protein,
+		foodConsumption.Carbohydrate, foodConsumption.Fat, foodConsumption.Fiber, foodConsumption.Sugar,
+		foodConsumption.Sodium, foodConsumption.Cost,
+	}
+	for _, value := range values {
+		if value < 0 {
+			return fmt.Errorf("negative quantities, nutrients and cost are not allowed for %s", foodConsumption.FoodName)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"github.com/google/uuid"
+	"strings"
+	"testing"
+)
+
+// Imported food consumptions never took their quantity from the pantry: they must not reference a grocery transaction,
+// which deleting them would credit.
+func TestImportedFoodConsumptionsAreNotLinkedToGrocery(t *testing.T) {
+	foodId, transactionId := uuid.New().String(), uuid.New().String()
+	readers := map[string]func(s *MealImportService) ([]*importedMeal, error){
+		"json": func(s *MealImportService) ([]*importedMeal, error) {
+			return s.readJson(strings.NewReader(`[{"name": "lunch", "mealType": "lunch", "date": "2026-10-18T12:30:00Z", ` +
+				`"foodConsumptions": [{"foodId": "` + foodId + `", "transactionId": "` + transactionId + `", "foodName": "pasta", "quantityUsed": 80, "unit": "g"}]}]`))
+		},
+		"csv": func(s *MealImportService) ([]*importedMeal, error) {
+			meals, _, err := s.readCsv(strings.NewReader("mealId,name,mealType,date,foodId,transactionId,foodName,quantityUsed,unit\n" +
+				"1,lunch,lunch,2026-10-18T12:30:00Z," + foodId + "," + transactionId + ",pasta,80,g\n"))
+			return meals, err
+		},
+	}
+	for format, read := range readers {
+		t.Run(format, func(t *testing.T) {
+			meals, err := read(&MealImportService{})
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(meals) != 1 || len(meals[0].foodConsumptions) != 1 {
+				t.Fatalf("expected a meal with a food consumption, got %+v", meals)
+			}
+			foodConsumption := meals[0].foodConsumptions[0]
+			if foodConsumption.FoodId != uuid.Nil || foodConsumption.TransactionId != uuid.Nil {
+				t.Fatalf("expected no grocery food and transaction, got %s and %s", foodConsumption.FoodId, foodConsumption.TransactionId)
+			}
+			if foodConsumption.FoodName != "pasta" || foodConsumption.QuantityUsed != 80 {
+				t.Fatalf("expected 80 g of pasta, got %v of %s", foodConsumption.QuantityUsed, foodConsumption.FoodName)
+			}
+		})
+	}
+}
@@ -1,15 +1,37 @@
 package service
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
 	"food-track-be/model"
 	"food-track-be/model/dto"
 	"food-track-be/repository"
 	"github.com/google/uuid"
 	"github.com/mashingan/smapping"
+	"github.com/uptrace/bun"
 	"log"
 	"time"
 )
 
+const (
+	// DefaultMealPageSize is the number of meals returned by the listing when no limit is requested.
+	DefaultMealPageSize = 50
+	// MaxMealPageSize is the maximum number of meals returned by a single page of the listing.
+	MaxMealPageSize = 200
+	// mealPlanDays is the number of days covered by a meal plan.
+	mealPlanDays = 7
+	// mealExportBatchSize is the number of meals loaded at once by the export.
+	mealExportBatchSize = 200
+	// maxMealDuplicates is the maximum number of copies of a meal created by a single request.
+	maxMealDuplicates = 31
+	// maxTimeseriesPoints is the maximum number of buckets of a time series.
+	maxTimeseriesPoints = 1000
+)
+
+// mealTypes are the meal types with a series in the time series, in the order they are returned.
+var mealTypes = []model.MealType{model.Breakfast, model.Lunch, model.Dinner, model.Others}
+
 type MealService struct {
 	repository             *repository.MealRepository
 	foodConsumptionService *FoodConsumptionService
@@ -19,9 +41,10 @@ func NewMealService(repository *repository.MealRepository, service *FoodConsumpt
 	return &MealService{repository: repository, foodConsumptionService: service}
 }
 
-func (s *MealService) FindAll(userId string) ([]dto.MealDto, error) {
-	var mealsDto []dto.MealDto
-	meals, err := s.repository.FindAll(userId)
+// FindAll retrieves all the user's meals matching the query, ignoring its limit and cursor.
+func (s *MealService) FindAll(query dto.MealQueryDto, userId string) ([]dto.MealDto, error) {
+	mealsDto := make([]dto.MealDto, 0)
+	meals, err := s.repository.FindAll(userId, query)
 	if err != nil {
 		log.Println(err)
 		return nil, err
@@ -36,21 +59,78 @@ func (s *MealService) FindAll(userId string) ([]dto.MealDto, error) {
 	return mealsDto, nil
 }
 
-func (s *MealService) FindAllInDateRange(startRange time.Time, endRange time.Time, userId string) ([]dto.MealDto, error) {
-	var mealsDto []dto.MealDto
-	meals, err := s.repository.GetMealInDateRange(startRange, endRange, userId)
+// FindPage retrieves a page of the user's meals matching the query, together with the cursor of the next page.
+func (s *MealService) FindPage(query dto.MealQueryDto, userId string) (dto.PageDto[dto.MealDto], error) {
+	page := dto.PageDto[dto.MealDto]{Items: make([]dto.MealDto, 0)}
+	if query.Limit <= 0 {
+		query.Limit = DefaultMealPageSize
+	}
+	if query.Limit > MaxMealPageSize {
+		query.Limit = MaxMealPageSize
+	}
+	limit := query.Limit
+
+	var cursor *dto.MealCursorDto
+	if query.Cursor != "" {
+		decodedCursor, err := decodeMealCursor(query.Cursor)
+		if err != nil {
+			return page, err
+		}
+		cursor = &decodedCursor
+	}
+
+	// One more meal than requested is fetched to know whether there is a next page.
+	query.Limit = limit + 1
+	meals, err := s.repository.FindPage(userId, query, cursor)
 	if err != nil {
 		log.Println(err)
-		return nil, err
+		return page, err
 	}
+	if len(meals) > limit {
+		meals = meals[:limit]
+		last := meals[len(meals)-1]
+		page.NextCursor, err = encodeMealCursor(dto.MealCursorDto{Date: last.Date, ID: last.ID})
+		if err != nil {
+			return page, err
+		}
+	}
+
 	for _, meal := range meals {
-		mealDto, err := s.mapMealToDto(&meal)
+		mealDto, err := s.mapMealToDto(meal)
 		if err != nil {
-			return nil, err
+			return page, err
 		}
-		mealsDto = append(mealsDto, mealDto)
+		page.Items = append(page.Items, mealDto)
+	}
+	return page, nil
+}
+
+// Export loads in batches the user's meals in the date range, sorted by date, and passes each of them with its food
+// consumptions to write, so that the export never holds the whole history in memory. It stops at the first error.
+func (s *MealService) Export(startRange time.Time, endRange time.Time, userId string, write func(mealExportDto dto.MealExportDto) error) error {
+	var cursor *dto.MealCursorDto
+	for {
+		meals, err := s.repository.GetMealBatchInDateRange(startRange, endRange, userId, cursor, mealExportBatchSize)
+		if err != nil {
+			log.Println(err)
+			return err
+		}
+		for _, meal := range meals {
+			mealExportDto, err := s.mapMealToExportDto(meal)
+			if err != nil {
+				return err
+			}
+			err = write(mealExportDto)
+			if err != nil {
+				return err
+			}
+		}
+		if len(meals) < mealExportBatchSize {
+			return nil
+		}
+		last := meals[len(meals)-1]
+		cursor = &dto.MealCursorDto{Date: last.Date, ID: last.ID}
 	}
-	return mealsDto, nil
 }
 
 func (s *MealService) FindById(id uuid.UUID, userId string) (dto.MealDto, error) {
@@ -75,6 +155,10 @@ func (s *MealService) Create(mealDto dto.MealDto) (dto.MealDto, error) {
 		return mealDto, err
 	}
 	meal.ID = uuid.New()
+	err = validateMealStatus(&meal)
+	if err != nil {
+		return dto.MealDto{}, err
+	}
 	_, err = s.repository.Create(&meal)
 	if err != nil {
 		log.Println(err)
@@ -93,15 +177,23 @@ func (s *MealService) Update(mealDto dto.MealDto, userId string) (dto.MealDto, e
 	if err != nil {
 		return mealDto, err
 	}
+	// The plan state changes only through MarkAsEaten.
+	status, planned, plannedKcal, plannedCost := meal.Status, meal.Planned, meal.PlannedKcal, meal.PlannedCost
 	mappedField := smapping.MapFields(&mealDto)
 	err = smapping.FillStruct(&meal, mappedField)
 	if err != nil {
 		return mealDto, err
 	}
+	meal.Status, meal.Planned, meal.PlannedKcal, meal.PlannedCost = status, planned, plannedKcal, plannedCost
 	_, err = s.repository.Update(meal, userId)
 	if err != nil {
 		return dto.MealDto{}, err
 	}
+	// The meal is reloaded since its totals have been overwritten by the request body.
+	meal, err = s.repository.FindByIdAndUserId(meal.ID, userId)
+	if err != nil {
+		return dto.MealDto{}, err
+	}
 	mealDto, err = s.mapMealToDto(meal)
 	if err != nil {
 		return mealDto, err
@@ -109,12 +201,14 @@ func (s *MealService) Update(mealDto dto.MealDto, userId string) (dto.MealDto, e
 	return mealDto, nil
 }
 
-func (s *MealService) Delete(mealId uuid.UUID, userId string) error {
+// Delete moves the meal to the trash together with its food consumptions. If restoreStock is set, the quantities used
+// by the meal are given back to the pantry.
+func (s *MealService) Delete(mealId uuid.UUID, userId string, restoreStock bool, token string) error {
 	meal, err := s.repository.FindByIdAndUserId(mealId, userId)
 	if err != nil {
 		return err
 	}
-	_, err = s.repository.Delete(meal, userId)
+	err = s.foodConsumptionService.DeleteMealWithConsumptions(meal, restoreStock, token)
 	if err != nil {
 		log.Println(err)
 		return err
@@ -122,6 +216,194 @@ func (s *MealService) Delete(mealId uuid.UUID, userId string) error {
 	return nil
 }
 
+// Duplicate copies the meal, with its food consumptions, to each of the dates. The copies keep the time of the day in
+// the location and the status of the meal; the quantities used by the copies which are not planned are removed from the
+// pantry at the current price.
+func (s *MealService) Duplicate(mealId uuid.UUID, userId string, dates []time.Time, location *time.Location, token string) ([]dto.MealDto, error) {
+	if len(dates) == 0 {
+		return nil, NewValidationError("at least one date is required")
+	}
+	if len(dates) > maxMealDuplicates {
+		return nil, NewValidationError(fmt.Sprintf("at most %d dates are allowed", maxMealDuplicates))
+	}
+	meal, err := s.repository.FindByIdAndUserId(mealId, userId)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	copies := make([]*model.Meal, 0, len(dates))
+	mealDate := meal.Date.In(location)
+	for _, date := range dates {
+		y, m, d := date.Date()
+		mealCopy := &model.Meal{
+			ID:          uuid.New(),
+			UserId:      userId,
+			Name:        meal.Name,
+			Description: meal.Description,
+			MealType:    meal.MealType,
+			Date:        time.Date(y, m, d, mealDate.Hour(), mealDate.Minute(), mealDate.Second(), mealDate.Nanosecond(), location),
+			Status:      meal.Status,
+		}
+		err = validateMealStatus(mealCopy)
+		if err != nil {
+			return nil, err
+		}
+		copies = append(copies, mealCopy)
+	}
+
+	err = s.foodConsumptionService.CreateCopiesOfMeal(meal, copies, token)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	mealsDto := make([]dto.MealDto, 0, len(copies))
+	for _, mealCopy := range copies {
+		mealDto, err := s.mapMealToDto(mealCopy)
+		if err != nil {
+			return nil, err
+		}
+		mealsDto = append(mealsDto, mealDto)
+	}
+	return mealsDto, nil
+}
+
+// GeneratePlan plans the week which starts at startDate copying, as planned meals, the meals eaten by the user in the
+// previous week together with their food consumptions. The copies keep the grocery-be food but not the transaction,
+// which is likely used up by then and is resolved when the meal is eaten.
+func (s *MealService) GeneratePlan(startDate time.Time, userId string) ([]dto.MealDto, error) {
+	mealsDto := make([]dto.MealDto, 0)
+	if startDate.Before(startOfToday(startDate.Location())) {
+		return nil, NewValidationError("the plan can't start in the past")
+	}
+	endDate := startDate.AddDate(0, 0, mealPlanDays-1)
+	plannedMeals, err := s.repository.GetMealWithConsumptionsInDateRange(startDate, endDate, userId, model.Planned)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	if len(plannedMeals) > 0 {
+		return nil, NewConflictError("the week has already been planned")
+	}
+
+	eatenMeals, err := s.repository.GetMealWithConsumptionsInDateRange(startDate.AddDate(0, 0, -mealPlanDays), startDate.AddDate(0, 0, -1), userId, model.Eaten)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	if len(eatenMeals) == 0 {
+		return mealsDto, nil
+	}
+
+	meals := make([]*model.Meal, 0, len(eatenMeals))
+	var foodConsumptions []*model.FoodConsumption
+	for _, eatenMeal := range eatenMeals {
+		meal := &model.Meal{
+			ID:          uuid.New(),
+			UserId:      userId,
+			Name:        eatenMeal.Name,
+			Description: eatenMeal.Description,
+			MealType:    eatenMeal.MealType,
+			Date:        eatenMeal.Date.AddDate(0, 0, mealPlanDays),
+			Status:      model.Planned,
+			Planned:     true,
+		}
+		for _, eatenConsumption := range eatenMeal.FoodConsumptions {
+			foodConsumption := *eatenConsumption
+			foodConsumption.ID = uuid.New()
+			foodConsumption.MealID = meal.ID
+			foodConsumption.TransactionId = uuid.Nil
+			foodConsumption.SyncFailed = false
+			foodConsumptions = append(foodConsumptions, &foodConsumption)
+			meal.Kcal += foodConsumption.Kcal
+			meal.Protein += foodConsumption.Protein
+			meal.Carbohydrate += foodConsumption.Carbohydrate
+			meal.Fat += foodConsumption.Fat
+			meal.Fiber += foodConsumption.Fiber
+			meal.Sugar += foodConsumption.Sugar
+			meal.Sodium += foodConsumption.Sodium
+			meal.Cost += foodConsumption.Cost
+		}
+		meals = append(meals, meal)
+	}
+
+	err = s.repository.RunInTx(func(tx bun.Tx) error {
+		_, err := s.repository.WithTx(tx).CreateAll(meals)
+		if err != nil {
+			return err
+		}
+		return s.foodConsumptionService.CreateAllForPlannedMeals(tx, foodConsumptions)
+	})
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	for _, meal := range meals {
+		mealDto, err := s.mapMealToDto(meal)
+		if err != nil {
+			return nil, err
+		}
+		mealsDto = append(mealsDto, mealDto)
+	}
+	return mealsDto, nil
+}
+
+// MarkAsEaten turns the planned meal into an eaten one, removing its food consumptions from the pantry. The planned
+// kcal and cost of the meal are kept for the plan report.
+func (s *MealService) MarkAsEaten(mealId uuid.UUID, userId string, token string) (dto.MealDto, error) {
+	meal, err := s.repository.FindByIdAndUserId(mealId, userId)
+	if err != nil {
+		log.Println(err)
+		return dto.MealDto{}, err
+	}
+	if meal.Status != model.Planned {
+		return dto.MealDto{}, NewConflictError("only planned meals can be marked as eaten")
+	}
+	previous := *meal
+	meal.Status = model.Eaten
+	meal.PlannedKcal = meal.Kcal
+	meal.PlannedCost = meal.Cost
+	err = s.foodConsumptionService.ConsumePlannedMeal(meal, &previous, token)
+	if err != nil {
+		log.Println(err)
+		return dto.MealDto{}, err
+	}
+	// The meal is reloaded since the cost of its food consumptions has been updated to the current prices.
+	meal, err = s.repository.FindByIdAndUserId(mealId, userId)
+	if err != nil {
+		return dto.MealDto{}, err
+	}
+	return s.mapMealToDto(meal)
+}
+
+// GetPlanReport compares the kcal and cost planned for the date range with the ones actually eaten. The planned totals
+// include both the meals still planned and the planned meals which have been eaten.
+func (s *MealService) GetPlanReport(startRange time.Time, endRange time.Time, userId string) (dto.PlanReportDto, error) {
+	planReportDto := dto.PlanReportDto{StartRange: startRange, EndRange: endRange}
+	stillPlanned, err := s.repository.GetConsumptionSumInDateRange(startRange, endRange, userId, model.Planned)
+	if err != nil {
+		log.Println(err)
+		return dto.PlanReportDto{}, err
+	}
+	plannedAndEaten, err := s.repository.GetPlannedSumOfEatenMealsInDateRange(startRange, endRange, userId)
+	if err != nil {
+		log.Println(err)
+		return dto.PlanReportDto{}, err
+	}
+	actual, err := s.repository.GetConsumptionSumInDateRange(startRange, endRange, userId, model.Eaten)
+	if err != nil {
+		log.Println(err)
+		return dto.PlanReportDto{}, err
+	}
+
+	planReportDto.Planned = dto.PlanTotalsDto{Kcal: stillPlanned.Kcal + plannedAndEaten.Kcal, Cost: stillPlanned.Cost + plannedAndEaten.Cost}
+	planReportDto.Actual = dto.PlanTotalsDto{Kcal: actual.Kcal, Cost: actual.Cost}
+	planReportDto.Difference = dto.PlanTotalsDto{Kcal: actual.Kcal - planReportDto.Planned.Kcal, Cost: actual.Cost - planReportDto.Planned.Cost}
+	return planReportDto, nil
+}
+
 func (s *MealService) GetMealsStatistics(startRange time.Time, endRange time.Time, userId string) (dto.MealStatisticsDto, error) {
 	var mealStatisticsDto dto.MealStatisticsDto
 	avgKcal, err := s.repository.GetAverageKcalEatenInDateRange(startRange, endRange, userId)
@@ -157,6 +439,101 @@ func (s *MealService) GetMealsStatistics(startRange time.Time, endRange time.Tim
 	return mealStatisticsDto, nil
 }
 
+// GetTimeseries aggregates the meals eaten in the date range in buckets of the granularity, filling with zeros the
+// buckets without meals. Buckets start at midnight in the location of startRange and weeks start on monday, as ISO
+// weeks do.
+func (s *MealService) GetTimeseries(startRange time.Time, endRange time.Time, granularity dto.TimeseriesGranularity, userId string) (dto.MealTimeseriesDto, error) {
+	if !granularity.IsValid() {
+		return dto.MealTimeseriesDto{}, NewValidationError("granularity must be one of day, week, month")
+	}
+	if endRange.Before(startRange) {
+		return dto.MealTimeseriesDto{}, NewValidationError("endRange can't be before startRange")
+	}
+	var buckets []time.Time
+	endRange = endRange.In(startRange.Location())
+	for bucket := truncateToBucket(startRange, granularity); !bucket.After(endRange); bucket = nextBucket(bucket, granularity) {
+		if len(buckets) == maxTimeseriesPoints {
+			return dto.MealTimeseriesDto{}, NewValidationError(fmt.Sprintf("the time series can't have more than %d points", maxTimeseriesPoints))
+		}
+		buckets = append(buckets, bucket)
+	}
+
+	rows, err := s.repository.GetTimeseriesInDateRange(startRange, endRange, userId, granularity, startRange.Location())
+	if err != nil {
+		log.Println(err)
+		return dto.MealTimeseriesDto{}, err
+	}
+
+	timeseriesDto := dto.MealTimeseriesDto{
+		Granularity: granularity,
+		StartRange:  startRange,
+		EndRange:    endRange,
+		Total:       newEmptyTimeseries(buckets),
+		PerMealType: make([]dto.MealTypeTimeseriesDto, 0, len(mealTypes)),
+	}
+	perMealType := make(map[model.MealType][]dto.TimeseriesPointDto, len(mealTypes))
+	for _, mealType := range mealTypes {
+		perMealType[mealType] = newEmptyTimeseries(buckets)
+	}
+	bucketIndex := make(map[time.Time]int, len(buckets))
+	for i, bucket := range buckets {
+		bucketIndex[bucket] = i
+	}
+	for _, row := range rows {
+		i, ok := bucketIndex[truncateToBucket(row.Bucket, granularity)]
+		if !ok {
+			continue
+		}
+		points := []*dto.TimeseriesPointDto{&timeseriesDto.Total[i]}
+		if series, ok := perMealType[row.MealType]; ok {
+			points = append(points, &series[i])
+		}
+		for _, point := range points {
+			point.Kcal += row.Kcal
+			point.Cost += row.Cost
+			point.MealCount += row.MealCount
+		}
+	}
+	for _, mealType := range mealTypes {
+		timeseriesDto.PerMealType = append(timeseriesDto.PerMealType, dto.MealTypeTimeseriesDto{MealType: mealType, Points: perMealType[mealType]})
+	}
+	return timeseriesDto, nil
+}
+
+func newEmptyTimeseries(buckets []time.Time) []dto.TimeseriesPointDto {
+	points := make([]dto.TimeseriesPointDto, len(buckets))
+	for i, bucket := range buckets {
+		points[i].Date = bucket
+	}
+	return points
+}
+
+// truncateToBucket returns the start of the bucket of the granularity which contains t in its location, as date_trunc
+// does.
+func truncateToBucket(t time.Time, granularity dto.TimeseriesGranularity) time.Time {
+	y, m, d := t.Date()
+	switch granularity {
+	case dto.Month:
+		return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+	case dto.Week:
+		daysSinceMonday := (int(t.Weekday()) + 6) % 7
+		return time.Date(y, m, d-daysSinceMonday, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	}
+}
+
+func nextBucket(bucket time.Time, granularity dto.TimeseriesGranularity) time.Time {
+	switch granularity {
+	case dto.Month:
+		return bucket.AddDate(0, 1, 0)
+	case dto.Week:
+		return bucket.AddDate(0, 0, 7)
+	default:
+		return bucket.AddDate(0, 0, 1)
+	}
+}
+
 func (s *MealService) mapMealToDto(meal *model.Meal) (dto.MealDto, error) {
 	mealDto := dto.MealDto{}
 	err := smapping.FillStruct(&mealDto, smapping.MapFields(&meal))
@@ -164,15 +541,75 @@ func (s *MealService) mapMealToDto(meal *model.Meal) (dto.MealDto, error) {
 		log.Println(err)
 		return dto.MealDto{}, err
 	}
-	mealDto.Kcal, err = s.foodConsumptionService.GetKcalSumForMeal(meal.ID)
+	return mealDto, nil
+}
+
+func (s *MealService) mapMealToExportDto(meal *model.Meal) (dto.MealExportDto, error) {
+	mealDto, err := s.mapMealToDto(meal)
 	if err != nil {
-		log.Println(err)
-		return dto.MealDto{}, err
+		return dto.MealExportDto{}, err
 	}
-	mealDto.Cost, err = s.foodConsumptionService.GetCostSumForMeal(meal.ID)
+	mealExportDto := dto.MealExportDto{MealDto: mealDto, FoodConsumptions: make([]dto.FoodConsumptionDto, 0, len(meal.FoodConsumptions))}
+	for _, foodConsumption := range meal.FoodConsumptions {
+		foodConsumptionDto, err := s.foodConsumptionService.mapMealConsumptionToDto(foodConsumption)
+		if err != nil {
+			return dto.MealExportDto{}, err
+		}
+		mealExportDto.FoodConsumptions = append(mealExportDto.FoodConsumptions, foodConsumptionDto)
+	}
+	return mealExportDto, nil
+}
+
+// validateMealStatus defaults the meal status to eaten and makes sure planned meals are not in the past, taking as today
+// the one of the timezone of the meal date.
+func validateMealStatus(meal *model.Meal) error {
+	err := normalizeMealStatus(meal)
 	if err != nil {
-		log.Println(err)
-		return dto.MealDto{}, err
+		return err
 	}
-	return mealDto, nil
+	if meal.Planned && meal.Date.Before(startOfToday(meal.Date.Location())) {
+		return NewValidationError("planned meals can't be in the past")
+	}
+	return nil
+}
+
+// normalizeMealStatus defaults the meal status to eaten and makes sure it is a known one, whatever the meal date.
+func normalizeMealStatus(meal *model.Meal) error {
+	switch meal.Status {
+	case "":
+		meal.Status = model.Eaten
+	case model.Eaten:
+	case model.Planned:
+		meal.Planned = true
+	default:
+		return NewValidationError("invalid meal status")
+	}
+	return nil
+}
+
+// startOfToday returns the midnight which starts the current day in the location.
+func startOfToday(location *time.Location) time.Time {
+	y, m, d := time.Now().In(location).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, location)
+}
+
+func encodeMealCursor(cursor dto.MealCursorDto) (string, error) {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeMealCursor(value string) (dto.MealCursorDto, error) {
+	var cursor dto.MealCursorDto
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return cursor, NewValidationError("invalid cursor")
+	}
+	err = json.Unmarshal(data, &cursor)
+	if err != nil {
+		return cursor, NewValidationError("invalid cursor")
+	}
+	return cursor, nil
 }
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"food-track-be/model"
+	"food-track-be/model/dto"
+	"food-track-be/repository"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"testing"
+	"time"
+)
+
+func newTestMealService(t *testing.T) (*MealService, sqlmock.Sqlmock) {
+	sqlDb, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := bun.NewDB(sqlDb, pgdialect.New())
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	return NewMealService(repository.NewMealRepository(*db), nil), mock
+}
+
+func TestGetTimeseries(t *testing.T) {
+	rome, err := time.LoadLocation("Europe/Rome")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// bucket is the row of the database, whose bucket is the local date of its start without a timezone.
+	type bucket struct {
+		date     string
+		mealType model.MealType
+		kcal     float64
+	}
+	tests := []struct {
+		name        string
+		granularity dto.TimeseriesGranularity
+		startRange  string
+		endRange    string
+		rows        []bucket
+		// dates are the start of the expected buckets, kcal the expected total of each of them.
+		dates []string
+		kcal  []float64
+	}{
+		{
+			name:        "days without meals are zero",
+			granularity: dto.Day,
+			startRange:  "2026-10-01",
+			endRange:    "2026-10-04",
+			rows:        []bucket{{"2026-10-02", model.Lunch, 500}, {"2026-10-02", model.Dinner, 700}},
+			dates:       []string{"2026-10-01", "2026-10-02", "2026-10-03", "2026-10-04"},
+			kcal:        []float64{0, 1200, 0, 0},
+		},
+		{
+			name:        "weeks start on monday",
+			granularity: dto.Week,
+			startRange:  "2026-10-07",
+			endRange:    "2026-10-20",
+			rows:        []bucket{{"2026-10-05", model.Breakfast, 300}, {"2026-10-19", model.Lunch, 800}},
+			dates:       []string{"2026-10-05", "2026-10-12", "2026-10-19"},
+			kcal:        []float64{300, 0, 800},
+		},
+		{
+			name:        "months start on the first day",
+			granularity: dto.Month,
+			startRange:  "2026-09-15",
+			endRange:    "2026-11-02",
+			rows:        []bucket{{"2026-10-01", model.Others, 150}},
+			dates:       []string{"2026-09-01", "2026-10-01", "2026-11-01"},
+			kcal:        []float64{0, 150, 0},
+		},
+		{
+			// Daylight saving time ends on the 25th in Rome: the day lasts 25 hours and the next one still starts at
+			// midnight.
+			name:        "days crossing the end of daylight saving time",
+			granularity: dto.Day,
+			startRange:  "2026-10-24",
+			endRange:    "2026-10-26",
+			rows:        []bucket{{"2026-10-25", model.Lunch, 600}, {"2026-10-26", model.Dinner, 400}},
+			dates:       []string{"2026-10-24", "2026-10-25", "2026-10-26"},
+			kcal:        []float64{0, 600, 400},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mealService, mock := newTestMealService(t)
+			startRange, _ := time.ParseInLocation(time.DateOnly, test.startRange, rome)
+			endRange, _ := time.ParseInLocation(time.DateOnly, test.endRange, rome)
+			rows := sqlmock.NewRows([]string{"bucket", "meal_type", "count", "kcal", "cost"})
+			for _, row := range test.rows {
+				date, _ := time.Parse(time.DateOnly, row.date)
+				rows.AddRow(date, string(row.mealType), 1, row.kcal, 0.0)
+			}
+			mock.ExpectQuery(`SELECT date_trunc\('` + string(test.granularity) + `', m\.date AT TIME ZONE 'Europe/Rome'\)`).WillReturnRows(rows)
+
+			timeseriesDto, err := mealService.GetTimeseries(startRange, endRange, test.granularity, "user-1")
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(timeseriesDto.Total) != len(test.dates) {
+				t.Fatalf("expected %d buckets, got %d: %v", len(test.dates), len(timeseriesDto.Total), timeseriesDto.Total)
+			}
+			for i, point := range timeseriesDto.Total {
+				expected, _ := time.ParseInLocation(time.DateOnly, test.dates[i], rome)
+				if !point.Date.Equal(expected) {
+					t.Fatalf("expected bucket %d to start at %s, got %s", i, expected, point.Date)
+				}
+				if point.Kcal != test.kcal[i] {
+					t.Fatalf("expected %v kcal in the bucket starting at %s, got %v", test.kcal[i], test.dates[i], point.Kcal)
+				}
+			}
+			if len(timeseriesDto.PerMealType) != len(mealTypes) {
+				t.Fatalf("expected a series for each meal type, got %d", len(timeseriesDto.PerMealType))
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"food-track-be/model/dto"
+	"github.com/google/uuid"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// expirationDateLayouts are the formats of the expiration dates accepted from grocery-be. Dates without timezone are
+// in the timezone of the user.
+var expirationDateLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"}
+
+// PantryService exposes the foods and the transactions of the pantry in grocery-be, enriched with the data needed to
+// pick them while logging a meal.
+type PantryService struct {
+	groceryService *GroceryService
+}
+
+func NewPantryService(groceryService *GroceryService) *PantryService {
+	return &PantryService{groceryService: groceryService}
+}
+
+// FindAllFoods retrieves the foods of the pantry ordered by name. Foods already used up are left out unless all is set.
+func (s *PantryService) FindAllFoods(pantryId string, all bool, token string) ([]dto.PantryFoodDto, error) {
+	availableFoods, err := s.groceryService.GetAllAvailableFood(token, pantryId)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	pantryFoodsDto := make([]dto.PantryFoodDto, 0, len(availableFoods))
+	for _, availableFood := range availableFoods {
+		if availableFood.AvailableQuantity <= 0 && !all {
+			continue
+		}
+		pantryFoodDto := dto.PantryFoodDto{FoodAvailableDto: *availableFood}
+		if availableFood.Quantity > 0 {
+			pantryFoodDto.RemainingPercentage = availableFood.AvailableQuantity / availableFood.Quantity * 100
+		}
+		pantryFoodsDto = append(pantryFoodsDto, pantryFoodDto)
+	}
+	sort.SliceStable(pantryFoodsDto, func(i, j int) bool {
+		return strings.ToLower(pantryFoodsDto[i].Name) < strings.ToLower(pantryFoodsDto[j].Name)
+	})
+	return pantryFoodsDto, nil
+}
+
+// FindAllTransactions retrieves the available transactions of the food, the ones expiring first at the top and the
+// ones without expiration date at the bottom. The days until expiry are counted from today in location.
+func (s *PantryService) FindAllTransactions(foodId uuid.UUID, location *time.Location, token string) ([]dto.PantryTransactionDto, error) {
+	transactions, err := s.groceryService.GetAvailableTransactionForFood(foodId, token)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	today := startOfToday(location)
+	pantryTransactionsDto := make([]dto.PantryTransactionDto, 0, len(transactions))
+	for _, transaction := range transactions {
+		pantryTransactionDto := dto.PantryTransactionDto{FoodTransactionDto: *transaction}
+		if transaction.Quantity > 0 {
+			pantryTransactionDto.PricePerUnit = transaction.Price / transaction.Quantity
+			pantryTransactionDto.RemainingValue = pantryTransactionDto.PricePerUnit * transaction.AvailableQuantity
+		}
+		if expirationDate, ok := parseExpirationDate(transaction.ExpirationDate, location); ok {
+			y, m, d := expirationDate.Date()
+			// The days are counted between calendar dates, so that a change of daylight saving time doesn't matter.
+			daysUntilExpiry := int(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Sub(time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)).Hours() / 24)
+			pantryTransactionDto.DaysUntilExpiry = &daysUntilExpiry
+			pantryTransactionDto.Expired = daysUntilExpiry < 0
+		}
+		pantryTransactionsDto = append(pantryTransactionsDto, pantryTransactionDto)
+	}
+	sort.SliceStable(pantryTransactionsDto, func(i, j int) bool {
+		first, second := pantryTransactionsDto[i].DaysUntilExpiry, pantryTransactionsDto[j].DaysUntilExpiry
+		if first == nil || second == nil {
+			return first != nil && second == nil
+		}
+		return *first < *second
+	})
+	return pantryTransactionsDto, nil
+}
+
+// parseExpirationDate parses the expiration date sent by grocery-be in location, returning false if it is missing or in
+// an unknown format.
+func parseExpirationDate(value string, location *time.Location) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range expirationDateLayouts {
+		if expirationDate, err := time.ParseInLocation(layout, value, location); err == nil {
+			return expirationDate.In(location), true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"encoding/json"
+	"food-track-be/model/dto"
+	"github.com/google/uuid"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFindAllTransactions(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	y, m, d := time.Now().In(tokyo).Date()
+	// Half past midnight in Tokyo, two days from now, is still the day before in UTC.
+	inTwoDays := time.Date(y, m, d+2, 0, 30, 0, 0, tokyo).UTC().Format(time.RFC3339)
+	inThreeDays := time.Date(y, m, d+3, 0, 0, 0, 0, tokyo).Format(time.DateOnly)
+	yesterday := time.Date(y, m, d-1, 0, 0, 0, 0, tokyo).Format(time.DateOnly)
+	transactions := []*dto.FoodTransactionDto{
+		{ID: uuid.New(), ExpirationDate: ""},
+		{ID: uuid.New(), ExpirationDate: inThreeDays},
+		{ID: uuid.New(), ExpirationDate: "best before the summer"},
+		{ID: uuid.New(), ExpirationDate: inTwoDays},
+		{ID: uuid.New(), ExpirationDate: yesterday},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(dto.BaseResponse[[]*dto.FoodTransactionDto]{Body: transactions})
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("GROCERY_BASE_URL", server.URL)
+	pantryService := NewPantryService(NewGroceryService())
+
+	pantryTransactionsDto, err := pantryService.FindAllTransactions(uuid.New(), tokyo, "token")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The transactions expiring first are at the top, the ones without a valid expiration date at the bottom in the
+	// order of grocery-be.
+	expected := []struct {
+		transaction     *dto.FoodTransactionDto
+		daysUntilExpiry *int
+		expired         bool
+	}{
+		{transactions[4], intPointer(-1), true},
+		{transactions[3], intPointer(2), false},
+		{transactions[1], intPointer(3), false},
+		{transactions[0], nil, false},
+		{transactions[2], nil, false},
+	}
+	if len(pantryTransactionsDto) != len(expected) {
+		t.Fatalf("expected %d transactions, got %d", len(expected), len(pantryTransactionsDto))
+	}
+	for i, pantryTransactionDto := range pantryTransactionsDto {
+		if pantryTransactionDto.ID != expected[i].transaction.ID {
+			t.Fatalf("expected the transaction expiring %q at %d, got the one expiring %q", expected[i].transaction.ExpirationDate, i, pantryTransactionDto.ExpirationDate)
+		}
+		daysUntilExpiry := pantryTransactionDto.DaysUntilExpiry
+		if (daysUntilExpiry == nil) != (expected[i].daysUntilExpiry == nil) || daysUntilExpiry != nil && *daysUntilExpiry != *expected[i].daysUntilExpiry {
+			t.Fatalf("expected %v days until the expiry %q, got %v", expected[i].daysUntilExpiry, pantryTransactionDto.ExpirationDate, daysUntilExpiry)
+		}
+		if pantryTransactionDto.Expired != expected[i].expired {
+			t.Fatalf("expected the transaction expiring %q to be expired %v", pantryTransactionDto.ExpirationDate, expected[i].expired)
+		}
+	}
+}
+
+func intPointer(value int) *int {
+	return &value
+}
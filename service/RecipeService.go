@@ -0,0 +1,219 @@
+package service
+
+import (
+	"fmt"
+	"food-track-be/model"
+	"food-track-be/model/dto"
+	"food-track-be/repository"
+	"github.com/google/uuid"
+	"github.com/mashingan/smapping"
+	"log"
+)
+
+type RecipeService struct {
+	repository             *repository.RecipeRepository
+	mealRepository         *repository.MealRepository
+	foodConsumptionService *FoodConsumptionService
+}
+
+func NewRecipeService(repository *repository.RecipeRepository, mealRepository *repository.MealRepository, foodConsumptionService *FoodConsumptionService) *RecipeService {
+	return &RecipeService{repository: repository, mealRepository: mealRepository, foodConsumptionService: foodConsumptionService}
+}
+
+func (s *RecipeService) FindAll(userId string) ([]dto.RecipeDto, error) {
+	recipesDto := make([]dto.RecipeDto, 0)
+	recipes, err := s.repository.FindAll(userId)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	for _, recipe := range recipes {
+		recipeDto, err := s.mapRecipeToDto(recipe)
+		if err != nil {
+			return nil, err
+		}
+		recipesDto = append(recipesDto, recipeDto)
+	}
+	return recipesDto, nil
+}
+
+func (s *RecipeService) FindById(id uuid.UUID, userId string) (dto.RecipeDto, error) {
+	recipe, err := s.repository.FindByIdAndUserId(id, userId)
+	if err != nil {
+		log.Println(err)
+		return dto.RecipeDto{}, err
+	}
+	return s.mapRecipeToDto(recipe)
+}
+
+func (s *RecipeService) Create(recipeDto dto.RecipeDto) (dto.RecipeDto, error) {
+	recipe, err := s.mapDtoToRecipe(recipeDto)
+	if err != nil {
+		log.Println(err)
+		return recipeDto, err
+	}
+	recipe.ID = uuid.New()
+	for _, ingredient := range recipe.Ingredients {
+		ingredient.ID = uuid.New()
+		ingredient.RecipeID = recipe.ID
+	}
+	err = s.repository.Create(recipe)
+	if err != nil {
+		log.Println(err)
+		return dto.RecipeDto{}, err
+	}
+	return s.mapRecipeToDto(recipe)
+}
+
+func (s *RecipeService) Update(recipeDto dto.RecipeDto, userId string) (dto.RecipeDto, error) {
+	_, err := s.repository.FindByIdAndUserId(recipeDto.ID, userId)
+	if err != nil {
+		return recipeDto, err
+	}
+	recipeDto.UserId = userId
+	recipe, err := s.mapDtoToRecipe(recipeDto)
+	if err != nil {
+		return recipeDto, err
+	}
+	for _, ingredient := range recipe.Ingredients {
+		ingredient.ID = uuid.New()
+		ingredient.RecipeID = recipe.ID
+	}
+	err = s.repository.Update(recipe, userId)
+	if err != nil {
+		log.Println(err)
+		return dto.RecipeDto{}, err
+	}
+	return s.mapRecipeToDto(recipe)
+}
+
+func (s *RecipeService) Delete(recipeId uuid.UUID, userId string) error {
+	recipe, err := s.repository.FindByIdAndUserId(recipeId, userId)
+	if err != nil {
+		return err
+	}
+	err = s.repository.Delete(recipe, userId)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	return nil
+}
+
+// ApplyToMeal adds the ingredients of the recipe to the meal as food consumptions, scaled to the servings eaten.
+//
+// Ingredients which reference a grocery-be food remove the quantity used from the pantry, from the transaction resolved
+// now, unless the meal is planned. The ingredients are added as a single saga: either all of them or none.
+func (s *RecipeService) ApplyToMeal(recipeId uuid.UUID, applyRecipeDto dto.ApplyRecipeDto, userId string, token string) ([]*dto.FoodConsumptionDto, error) {
+	recipe, err := s.repository.FindByIdAndUserId(recipeId, userId)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	meal, err := s.mealRepository.FindByIdAndUserId(applyRecipeDto.MealId, userId)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	if applyRecipeDto.Servings < 0 {
+		return nil, NewValidationError("servings must be positive")
+	}
+	servings := applyRecipeDto.Servings
+	if servings == 0 {
+		servings = recipe.Servings
+	}
+	factor := servings / recipe.Servings
+
+	// Ingredients of the same food are resolved against what the previous ones left in its transactions.
+	resolver := s.foodConsumptionService.newTransactionResolver(token)
+	foodConsumptionsDto := make([]dto.FoodConsumptionDto, 0, len(recipe.Ingredients))
+	for _, ingredient := range recipe.Ingredients {
+		var transactionId uuid.UUID
+		if ingredient.FoodId != uuid.Nil && meal.Status != model.Planned {
+			transactionId, err = resolver.resolve(ingredient.FoodId, ingredient.FoodName, ingredient.Quantity*factor)
+			if err != nil {
+				return nil, err
+			}
+		}
+		foodConsumptionsDto = append(foodConsumptionsDto, dto.FoodConsumptionDto{
+			FoodId:          ingredient.FoodId,
+			TransactionId:   transactionId,
+			CatalogFoodId:   ingredient.CatalogFoodId,
+			FoodName:        ingredient.FoodName,
+			QuantityUsed:    ingredient.Quantity * factor,
+			QuantityUsedStd: ingredient.QuantityStd * factor,
+			Unit:            ingredient.Unit,
+			Kcal:            ingredient.Kcal * factor,
+			Protein:         ingredient.Protein * factor,
+			Carbohydrate:    ingredient.Carbohydrate * factor,
+			Fat:             ingredient.Fat * factor,
+			Fiber:           ingredient.Fiber * factor,
+			Sugar:           ingredient.Sugar * factor,
+			Sodium:          ingredient.Sodium * factor,
+		})
+	}
+	return s.foodConsumptionService.CreateAllFoodConsumptionsForMeal(applyRecipeDto.MealId, userId, foodConsumptionsDto, token)
+}
+
+func (s *RecipeService) mapDtoToRecipe(recipeDto dto.RecipeDto) (*model.Recipe, error) {
+	if recipeDto.Servings <= 0 {
+		return nil, NewValidationError("servings must be greater than zero")
+	}
+	recipe := model.Recipe{
+		ID:          recipeDto.ID,
+		UserId:      recipeDto.UserId,
+		Name:        recipeDto.Name,
+		Description: recipeDto.Description,
+		Servings:    recipeDto.Servings,
+		Ingredients: make([]*model.RecipeIngredient, 0, len(recipeDto.Ingredients)),
+	}
+	for _, ingredientDto := range recipeDto.Ingredients {
+		unit := model.Unit(ingredientDto.Unit)
+		if !unit.IsValid() {
+			return nil, NewValidationError(fmt.Sprintf("unit %q of ingredient %s is unknown", ingredientDto.Unit, ingredientDto.FoodName))
+		}
+		if ingredientDto.Quantity <= 0 {
+			return nil, NewValidationError("quantity of ingredient " + ingredientDto.FoodName + " must be greater than zero")
+		}
+		ingredient := model.RecipeIngredient{}
+		err := smapping.FillStruct(&ingredient, smapping.MapFields(&ingredientDto))
+		if err != nil {
+			return nil, err
+		}
+		// The standard quantity of pieces, which depends on the user's piece weights, is left as sent.
+		if quantityStd, ok := unit.ToStd(ingredient.Quantity); ok {
+			ingredient.QuantityStd = quantityStd
+		}
+		recipe.Ingredients = append(recipe.Ingredients, &ingredient)
+	}
+	return &recipe, nil
+}
+
+func (s *RecipeService) mapRecipeToDto(recipe *model.Recipe) (dto.RecipeDto, error) {
+	recipeDto := dto.RecipeDto{
+		ID:          recipe.ID,
+		UserId:      recipe.UserId,
+		Name:        recipe.Name,
+		Description: recipe.Description,
+		Servings:    recipe.Servings,
+		Ingredients: make([]dto.RecipeIngredientDto, 0, len(recipe.Ingredients)),
+	}
+	for _, ingredient := range recipe.Ingredients {
+		ingredientDto := dto.RecipeIngredientDto{}
+		err := smapping.FillStruct(&ingredientDto, smapping.MapFields(ingredient))
+		if err != nil {
+			log.Println(err)
+			return dto.RecipeDto{}, err
+		}
+		recipeDto.Ingredients = append(recipeDto.Ingredients, ingredientDto)
+
+		recipeDto.PerServing.Kcal += ingredient.Kcal / recipe.Servings
+		recipeDto.PerServing.Protein += ingredient.Protein / recipe.Servings
+		recipeDto.PerServing.Carbohydrate += ingredient.Carbohydrate / recipe.Servings
+		recipeDto.PerServing.Fat += ingredient.Fat / recipe.Servings
+		recipeDto.PerServing.Fiber += ingredient.Fiber / recipe.Servings
+		recipeDto.PerServing.Sugar += ingredient.Sugar / recipe.Servings
+		recipeDto.PerServing.Sodium += ingredient.Sodium / recipe.Servings
+	}
+	return recipeDto, nil
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"food-track-be/model/dto"
+	"food-track-be/repository"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+	"log"
+	"time"
+)
+
+// DefaultTrashRetention is the time deleted meals and food consumptions stay in the trash when no retention is configured.
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+// TrashService manages the meals and food consumptions moved to the trash, which can be restored until they are purged
+// at the end of the retention period.
+type TrashService struct {
+	mealRepository            *repository.MealRepository
+	foodConsumptionRepository *repository.FoodConsumptionRepository
+	mealService               *MealService
+	foodConsumptionService    *FoodConsumptionService
+	retention                 time.Duration
+}
+
+func NewTrashService(mealRepository *repository.MealRepository, foodConsumptionRepository *repository.FoodConsumptionRepository, mealService *MealService, foodConsumptionService *FoodConsumptionService, retention time.Duration) *TrashService {
+	return &TrashService{mealRepository: mealRepository, foodConsumptionRepository: foodConsumptionRepository, mealService: mealService, foodConsumptionService: foodConsumptionService, retention: retention}
+}
+
+// FindAll lists the meals of the user in the trash and the food consumptions in the trash of the meals which are not.
+func (s *TrashService) FindAll(userId string) (dto.TrashDto, error) {
+	trashDto := dto.TrashDto{Meals: make([]dto.TrashedMealDto, 0), FoodConsumptions: make([]dto.TrashedFoodConsumptionDto, 0)}
+	meals, err := s.mealRepository.FindDeleted(userId)
+	if err != nil {
+		log.Println(err)
+		return dto.TrashDto{}, err
+	}
+	for _, meal := range meals {
+		mealDto, err := s.mealService.mapMealToDto(meal)
+		if err != nil {
+			return dto.TrashDto{}, err
+		}
+		trashDto.Meals = append(trashDto.Meals, dto.TrashedMealDto{MealDto: mealDto, DeletedAt: meal.DeletedAt, PurgeAt: meal.DeletedAt.Add(s.retention)})
+	}
+
+	foodConsumptions, err := s.foodConsumptionRepository.FindDeletedForUser(userId)
+	if err != nil {
+		log.Println(err)
+		return dto.TrashDto{}, err
+	}
+	for _, foodConsumption := range foodConsumptions {
+		foodConsumptionDto, err := s.foodConsumptionService.mapMealConsumptionToDto(foodConsumption)
+		if err != nil {
+			return dto.TrashDto{}, err
+		}
+		trashDto.FoodConsumptions = append(trashDto.FoodConsumptions, dto.TrashedFoodConsumptionDto{FoodConsumptionDto: foodConsumptionDto, DeletedAt: foodConsumption.DeletedAt, PurgeAt: foodConsumption.DeletedAt.Add(s.retention)})
+	}
+	return trashDto, nil
+}
+
+// RestoreMeal moves the meal of the user out of the trash together with the food consumptions deleted with it, removing
+// again from the pantry the quantities given back by the deletion.
+func (s *TrashService) RestoreMeal(mealId uuid.UUID, userId string, token string) (dto.MealDto, error) {
+	meal, err := s.mealRepository.FindDeletedByIdAndUserId(mealId, userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return dto.MealDto{}, NewNotFoundError("meal not found in the trash")
+	}
+	if err != nil {
+		log.Println(err)
+		return dto.MealDto{}, err
+	}
+	err = s.foodConsumptionService.RestoreMealWithConsumptions(meal, token)
+	if err != nil {
+		log.Println(err)
+		return dto.MealDto{}, err
+	}
+	// The meal is reloaded to get the totals of its food consumptions.
+	meal, err = s.mealRepository.FindByIdAndUserId(mealId, userId)
+	if err != nil {
+		log.Println(err)
+		return dto.MealDto{}, err
+	}
+	return s.mealService.mapMealToDto(meal)
+}
+
+// RestoreFoodConsumption moves the food consumption of the user's meal out of the trash, removing again the quantity
+// used from the pantry.
+func (s *TrashService) RestoreFoodConsumption(mealId uuid.UUID, foodConsumptionId uuid.UUID, userId string, token string) (dto.FoodConsumptionDto, error) {
+	return s.foodConsumptionService.RestoreFoodConsumptionForMeal(mealId, userId, foodConsumptionId, token)
+}
+
+// Purge permanently deletes the meals and food consumptions which have been in the trash longer than the retention.
+func (s *TrashService) Purge() error {
+	deletedBefore := time.Now().Add(-s.retention)
+	return s.mealRepository.RunInTx(func(tx bun.Tx) error {
+		_, err := s.foodConsumptionRepository.WithTx(tx).PurgeDeletedBefore(deletedBefore)
+		if err != nil {
+			return err
+		}
+		_, err = s.mealRepository.WithTx(tx).PurgeDeletedBefore(deletedBefore)
+		return err
+	})
+}
+
+// Start runs the purge of the trash in background at every interval.
+func (s *TrashService) Start(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			err := s.Purge()
+			if err != nil {
+				log.Println("failed to purge the trash:", err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"fmt"
+	"food-track-be/model"
+	"food-track-be/model/dto"
+	"food-track-be/repository"
+	"log"
+	"strings"
+)
+
+// UnitService converts the quantities of the food consumptions to grams or millilitres and manages the weights of the
+// pieces of food configured by the users.
+type UnitService struct {
+	foodPieceWeightRepository *repository.FoodPieceWeightRepository
+}
+
+func NewUnitService(foodPieceWeightRepository *repository.FoodPieceWeightRepository) *UnitService {
+	return &UnitService{foodPieceWeightRepository: foodPieceWeightRepository}
+}
+
+// PieceWeights are the weights, in grams, of a piece of the foods of a user, by lowercase food name.
+type PieceWeights map[string]float32
+
+// FindAllUnits returns the known units with their conversion to the standard unit.
+func (s *UnitService) FindAllUnits() []dto.UnitDto {
+	unitsDto := make([]dto.UnitDto, 0, len(model.Units))
+	for _, unit := range model.Units {
+		unitDto := dto.UnitDto{Unit: string(unit), UnitStd: string(unit.StdUnit())}
+		if factor, ok := unit.ToStd(1); ok {
+			unitDto.Factor = &factor
+		}
+		unitsDto = append(unitsDto, unitDto)
+	}
+	return unitsDto
+}
+
+// FindAllPieceWeights retrieves the piece weights configured by the user.
+func (s *UnitService) FindAllPieceWeights(userId string) ([]dto.FoodPieceWeightDto, error) {
+	foodPieceWeights, err := s.foodPieceWeightRepository.FindAllByUserId(userId)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	foodPieceWeightsDto := make([]dto.FoodPieceWeightDto, 0, len(foodPieceWeights))
+	for _, foodPieceWeight := range foodPieceWeights {
+		foodPieceWeightsDto = append(foodPieceWeightsDto, dto.FoodPieceWeightDto{FoodName: foodPieceWeight.FoodName, Grams: foodPieceWeight.Grams})
+	}
+	return foodPieceWeightsDto, nil
+}
+
+// SavePieceWeight creates or overwrites the weight of a piece of the food. Food consumptions already saved keep their
+// standard quantity.
+func (s *UnitService) SavePieceWeight(foodPieceWeightDto dto.FoodPieceWeightDto, userId string) (dto.FoodPieceWeightDto, error) {
+	foodName := normalizeFoodName(foodPieceWeightDto.FoodName)
+	if foodName == "" {
+		return dto.FoodPieceWeightDto{}, NewValidationError("foodName is required")
+	}
+	if foodPieceWeightDto.Grams <= 0 {
+		return dto.FoodPieceWeightDto{}, NewValidationError("grams must be greater than zero")
+	}
+	foodPieceWeight := model.FoodPieceWeight{UserId: userId, FoodName: foodName, Grams: foodPieceWeightDto.Grams}
+	_, err := s.foodPieceWeightRepository.Upsert(&foodPieceWeight)
+	if err != nil {
+		log.Println(err)
+		return dto.FoodPieceWeightDto{}, err
+	}
+	return dto.FoodPieceWeightDto{FoodName: foodPieceWeight.FoodName, Grams: foodPieceWeight.Grams}, nil
+}
+
+// DeletePieceWeight deletes the weight of a piece of the food configured by the user.
+func (s *UnitService) DeletePieceWeight(foodName string, userId string) error {
+	result, err := s.foodPieceWeightRepository.Delete(userId, normalizeFoodName(foodName))
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return NewNotFoundError("piece weight not found for " + foodName)
+	}
+	return nil
+}
+
+// LoadPieceWeights retrieves the piece weights of the user, to normalize many food consumptions with a single query.
+func (s *UnitService) LoadPieceWeights(userId string) (PieceWeights, error) {
+	foodPieceWeights, err := s.foodPieceWeightRepository.FindAllByUserId(userId)
+	if err != nil {
+		return nil, err
+	}
+	pieceWeights := make(PieceWeights, len(foodPieceWeights))
+	for _, foodPieceWeight := range foodPieceWeights {
+		pieceWeights[foodPieceWeight.FoodName] = foodPieceWeight.Grams
+	}
+	return pieceWeights, nil
+}
+
+// Normalize sets the standard quantity and unit of the food consumption of the user. See PieceWeights.Normalize.
+func (s *UnitService) Normalize(foodConsumption *model.FoodConsumption, userId string) error {
+	pieceWeights := PieceWeights{}
+	if model.Unit(foodConsumption.Unit) == model.Piece {
+		var err error
+		pieceWeights, err = s.LoadPieceWeights(userId)
+		if err != nil {
+			return err
+		}
+	}
+	return pieceWeights.Normalize(foodConsumption)
+}
+
+// Normalize sets QuantityUsedStd and UnitStd of the food consumption from its quantity and unit. Pieces are converted
+// to grams with the piece weight of the food; when it isn't configured, the standard quantity sent by the client is
+// kept, and it is a Validation error if there is none.
+func (p PieceWeights) Normalize(foodConsumption *model.FoodConsumption) error {
+	unit := model.Unit(foodConsumption.Unit)
+	if !unit.IsValid() {
+		return NewValidationError(fmt.Sprintf("invalid unit %q", foodConsumption.Unit))
+	}
+	foodConsumption.UnitStd = string(unit.StdUnit())
+	if quantityStd, ok := unit.ToStd(foodConsumption.QuantityUsed); ok {
+		foodConsumption.QuantityUsedStd = quantityStd
+		return nil
+	}
+	if grams, ok := p[normalizeFoodName(foodConsumption.FoodName)]; ok {
+		foodConsumption.QuantityUsedStd = foodConsumption.QuantityUsed * grams
+		return nil
+	}
+	if foodConsumption.QuantityUsedStd > 0 {
+		return nil
+	}
+	return NewValidationError(fmt.Sprintf("the weight of a piece of %s is unknown: set it or send quantityUsedStd in grams", foodConsumption.FoodName))
+}
+
+func normalizeFoodName(foodName string) string {
+	return strings.ToLower(strings.TrimSpace(foodName))
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"errors"
+	"food-track-be/model"
+	"food-track-be/repository"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"testing"
+)
+
+func TestUnitServiceNormalize(t *testing.T) {
+	foodConsumptions := []struct {
+		name         string
+		foodName     string
+		quantityUsed float32
+		unit         model.Unit
+		// quantityUsedStd is the standard quantity sent by the client.
+		quantityUsedStd float32
+		// pieceWeights are the piece weights configured by the user, read only for pieces.
+		pieceWeights        map[string]float32
+		expectedQuantityStd float32
+		expectedUnitStd     model.Unit
+		valid               bool
+	}{
+		{"grams", "pasta", 80, model.Gram, 0, nil, 80, model.Gram, true},
+		{"kilograms", "potatoes", 1.5, model.Kilogram, 0, nil, 1500, model.Gram, true},
+		{"millilitres", "milk", 200, model.Milliliter, 0, nil, 200, model.Milliliter, true},
+		{"litres", "water", 0.5, model.Liter, 0, nil, 500, model.Milliliter, true},
+		{"pieces with a configured weight", " Egg ", 2, model.Piece, 0, map[string]float32{"egg": 60}, 120, model.Gram, true},
+		{"pieces without a configured weight", "apple", 2, model.Piece, 0, map[string]float32{"egg": 60}, 0, model.Gram, false},
+		{"pieces without a configured weight and the standard quantity", "apple", 2, model.Piece, 300, nil, 300, model.Gram, true},
+		{"unknown unit", "pasta", 80, model.Unit("spoonful"), 0, nil, 0, "", false},
+	}
+	for _, test := range foodConsumptions {
+		t.Run(test.name, func(t *testing.T) {
+			sqlDb, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+			if err != nil {
+				t.Fatal(err)
+			}
+			db := bun.NewDB(sqlDb, pgdialect.New())
+			t.Cleanup(func() {
+				_ = db.Close()
+			})
+			unitService := NewUnitService(repository.NewFoodPieceWeightRepository(*db))
+			if test.unit == model.Piece {
+				rows := sqlmock.NewRows([]string{"user_id", "food_name", "grams"})
+				for foodName, grams := range test.pieceWeights {
+					rows.AddRow("user-1", foodName, grams)
+				}
+				mock.ExpectQuery(`FROM "food_piece_weight" AS "fpw" WHERE \(user_id = 'user-1'\)`).WillReturnRows(rows)
+			}
+			foodConsumption := model.FoodConsumption{FoodName: test.foodName, QuantityUsed: test.quantityUsed, Unit: string(test.unit), QuantityUsedStd: test.quantityUsedStd}
+
+			err = unitService.Normalize(&foodConsumption, "user-1")
+
+			if !test.valid {
+				var domainError *DomainError
+				if !errors.As(err, &domainError) || domainError.Kind != Validation {
+					t.Fatalf("expected a validation error, got %v", err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			} else if foodConsumption.QuantityUsedStd != test.expectedQuantityStd || foodConsumption.UnitStd != string(test.expectedUnitStd) {
+				t.Fatalf("expected %v %s, got %v %s", test.expectedQuantityStd, test.expectedUnitStd, foodConsumption.QuantityUsedStd, foodConsumption.UnitStd)
+			}
+			// Only pieces read the piece weights: the mock fails any query which isn't expected.
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"food-track-be/model"
+	"food-track-be/model/dto"
+	"food-track-be/repository"
+	"log"
+	"time"
+)
+
+// DefaultTimezone is the timezone of the users who haven't configured one.
+const DefaultTimezone = "UTC"
+
+type UserProfileService struct {
+	repository *repository.UserProfileRepository
+}
+
+func NewUserProfileService(repository *repository.UserProfileRepository) *UserProfileService {
+	return &UserProfileService{repository: repository}
+}
+
+// FindByUserId retrieves the profile of the user, with the default values if the user hasn't configured it yet.
+func (s *UserProfileService) FindByUserId(userId string) (dto.UserProfileDto, error) {
+	userProfile, err := s.repository.FindByUserId(userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return dto.UserProfileDto{UserId: userId, Timezone: DefaultTimezone}, nil
+	}
+	if err != nil {
+		log.Println(err)
+		return dto.UserProfileDto{}, err
+	}
+	return dto.UserProfileDto{UserId: userProfile.UserId, Timezone: userProfile.Timezone}, nil
+}
+
+// Update saves the profile of the user, making sure the timezone is a known IANA timezone.
+func (s *UserProfileService) Update(userProfileDto dto.UserProfileDto, userId string) (dto.UserProfileDto, error) {
+	if _, err := LoadLocation(userProfileDto.Timezone); err != nil {
+		return dto.UserProfileDto{}, err
+	}
+	userProfile := model.UserProfile{UserId: userId, Timezone: userProfileDto.Timezone}
+	_, err := s.repository.Upsert(&userProfile)
+	if err != nil {
+		log.Println(err)
+		return dto.UserProfileDto{}, err
+	}
+	return dto.UserProfileDto{UserId: userProfile.UserId, Timezone: userProfile.Timezone}, nil
+}
+
+// GetLocation returns the timezone configured by the user, or the default one.
+func (s *UserProfileService) GetLocation(userId string) (*time.Location, error) {
+	userProfileDto, err := s.FindByUserId(userId)
+	if err != nil {
+		return nil, err
+	}
+	return LoadLocation(userProfileDto.Timezone)
+}
+
+// LoadLocation loads the IANA timezone with the given name, returning a Validation error if it is unknown. The Local
+// timezone of the server is rejected, since it isn't a timezone of the user.
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return nil, NewValidationError("invalid timezone " + name)
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, NewValidationError("invalid timezone " + name)
+	}
+	return location, nil
+}